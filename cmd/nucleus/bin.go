@@ -11,10 +11,12 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/api"
+	"github.com/LambdaTest/test-at-scale/pkg/artifactmanager"
 	"github.com/LambdaTest/test-at-scale/pkg/azure"
 	"github.com/LambdaTest/test-at-scale/pkg/blocktestservice"
 	"github.com/LambdaTest/test-at-scale/pkg/cachemanager"
@@ -25,8 +27,10 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/gitmanager"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/listsubmoduleservice"
+	"github.com/LambdaTest/test-at-scale/pkg/logwriter"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 	"github.com/LambdaTest/test-at-scale/pkg/payloadmanager"
+	"github.com/LambdaTest/test-at-scale/pkg/procutils"
 	"github.com/LambdaTest/test-at-scale/pkg/requestutils"
 	"github.com/LambdaTest/test-at-scale/pkg/secret"
 	"github.com/LambdaTest/test-at-scale/pkg/server"
@@ -36,6 +40,8 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/task"
 	"github.com/LambdaTest/test-at-scale/pkg/testdiscoveryservice"
 	"github.com/LambdaTest/test-at-scale/pkg/testexecutionservice"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
+	"github.com/LambdaTest/test-at-scale/pkg/utils"
 	"github.com/LambdaTest/test-at-scale/pkg/zstd"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/spf13/cobra"
@@ -73,6 +79,12 @@ func run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// the token is only kept in memory, so that the user commands can not read it from the environment of nucleus
+	utils.SetAuthToken(cfg.Token)
+	for _, name := range global.InternalEnvVars {
+		os.Unsetenv(name)
+	}
+
 	// patch logconfig file location with root level log file location
 	if cfg.LogFile != "" {
 		cfg.LogConfig.FileLocation = filepath.Join(cfg.LogFile, "nucleus.log")
@@ -85,6 +97,8 @@ func run(cmd *cobra.Command, args []string) {
 		log.Fatalf("Could not instantiate logger %s", err.Error())
 	}
 	logger.Debugf("Running on local: %t", cfg.LocalRunner)
+	// the processes orphaned by the killed process groups are reparented to nucleus when it is PID 1
+	go procutils.ReapOrphans(ctx, logger)
 
 	if cfg.LocalRunner {
 		logger.Infof("Local runner detected , changing IP from: %s to: %s", global.NeuronHost, cfg.SynapseHost)
@@ -94,6 +108,10 @@ func run(cmd *cobra.Command, args []string) {
 	} else {
 		global.SetNeuronHost(global.NeuronRemoteHost)
 	}
+	shutdownTracing, err := tracing.Setup(ctx, &cfg.Tracing, "nucleus", global.NucleusBinaryVersion)
+	if err != nil {
+		logger.Fatalf("failed to initialize tracing: %v", err)
+	}
 	pl, err := core.NewPipeline(cfg, logger)
 	if err != nil {
 		logger.Errorf("Unable to create the pipeline: %+v\n", err)
@@ -133,6 +151,11 @@ func run(cmd *cobra.Command, args []string) {
 	if err != nil {
 		logger.Fatalf("failed to initialize task: %v", err)
 	}
+	// a missed heartbeat is not retried, the next one reports the latest progress
+	heartbeat, err := task.New(requestutils.New(logger, global.DefaultAPITimeout, &backoff.StopBackOff{}), logger)
+	if err != nil {
+		logger.Fatalf("failed to initialize task heartbeat: %v", err)
+	}
 
 	zstd, err := zstd.New(execManager, logger)
 	if err != nil {
@@ -143,11 +166,21 @@ func run(cmd *cobra.Command, args []string) {
 		logger.Fatalf("failed to initialize cache manager: %v", err)
 	}
 
+	artifactManager := artifactmanager.New(zstd, azureClient, logger)
 	coverageService, err := coverage.New(execManager, azureClient, zstd, cfg, logger)
 	if err != nil {
 		logger.Fatalf("failed to initialize coverage service: %v", err)
 	}
 	listsubmodule := listsubmoduleservice.New(defaultRequests, logger)
+	logStreamEndpoint := ""
+	if cfg.LocalRunner {
+		// the logs are streamed live through the synapse proxy, which is the neuron host of local runners
+		logStreamEndpoint = global.NeuronHost + global.LogStreamPath
+	}
+	logWriterFactory, err := logwriter.NewFactory(&cfg.LogWriter, azureClient, logStreamEndpoint, logger)
+	if err != nil {
+		logger.Fatalf("failed to initialize log writers: %v", err)
+	}
 
 	builder := driver.Builder{
 		Logger:               logger,
@@ -158,8 +191,11 @@ func run(cmd *cobra.Command, args []string) {
 		ExecutionManager:     execManager,
 		TASConfigManager:     tcm,
 		CacheStore:           cache,
+		ArtifactManager:      artifactManager,
 		DiffManager:          dm,
 		ListSubModuleService: listsubmodule,
+		SecretParser:         secretParser,
+		LogWriterFactory:     logWriterFactory,
 	}
 
 	pl.PayloadManager = pm
@@ -173,18 +209,29 @@ func run(cmd *cobra.Command, args []string) {
 	pl.CoverageService = coverageService
 	pl.TestStats = ts
 	pl.Task = t
+	pl.Heartbeat = heartbeat
 	pl.CacheStore = cache
 	pl.SecretParser = secretParser
 	pl.Builder = &builder
 
 	logger.Infof("LambdaTest Nucleus version: %s", global.NucleusBinaryVersion)
 
+	// the pipeline is canceled on its own, so that the server keeps receiving the results flushed by the runner
+	// of an aborted task until the pipeline exits
+	pipelineCtx, cancelPipeline := context.WithCancel(ctx)
+	defer cancelPipeline()
 	wg.Add(1)
 	go func() {
 		defer cancel()
 		defer wg.Done()
 		// starting pipeline
-		pl.Start(ctx)
+		pl.Start(pipelineCtx)
+		// the spans of the task are flushed before nucleus exits
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), gracefulTimeout)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Errorf("failed to flush traces: %v", err)
+		}
 	}()
 	wg.Add(1)
 	go func() {
@@ -192,9 +239,9 @@ func run(cmd *cobra.Command, args []string) {
 		defer wg.Done()
 		server.ListenAndServe(ctx, router, cfg, logger)
 	}()
-	// listen for C-c
+	// listen for C-c and for docker stopping the container
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	// create channel to mark status of waitgroup
 	// this is required to brutally kill application in case of
@@ -211,16 +258,16 @@ func run(cmd *cobra.Command, args []string) {
 
 	// wait for signal channel
 	select {
-	case <-c:
+	case sig := <-c:
 		{
-			logger.Debugf("main: received C-c - attempting graceful shutdown ....")
-			// tell the goroutines to stop
+			logger.Debugf("main: received %s - attempting graceful shutdown ....", sig)
+			// tell the pipeline to stop, it submits the partial results and the logs within the grace period
 			logger.Debugf("main: telling goroutines to stop")
-			cancel()
+			cancelPipeline()
 			select {
 			case <-done:
 				logger.Debugf("Go routines exited within timeout")
-			case <-time.After(gracefulTimeout):
+			case <-time.After(time.Duration(cfg.GracePeriod)*time.Second + gracefulTimeout):
 				logger.Errorf("Graceful timeout exceeded. Brutally killing the application")
 			}
 
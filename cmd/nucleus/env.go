@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+)
+
+// credentialsFDEnv names the pipe the internal credentials are handed over through on re-execution
+const credentialsFDEnv = "NUCLEUS_CREDENTIALS_FD"
+
+// scrubEnv re-executes nucleus without the internal credentials in its environment, as the initial environment of
+// a process stays readable at /proc/<pid>/environ after the variables are unset. The credentials are handed over to
+// the new process through a pipe and set in its environment again, to be read into config and unset.
+func scrubEnv() error {
+	if fd := os.Getenv(credentialsFDEnv); fd != "" {
+		return restoreCredentials(fd)
+	}
+	credentials := map[string]string{}
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		name := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			name = kv[:i]
+		}
+		if isInternalEnvVar(name) {
+			credentials[name] = kv[len(name)+1:]
+			continue
+		}
+		env = append(env, kv)
+	}
+	if len(credentials) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	// the read end is inherited by the new process, the credentials fit in the pipe buffer
+	fds := make([]int, 2)
+	if err := syscall.Pipe(fds); err != nil {
+		return err
+	}
+	writer := os.NewFile(uintptr(fds[1]), "credentials")
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	writer.Close()
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	env = append(env, credentialsFDEnv+"="+strconv.Itoa(fds[0]))
+	return syscall.Exec(executable, os.Args, env)
+}
+
+func restoreCredentials(fd string) error {
+	os.Unsetenv(credentialsFDEnv)
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return err
+	}
+	reader := os.NewFile(uintptr(n), "credentials")
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	var credentials map[string]string
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return err
+	}
+	for name, value := range credentials {
+		os.Setenv(name, value)
+	}
+	return nil
+}
+
+func isInternalEnvVar(name string) bool {
+	for _, internal := range global.InternalEnvVars {
+		if name == internal {
+			return true
+		}
+	}
+	return false
+}
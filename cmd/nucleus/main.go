@@ -2,11 +2,18 @@ package main
 
 import (
 	"log"
+
+	"github.com/LambdaTest/test-at-scale/pkg/cgroup"
 )
 
 // Main function just executes root command `ts`
 // this project structure is inspired from `cobra` package
 func main() {
+	// the commands run in a cgroup are started through a re-execution of nucleus
+	cgroup.ExecShim()
+	if err := scrubEnv(); err != nil {
+		log.Fatalf("failed to remove credentials from environment: %v", err)
+	}
 	if err := RootCommand().Execute(); err != nil {
 		log.Fatal(err)
 	}
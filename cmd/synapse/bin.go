@@ -40,6 +40,7 @@ func RootCommand() *cobra.Command {
 	if err := AttachCLIFlags(&rootCmd); err != nil {
 		fmt.Println("Error in attaching cli flags")
 	}
+	rootCmd.AddCommand(secretsCommand())
 
 	return &rootCmd
 }
@@ -61,12 +62,12 @@ func run(cmd *cobra.Command, args []string) {
 
 	cfg, err := config.LoadSynapseConfig(cmd)
 	if err != nil {
-		fmt.Printf("Failed to load config: %s", err.Error())
+		log.Fatalf("Failed to load config: %s", err.Error())
 	}
 
 	err = config.LoadRepoSecrets(cmd, cfg)
 	if err != nil {
-		fmt.Printf("Error loading repository secrets: %v", err)
+		log.Fatalf("Error loading repository secrets: %v", err)
 	}
 
 	// patch logconfig file location with root level log file location
@@ -92,7 +93,7 @@ func run(cmd *cobra.Command, args []string) {
 	tasConfigDownloader := tasconfigdownloader.New(logger)
 	synapse := synapsepkg.New(runner, logger, secretsManager, tasConfigDownloader)
 
-	proxyHandler, err := proxyserver.NewProxyHandler(logger)
+	proxyHandler, err := proxyserver.NewProxyHandler(synapse, logger)
 	if err != nil {
 		logger.Fatalf("Could not instantiate proxyhandler %v", err)
 	}
@@ -116,6 +117,15 @@ func run(cmd *cobra.Command, args []string) {
 		}
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// synapse keeps running the jobs without its metrics
+		if err := proxyserver.ListenAndServeMetrics(ctx, synapse, cfg, logger); err != nil {
+			logger.Errorf("Error starting metrics server: %v", err)
+		}
+	}()
+
 	// listen for C-cInterrupt
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
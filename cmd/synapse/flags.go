@@ -8,5 +8,6 @@ import (
 func AttachCLIFlags(rootCmd *cobra.Command) error {
 	rootCmd.PersistentFlags().StringP("config", "c", "", "the config file to use")
 	rootCmd.PersistentFlags().BoolP("verbose", "", false, "should every proxy request be logged to stdout")
+	rootCmd.PersistentFlags().StringP("secrets-key-file", "", "", "the file holding the key of the encrypted secrets")
 	return nil
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/pkg/envelope"
+	"github.com/spf13/cobra"
+)
+
+const defaultConfigFile = ".synapse.json"
+
+// secretsCommand returns the command managing the encrypted secrets of the synapse configuration
+func secretsCommand() *cobra.Command {
+	secretsCmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the encrypted secrets of the synapse configuration",
+		Long: `Encrypts the secrets of the synapse configuration with the key in the file given by
+--secrets-key-file or the SYN_SECRETS_KEY_FILE env variable, or in the SYN_SECRETS_KEY env variable.
+The key is 32 random bytes encoded in base64, e.g. generated with: openssl rand -base64 32`,
+	}
+	secretsCmd.AddCommand(
+		&cobra.Command{
+			Use:   "encrypt [value]",
+			Short: "Encrypt a value, which is read from stdin if not given",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				key, value, err := loadKeyAndValue(cmd, args, 0)
+				if err != nil {
+					return err
+				}
+				encrypted, err := envelope.Encrypt(key, value)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), encrypted)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "decrypt [value]",
+			Short: "Decrypt a value, which is read from stdin if not given",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				key, value, err := loadKeyAndValue(cmd, args, 0)
+				if err != nil {
+					return err
+				}
+				decrypted, err := envelope.Decrypt(key, strings.TrimSpace(value))
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), decrypted)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "set <path> [value]",
+			Short: "Encrypt a value, which is read from stdin if not given, and set it in the configuration file",
+			Example: `  synapse secrets set Git.Token
+  synapse secrets set RepoSecrets.my-repo.NPM_TOKEN`,
+			Args: cobra.RangeArgs(1, 2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				key, value, err := loadKeyAndValue(cmd, args, 1)
+				if err != nil {
+					return err
+				}
+				encrypted, err := envelope.Encrypt(key, value)
+				if err != nil {
+					return err
+				}
+				configFile, _ := cmd.Flags().GetString("config")
+				if configFile == "" {
+					configFile = defaultConfigFile
+				}
+				return setConfigValue(configFile, args[0], encrypted)
+			},
+		},
+	)
+	return secretsCmd
+}
+
+// loadKeyAndValue returns the secrets key along with the value in args[i], or in stdin if not given
+func loadKeyAndValue(cmd *cobra.Command, args []string, i int) (key []byte, value string, err error) {
+	keyFile, _ := cmd.Flags().GetString("secrets-key-file")
+	if key, err = envelope.LoadKey(keyFile); err != nil {
+		return nil, "", err
+	}
+	if len(args) > i {
+		return key, args[i], nil
+	}
+	// reading from stdin keeps the value out of the shell history
+	body, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return nil, "", err
+	}
+	return key, strings.TrimRight(string(body), "\r\n"), nil
+}
+
+// setConfigValue sets value at the dot separated path of the JSON configuration file. The keys of the path
+// are matched exactly, then case insensitively like the configuration fields, and the missing ones are created.
+func setConfigValue(configFile, path, value string) error {
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return err
+	}
+	body, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	root := map[string]interface{}{}
+	if err := decoder.Decode(&root); err != nil {
+		return fmt.Errorf("error parsing configuration file %s: %w", configFile, err)
+	}
+	keys := strings.Split(path, ".")
+	node := root
+	for i, key := range keys {
+		key = findKey(node, key)
+		if i == len(keys)-1 {
+			node[key] = value
+			break
+		}
+		if _, ok := node[key]; !ok {
+			node[key] = map[string]interface{}{}
+		}
+		child, ok := node[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("`%s` in configuration file %s is not an object", strings.Join(keys[:i+1], "."), configFile)
+		}
+		node = child
+	}
+	updated, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, append(updated, '\n'), info.Mode())
+}
+
+func findKey(node map[string]interface{}, key string) string {
+	if _, ok := node[key]; ok {
+		return key
+	}
+	for k := range node {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/LambdaTest/test-at-scale/pkg/envelope"
+)
+
+// decrypter decrypts the encrypted values of the configuration, the key is loaded
+// only once an encrypted value is found so that plaintext configurations need no key.
+type decrypter struct {
+	keyFile string
+	key     []byte
+}
+
+// decryptSecrets decrypts in place the encrypted values in the string fields, maps and slices of v
+func decryptSecrets(v interface{}, keyFile string) error {
+	d := &decrypter{keyFile: keyFile}
+	return d.walk(reflect.ValueOf(v), "")
+}
+
+func (d *decrypter) walk(val reflect.Value, path string) error {
+	// nolint:exhaustive
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+		return d.walk(val.Elem(), path)
+	case reflect.Struct:
+		vType := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			if !val.Field(i).CanSet() {
+				continue
+			}
+			if err := d.walk(val.Field(i), joinPath(path, vType.Field(i).Name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			if err := d.walk(val.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range val.MapKeys() {
+			// map elements are not addressable, so they are decrypted in a copy
+			elem := reflect.New(val.Type().Elem()).Elem()
+			elem.Set(val.MapIndex(key))
+			if err := d.walk(elem, joinPath(path, fmt.Sprint(key.Interface()))); err != nil {
+				return err
+			}
+			val.SetMapIndex(key, elem)
+		}
+	case reflect.String:
+		if !envelope.IsEncrypted(val.String()) {
+			return nil
+		}
+		if d.key == nil {
+			key, err := envelope.LoadKey(d.keyFile)
+			if err != nil {
+				return err
+			}
+			d.key = key
+		}
+		plaintext, err := envelope.Decrypt(d.key, val.String())
+		if err != nil {
+			return fmt.Errorf("error decrypting %s: %w", path, err)
+		}
+		val.SetString(plaintext)
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/envelope"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptSecrets(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypt := func(value string) string {
+		encrypted, err := envelope.Encrypt(key, value)
+		assert.Nil(t, err)
+		return encrypted
+	}
+	cfg := &SynapseConfig{
+		Name:        "synapse",
+		Lambdatest:  LambdatestConfig{SecretKey: encrypt("secret-key")},
+		Git:         GitConfig{Token: encrypt("git-token"), TokenType: "Bearer"},
+		RepoSecrets: map[string]map[string]string{"repo": {"NPM_TOKEN": encrypt("npm"), "PLAIN": "plain"}},
+		SecretProviders: []SecretProviderConfig{
+			{Type: VaultSecretProvider, Vault: VaultConfig{Token: encrypt("vault-token")}},
+		},
+	}
+
+	t.Setenv(global.SecretsKeyEnv, base64.StdEncoding.EncodeToString(key))
+	assert.Nil(t, decryptSecrets(cfg, ""))
+	assert.Equal(t, "synapse", cfg.Name)
+	assert.Equal(t, "secret-key", cfg.Lambdatest.SecretKey)
+	assert.Equal(t, "git-token", cfg.Git.Token)
+	assert.Equal(t, map[string]string{"NPM_TOKEN": "npm", "PLAIN": "plain"}, cfg.RepoSecrets["repo"])
+	assert.Equal(t, "vault-token", cfg.SecretProviders[0].Vault.Token)
+}
+
+func TestDecryptSecretsErrors(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypted, err := envelope.Encrypt(key, "git-token")
+	assert.Nil(t, err)
+
+	t.Setenv(global.SecretsKeyEnv, "")
+	assert.Nil(t, decryptSecrets(&SynapseConfig{Git: GitConfig{Token: "plain"}}, ""), "plaintext config needs no key")
+	assert.ErrorIs(t, decryptSecrets(&SynapseConfig{Git: GitConfig{Token: encrypted}}, ""), envelope.ErrKeyNotFound)
+
+	t.Setenv(global.SecretsKeyEnv, base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210")))
+	err = decryptSecrets(&SynapseConfig{Git: GitConfig{Token: encrypted}}, "")
+	assert.ErrorIs(t, err, envelope.ErrDecrypt)
+	assert.Contains(t, err.Error(), "Git.Token")
+}
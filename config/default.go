@@ -15,7 +15,16 @@ func setNucleusDefaultConfig() {
 	viper.SetDefault("LogConfig.FileLocation", global.HomeDir+"/nucleus.log")
 	viper.SetDefault("Env", "prod")
 	viper.SetDefault("Port", "9876")
+	viper.SetDefault("LogWriter.Dir", global.HomeDir+"/logs")
+	viper.SetDefault("LogWriter.MaxSize", 100)
+	viper.SetDefault("LogWriter.MaxBackups", 5)
 	viper.SetDefault("Verbose", false)
+	viper.SetDefault("HeartbeatInterval", 30)
+	viper.SetDefault("MaxMissedHeartbeats", 4)
+	viper.SetDefault("GracePeriod", 5)
+	viper.SetDefault("Cgroup.Enabled", false)
+	viper.SetDefault("Cgroup.MemoryReserve", 256)
+	viper.SetDefault("Cgroup.CPUReserve", 100)
 }
 
 func setSynapseDefaultConfig() {
@@ -27,5 +36,8 @@ func setSynapseDefaultConfig() {
 	viper.SetDefault("LogConfig.FileLevel", "debug")
 	viper.SetDefault("LogConfig.FileLocation", "./mould.log")
 	viper.SetDefault("Env", "prod")
+	viper.SetDefault("MetricsPort", global.MetricsServerPort)
 	viper.SetDefault("Verbose", false)
+	viper.SetDefault("ServiceResources.CPU", 1)
+	viper.SetDefault("ServiceResources.RAM", 1024)
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,7 +20,14 @@ var GlobalNucleusConfig *NucleusConfig
 var GlobalSynapseConfig *SynapseConfig
 
 type tempSecretReader struct {
-	RepoSecrets map[string]map[string]string `json:"RepoSecrets" yaml:"RepoSecrets"`
+	RepoSecrets     map[string]map[string]string `json:"RepoSecrets" yaml:"RepoSecrets"`
+	SecretPolicy    SecretPolicy                 `json:"SecretPolicy" yaml:"SecretPolicy"`
+	SecretProviders []SecretProviderConfig       `json:"SecretProviders" yaml:"SecretProviders"`
+	GitCredentials  []GitConfig                  `json:"GitCredentials" yaml:"GitCredentials"`
+	// maps are not populated from viper, so the registries are read from the file as well
+	ContainerRegistry struct {
+		Registries map[string]RegistryCredential `json:"Registries" yaml:"Registries"`
+	} `json:"ContainerRegistry" yaml:"ContainerRegistry"`
 }
 
 // LoadNucleusConfig loads config from command instance to predefined config variables
@@ -48,7 +56,24 @@ func LoadNucleusConfig(cmd *cobra.Command) (*NucleusConfig, error) {
 		fmt.Println("Warning: No configuration file found. Proceeding with defaults")
 	}
 
-	return populateNucleusConfig(new(NucleusConfig))
+	cfg, err := populateNucleusConfig(new(NucleusConfig))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateNucleusCfg(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validateNucleusCfg checks the validity of the nucleus config
+func validateNucleusCfg(cfg *NucleusConfig) error {
+	// zero would abort the task on the first heartbeat missed
+	if cfg.HeartbeatInterval > 0 && cfg.MaxMissedHeartbeats < 1 {
+		return fmt.Errorf("invalid MaxMissedHeartbeats %d, at least 1 heartbeat must be missed to abort the task",
+			cfg.MaxMissedHeartbeats)
+	}
+	return nil
 }
 
 // LoadSynapseConfig loads config from command instance to predefined config variables
@@ -77,7 +102,15 @@ func LoadSynapseConfig(cmd *cobra.Command) (*SynapseConfig, error) {
 	if err := viper.ReadInConfig(); err != nil {
 		fmt.Println("Warning: No configuration file found. Proceeding with defaults")
 	}
-	return populateSynapseConfig(new(SynapseConfig))
+	cfg, err := populateSynapseConfig(new(SynapseConfig))
+	if err != nil {
+		return nil, err
+	}
+	keyFile, _ := cmd.Flags().GetString("secrets-key-file")
+	if err := decryptSecrets(cfg, keyFile); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
 // LoadRepoSecrets loads repo secrets from configuration file
@@ -103,8 +136,17 @@ func LoadRepoSecrets(cmd *cobra.Command, synapseConfig *SynapseConfig) error {
 	if err := json.Unmarshal(secretFile, &tempSecret); err != nil {
 		fmt.Printf("error in umarshaling secrets: %v\n", err)
 	}
+	keyFile, _ := cmd.Flags().GetString("secrets-key-file")
+	if err := decryptSecrets(&tempSecret, keyFile); err != nil {
+		return err
+	}
 
 	synapseConfig.RepoSecrets = tempSecret.RepoSecrets
+	// secret names in the policy are case sensitive as well
+	synapseConfig.SecretPolicy = tempSecret.SecretPolicy
+	synapseConfig.SecretProviders = tempSecret.SecretProviders
+	synapseConfig.GitCredentials = tempSecret.GitCredentials
+	synapseConfig.ContainerRegistry.Registries = tempSecret.ContainerRegistry.Registries
 	return nil
 }
 
@@ -116,9 +158,48 @@ func ValidateCfg(cfg *SynapseConfig, logger lumber.Logger) error {
 	if cfg.ContainerRegistry.Mode == "" {
 		return errors.New("error finding ContainerRegistry Mode in configuration file")
 	}
+	if err := validateSecretProviders(cfg.SecretProviders); err != nil {
+		return err
+	}
+	for i := range cfg.GitCredentials {
+		if cfg.GitCredentials[i].Token == "" {
+			return fmt.Errorf("error finding Token of GitCredentials[%d] in configuration file", i)
+		}
+		if !doublestar.ValidatePattern(cfg.GitCredentials[i].Repo) {
+			return fmt.Errorf("invalid Repo pattern `%s` of GitCredentials[%d] in configuration file", cfg.GitCredentials[i].Repo, i)
+		}
+	}
+	for host, credential := range cfg.ContainerRegistry.Registries {
+		if credential.Username == "" || credential.Password == "" {
+			return fmt.Errorf("error finding Username or Password of ContainerRegistry Registries %s in configuration file", host)
+		}
+	}
 	if cfg.RepoSecrets == nil {
 		logger.Debugf("no RepoSecrets found in configuration file.")
 		return nil
 	}
 	return nil
 }
+
+func validateSecretProviders(providers []SecretProviderConfig) error {
+	for i := range providers {
+		provider := &providers[i]
+		switch provider.Type {
+		case ConfigSecretProvider, EnvSecretProvider:
+		case FilesSecretProvider:
+			if provider.Dir == "" {
+				return errors.New("error finding Dir of files secret provider in configuration file")
+			}
+		case VaultSecretProvider:
+			if provider.Vault.Address == "" {
+				return errors.New("error finding Vault Address of vault secret provider in configuration file")
+			}
+			if provider.Vault.KVVersion != 0 && provider.Vault.KVVersion != 1 && provider.Vault.KVVersion != 2 {
+				return fmt.Errorf("invalid Vault KVVersion %d of vault secret provider in configuration file", provider.Vault.KVVersion)
+			}
+		default:
+			return fmt.Errorf("invalid secret provider type `%s` in configuration file", provider.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateNucleusCfg(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     NucleusConfig
+		wantErr bool
+	}{
+		{"defaults", NucleusConfig{HeartbeatInterval: 30, MaxMissedHeartbeats: 4}, false},
+		{"no missed heartbeats allowed", NucleusConfig{HeartbeatInterval: 30, MaxMissedHeartbeats: 0}, true},
+		{"negative missed heartbeats", NucleusConfig{HeartbeatInterval: 30, MaxMissedHeartbeats: -1}, true},
+		{"heartbeat disabled", NucleusConfig{HeartbeatInterval: 0, MaxMissedHeartbeats: 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateNucleusCfg(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateNucleusCfg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
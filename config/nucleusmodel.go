@@ -19,6 +19,7 @@ type NucleusConfig struct {
 	FlakyMode       bool   `json:"flaky"`
 	TaskID          string `json:"taskID" env:"TASK_ID"`
 	BuildID         string `json:"buildID" env:"BUILD_ID"`
+	Token           string `env:"TOKEN"`
 	Locators        string `json:"locators"`
 	LocatorAddress  string `json:"locatorAddress"`
 	Env             string
@@ -27,6 +28,54 @@ type NucleusConfig struct {
 	LocalRunner     bool   `env:"local"`
 	SynapseHost     string `env:"synapsehost"`
 	SubModule       string `json:"subModule"`
+	LogWriter       LogWriterConfig
+	Tracing         TracingConfig
+	// HeartbeatInterval is the interval in seconds between the heartbeats of the task, zero disables them
+	HeartbeatInterval   int `json:"heartbeatInterval"`
+	MaxMissedHeartbeats int `json:"maxMissedHeartbeats"`
+	// GracePeriod is the time in seconds an aborted task is given to submit its partial results and upload its logs
+	GracePeriod int `json:"gracePeriod"`
+	Cgroup      CgroupConfig
+}
+
+// Log writers of the logs of the user commands and the test runs
+const (
+	AzureLogWriter  = "azure"
+	FileLogWriter   = "file"
+	StreamLogWriter = "stream"
+)
+
+// LogWriterConfig selects the writers of the logs of the user commands and the test runs.
+type LogWriterConfig struct {
+	// Writers is a comma separated list of the log writers. If empty the logs are uploaded to azure,
+	// and streamed live to synapse in local runner mode.
+	Writers string
+	// Dir is the directory of the file log writer, where each stage is logged to its own file
+	Dir string
+	// MaxSize is the size in megabytes after which a log file is rotated
+	MaxSize int
+	// MaxBackups is the number of rotated log files kept, which are compressed with gzip
+	MaxBackups int
+}
+
+// CgroupConfig configures the child cgroup (v2) the test runner and the user commands are run in. Its limits are
+// the specs of the tier of the task minus the reserve kept for nucleus. It is opt-in, as the container runtime
+// must delegate a writable cgroup v2 subtree to the container, which docker does not do for unprivileged containers.
+type CgroupConfig struct {
+	// Enabled runs the commands in the child cgroup when a writable cgroup v2 subtree is delegated
+	Enabled bool
+	// MemoryReserve is the memory in MiB reserved for nucleus
+	MemoryReserve int
+	// CPUReserve is the CPU in millicores reserved for nucleus
+	CPUReserve int
+}
+
+// TracingConfig configures the export of the traces of the tasks over OTLP.
+type TracingConfig struct {
+	// Endpoint is the host:port of the OTLP/HTTP collector, tracing is disabled if empty
+	Endpoint string
+	// Insecure sends the traces over plain http
+	Insecure bool
 }
 
 // Azure providers the storage configuration.
@@ -84,7 +84,7 @@ func recursivelySet(val reflect.Value, prefix string) error {
 					continue
 				}
 				thisField.SetBool(viper.GetBool(key))
-			case reflect.Map:
+			case reflect.Map, reflect.Slice:
 				continue
 			default:
 				return fmt.Errorf("unexpected type detected ~ aborting: %s", thisField.Kind())
@@ -12,10 +12,41 @@ type SynapseConfig struct {
 	LogConfig         lumber.LoggingConfig
 	Env               string
 	Verbose           bool
+	MetricsPort       string
 	Lambdatest        LambdatestConfig
 	Git               GitConfig
+	GitCredentials    []GitConfig
 	ContainerRegistry ContainerRegistryConfig
 	RepoSecrets       map[string]map[string]string
+	SecretPolicy      SecretPolicy
+	SecretProviders   []SecretProviderConfig
+	ServiceResources  ServiceResourcesConfig
+}
+
+// ServiceResourcesConfig limits the resources of the service containers which do not set their own,
+// so that a service cannot starve the nucleus container on the same host
+type ServiceResourcesConfig struct {
+	// CPU is the number of cores
+	CPU float32
+	// RAM is the memory in MiB
+	RAM int64
+}
+
+// SecretPolicy restricts the builds to which the repo secrets are made available.
+// The zero value withholds the secrets from pull requests of forks.
+type SecretPolicy struct {
+	// AllowForkPullRequests makes the secrets available to pull requests from forks
+	AllowForkPullRequests bool `json:"AllowForkPullRequests" yaml:"allowForkPullRequests"`
+	// Branches limits the secrets to the builds of branches matching any of the globs
+	Branches []string `json:"Branches,omitempty" yaml:"branches" validate:"omitempty,dive,required"`
+	// Secrets further limits the individual secrets, keyed by secret name
+	Secrets map[string]SecretScope `json:"Secrets,omitempty" yaml:"secrets" validate:"omitempty,dive"`
+}
+
+// SecretScope limits a secret to the builds of the given events and branches
+type SecretScope struct {
+	EventTypes []string `json:"EventTypes,omitempty" yaml:"eventType" validate:"omitempty,dive,oneof=push pull-request"`
+	Branches   []string `json:"Branches,omitempty" yaml:"branches" validate:"omitempty,dive,required"`
 }
 
 // LambdatestConfig contains credentials for lambdatest
@@ -23,10 +54,42 @@ type LambdatestConfig struct {
 	SecretKey string
 }
 
-// GitConfig contains git token
+// GitConfig contains git token, along with the repos for which it is used
 type GitConfig struct {
 	Token     string
 	TokenType string
+	// Provider, e.g. github or gitlab, Host and Repo select the repos for which the token is used,
+	// an empty one matches all repos
+	Provider string
+	Host     string
+	// Repo is a glob matched against the repo slug, e.g. myorg/*
+	Repo string
+}
+
+// SecretProviderType defines the source of repo and git secrets
+type SecretProviderType string
+
+// SecretProviderConfig configures a source of repo and git secrets
+type SecretProviderConfig struct {
+	Type SecretProviderType
+	// Prefix of the env variables holding the secrets, used by the env provider
+	Prefix string
+	// Dir holding the secret files, used by the files provider
+	Dir   string
+	Vault VaultConfig
+}
+
+// VaultConfig contains the configuration of the HashiCorp Vault KV secrets engine
+type VaultConfig struct {
+	Address string
+	// Token is read from TokenFile if not set, and then from the VAULT_TOKEN env variable
+	Token     string
+	TokenFile string
+	Namespace string
+	MountPath string
+	// Path under which the git secret and the repo secrets are stored
+	Path      string
+	KVVersion int
 }
 
 // PullPolicyType defines when to pull docker image
@@ -41,8 +104,28 @@ type ContainerRegistryConfig struct {
 	Mode       ModeType
 	Username   string
 	Password   string
+	// Registry is the host of the registry Username and Password are used for, docker.io if not set
+	Registry string
+	// Registries holds the credentials of the registries keyed by their host, e.g. ghcr.io
+	Registries map[string]RegistryCredential
+	// DockerConfigFile is a docker config.json whose credentials are used for the registries not in Registries
+	DockerConfigFile string
+}
+
+// RegistryCredential contains the credentials of a container registry
+type RegistryCredential struct {
+	Username string
+	Password string
 }
 
+// defines the secret providers
+const (
+	ConfigSecretProvider SecretProviderType = "config"
+	EnvSecretProvider    SecretProviderType = "env"
+	FilesSecretProvider  SecretProviderType = "files"
+	VaultSecretProvider  SecretProviderType = "vault"
+)
+
 // defines constant for docker config
 const (
 	PullAlways  PullPolicyType = "always"
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ArtifactManager is an autogenerated mock type for the ArtifactManager type
+type ArtifactManager struct {
+	mock.Mock
+}
+
+// Upload provides a mock function with given fields: ctx, name, workingDir, patterns
+func (_m *ArtifactManager) Upload(ctx context.Context, name string, workingDir string, patterns ...string) (string, error) {
+	_va := make([]interface{}, len(patterns))
+	for _i := range patterns {
+		_va[_i] = patterns[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, name, workingDir)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ...string) string); ok {
+		r0 = rf(ctx, name, workingDir, patterns...)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, ...string) error); ok {
+		r1 = rf(ctx, name, workingDir, patterns...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewArtifactManager interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewArtifactManager creates a new instance of ArtifactManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewArtifactManager(t mockConstructorTestingTNewArtifactManager) *ArtifactManager {
+	mock := &ArtifactManager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -13,13 +13,20 @@ type CacheStore struct {
 	mock.Mock
 }
 
-// CacheWorkspace provides a mock function with given fields: ctx, subModule
-func (_m *CacheStore) CacheWorkspace(ctx context.Context, subModule string) error {
-	ret := _m.Called(ctx, subModule)
+// CacheWorkspace provides a mock function with given fields: ctx, subModule, excludePaths
+func (_m *CacheStore) CacheWorkspace(ctx context.Context, subModule string, excludePaths ...string) error {
+	_va := make([]interface{}, len(excludePaths))
+	for _i := range excludePaths {
+		_va[_i] = excludePaths[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, subModule)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, subModule)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) error); ok {
+		r0 = rf(ctx, subModule, excludePaths...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -15,31 +15,49 @@ type ExecutionManager struct {
 }
 
 // ExecuteInternalCommands provides a mock function with given fields: ctx, commandType, commands, cwd, envMap, secretData
-func (_m *ExecutionManager) ExecuteInternalCommands(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap map[string]string, secretData map[string]string) error {
+func (_m *ExecutionManager) ExecuteInternalCommands(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap map[string]string, secretData map[string]string) ([]*core.StepResult, error) {
 	ret := _m.Called(ctx, commandType, commands, cwd, envMap, secretData)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, core.CommandType, []string, string, map[string]string, map[string]string) error); ok {
+	var r0 []*core.StepResult
+	if rf, ok := ret.Get(0).(func(context.Context, core.CommandType, []string, string, map[string]string, map[string]string) []*core.StepResult); ok {
 		r0 = rf(ctx, commandType, commands, cwd, envMap, secretData)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.StepResult)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, core.CommandType, []string, string, map[string]string, map[string]string) error); ok {
+		r1 = rf(ctx, commandType, commands, cwd, envMap, secretData)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // ExecuteUserCommands provides a mock function with given fields: ctx, commandType, payload, runConfig, secretData, logwriter, cwd
-func (_m *ExecutionManager) ExecuteUserCommands(ctx context.Context, commandType core.CommandType, payload *core.Payload, runConfig *core.Run, secretData map[string]string, logwriter core.LogWriterStrategy, cwd string) error {
+func (_m *ExecutionManager) ExecuteUserCommands(ctx context.Context, commandType core.CommandType, payload *core.Payload, runConfig *core.Run, secretData map[string]string, logwriter core.LogWriterStrategy, cwd string) ([]*core.StepResult, error) {
 	ret := _m.Called(ctx, commandType, payload, runConfig, secretData, logwriter, cwd)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, core.CommandType, *core.Payload, *core.Run, map[string]string, core.LogWriterStrategy, string) error); ok {
+	var r0 []*core.StepResult
+	if rf, ok := ret.Get(0).(func(context.Context, core.CommandType, *core.Payload, *core.Run, map[string]string, core.LogWriterStrategy, string) []*core.StepResult); ok {
 		r0 = rf(ctx, commandType, payload, runConfig, secretData, logwriter, cwd)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.StepResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, core.CommandType, *core.Payload, *core.Run, map[string]string, core.LogWriterStrategy, string) error); ok {
+		r1 = rf(ctx, commandType, payload, runConfig, secretData, logwriter, cwd)
+	} else {
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
 }
 
 // GetEnvVariables provides a mock function with given fields: envMap, secretData
@@ -65,6 +83,11 @@ func (_m *ExecutionManager) GetEnvVariables(envMap map[string]string, secretData
 	return r0, r1
 }
 
+// SetInheritEnv provides a mock function with given fields: names
+func (_m *ExecutionManager) SetInheritEnv(names []string) {
+	_m.Called(names)
+}
+
 type mockConstructorTestingTNewExecutionManager interface {
 	mock.TestingT
 	Cleanup(func())
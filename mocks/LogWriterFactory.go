@@ -0,0 +1,76 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	core "github.com/LambdaTest/test-at-scale/pkg/core"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogWriterFactory is an autogenerated mock type for the LogWriterFactory type
+type LogWriterFactory struct {
+	mock.Mock
+}
+
+// NewLogWriter provides a mock function with given fields: purpose, payload
+func (_m *LogWriterFactory) NewLogWriter(purpose core.SASURLPurpose, payload *core.Payload) core.LogWriterStrategy {
+	ret := _m.Called(purpose, payload)
+
+	var r0 core.LogWriterStrategy
+	if rf, ok := ret.Get(0).(func(core.SASURLPurpose, *core.Payload) core.LogWriterStrategy); ok {
+		r0 = rf(purpose, payload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.LogWriterStrategy)
+		}
+	}
+
+	return r0
+}
+
+// NewStoreLogWriter provides a mock function with given fields: purpose, payload
+func (_m *LogWriterFactory) NewStoreLogWriter(purpose core.SASURLPurpose, payload *core.Payload) core.LogWriterStrategy {
+	ret := _m.Called(purpose, payload)
+
+	var r0 core.LogWriterStrategy
+	if rf, ok := ret.Get(0).(func(core.SASURLPurpose, *core.Payload) core.LogWriterStrategy); ok {
+		r0 = rf(purpose, payload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.LogWriterStrategy)
+		}
+	}
+
+	return r0
+}
+
+// WithLogStream provides a mock function with given fields: writer, purpose, payload
+func (_m *LogWriterFactory) WithLogStream(writer core.LogWriterStrategy, purpose core.SASURLPurpose, payload *core.Payload) core.LogWriterStrategy {
+	ret := _m.Called(writer, purpose, payload)
+
+	var r0 core.LogWriterStrategy
+	if rf, ok := ret.Get(0).(func(core.LogWriterStrategy, core.SASURLPurpose, *core.Payload) core.LogWriterStrategy); ok {
+		r0 = rf(writer, purpose, payload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.LogWriterStrategy)
+		}
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewLogWriterFactory interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewLogWriterFactory creates a new instance of LogWriterFactory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewLogWriterFactory(t mockConstructorTestingTNewLogWriterFactory) *LogWriterFactory {
+	mock := &LogWriterFactory{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	config "github.com/LambdaTest/test-at-scale/config"
 	core "github.com/LambdaTest/test-at-scale/pkg/core"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +13,29 @@ type SecretParser struct {
 	mock.Mock
 }
 
+// ApplySecretPolicy provides a mock function with given fields: secretData, payload, policies
+func (_m *SecretParser) ApplySecretPolicy(secretData map[string]string, payload *core.Payload, policies ...*config.SecretPolicy) map[string]string {
+	_va := make([]interface{}, len(policies))
+	for _i := range policies {
+		_va[_i] = policies[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, secretData, payload)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(map[string]string, *core.Payload, ...*config.SecretPolicy) map[string]string); ok {
+		r0 = rf(secretData, payload, policies...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	return r0
+}
+
 // Expired provides a mock function with given fields: token
 func (_m *SecretParser) Expired(token *core.Oauth) bool {
 	ret := _m.Called(token)
@@ -72,6 +96,29 @@ func (_m *SecretParser) GetRepoSecret(_a0 string) (map[string]string, error) {
 	return r0, r1
 }
 
+// GetSecretPolicy provides a mock function with given fields: path
+func (_m *SecretParser) GetSecretPolicy(path string) (*config.SecretPolicy, error) {
+	ret := _m.Called(path)
+
+	var r0 *config.SecretPolicy
+	if rf, ok := ret.Get(0).(func(string) *config.SecretPolicy); ok {
+		r0 = rf(path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.SecretPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SubstituteSecret provides a mock function with given fields: command, secretData
 func (_m *SecretParser) SubstituteSecret(command string, secretData map[string]string) (string, error) {
 	ret := _m.Called(command, secretData)
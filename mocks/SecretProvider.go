@@ -0,0 +1,91 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	config "github.com/LambdaTest/test-at-scale/config"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SecretProvider is an autogenerated mock type for the SecretProvider type
+type SecretProvider struct {
+	mock.Mock
+}
+
+// GetGitSecrets provides a mock function with given fields: ctx
+func (_m *SecretProvider) GetGitSecrets(ctx context.Context) ([]config.GitConfig, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []config.GitConfig
+	if rf, ok := ret.Get(0).(func(context.Context) []config.GitConfig); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]config.GitConfig)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRepoSecrets provides a mock function with given fields: ctx, repo
+func (_m *SecretProvider) GetRepoSecrets(ctx context.Context, repo string) (map[string]string, error) {
+	ret := _m.Called(ctx, repo)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(context.Context, string) map[string]string); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Name provides a mock function with given fields:
+func (_m *SecretProvider) Name() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewSecretProvider interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSecretProvider creates a new instance of SecretProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSecretProvider(t mockConstructorTestingTNewSecretProvider) *SecretProvider {
+	mock := &SecretProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
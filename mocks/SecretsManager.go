@@ -5,7 +5,6 @@ package mocks
 import (
 	config "github.com/LambdaTest/test-at-scale/config"
 	core "github.com/LambdaTest/test-at-scale/pkg/core"
-
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -35,6 +34,29 @@ func (_m *SecretsManager) GetDockerSecrets(r *core.RunnerOptions) (core.Containe
 	return r0, r1
 }
 
+// GetGitSecretBytes provides a mock function with given fields: repo
+func (_m *SecretsManager) GetGitSecretBytes(repo *core.GitRepo) ([]byte, error) {
+	ret := _m.Called(repo)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(*core.GitRepo) []byte); ok {
+		r0 = rf(repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*core.GitRepo) error); ok {
+		r1 = rf(repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLambdatestSecrets provides a mock function with given fields:
 func (_m *SecretsManager) GetLambdatestSecrets() *config.LambdatestConfig {
 	ret := _m.Called()
@@ -51,43 +73,98 @@ func (_m *SecretsManager) GetLambdatestSecrets() *config.LambdatestConfig {
 	return r0
 }
 
-// GetSynapseName provides a mock function with given fields:
-func (_m *SecretsManager) GetSynapseName() string {
-	ret := _m.Called()
+// GetOauthToken provides a mock function with given fields: repo
+func (_m *SecretsManager) GetOauthToken(repo *core.GitRepo) *core.Oauth {
+	ret := _m.Called(repo)
+
+	var r0 *core.Oauth
+	if rf, ok := ret.Get(0).(func(*core.GitRepo) *core.Oauth); ok {
+		r0 = rf(repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Oauth)
+		}
+	}
+
+	return r0
+}
+
+// GetRegistryAuth provides a mock function with given fields: image
+func (_m *SecretsManager) GetRegistryAuth(image string) (string, error) {
+	ret := _m.Called(image)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func() string); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(image)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(image)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// WriteGitSecrets provides a mock function with given fields: path
-func (_m *SecretsManager) WriteGitSecrets(path string) error {
-	ret := _m.Called(path)
+// GetRepoSecretBytes provides a mock function with given fields: repo, build
+func (_m *SecretsManager) GetRepoSecretBytes(repo string, build *core.Payload) ([]byte, error) {
+	ret := _m.Called(repo, build)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(path)
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, *core.Payload) []byte); ok {
+		r0 = rf(repo, build)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *core.Payload) error); ok {
+		r1 = rf(repo, build)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSecretPolicyBytes provides a mock function with given fields:
+func (_m *SecretsManager) GetSecretPolicyBytes() ([]byte, error) {
+	ret := _m.Called()
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// WriteRepoSecrets provides a mock function with given fields: repo, path
-func (_m *SecretsManager) WriteRepoSecrets(repo string, path string) error {
-	ret := _m.Called(repo, path)
+// GetSynapseName provides a mock function with given fields:
+func (_m *SecretsManager) GetSynapseName() string {
+	ret := _m.Called()
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(repo, path)
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
 
 	return r0
@@ -4,10 +4,10 @@ package mocks
 
 import (
 	context "context"
+	sync "sync"
 
+	core "github.com/LambdaTest/test-at-scale/pkg/core"
 	mock "github.com/stretchr/testify/mock"
-
-	sync "sync"
 )
 
 // SynapseManager is an autogenerated mock type for the SynapseManager type
@@ -15,11 +15,39 @@ type SynapseManager struct {
 	mock.Mock
 }
 
+// Connected provides a mock function with given fields:
+func (_m *SynapseManager) Connected() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // InitiateConnection provides a mock function with given fields: ctx, wg, connectionFailed
 func (_m *SynapseManager) InitiateConnection(ctx context.Context, wg *sync.WaitGroup, connectionFailed chan struct{}) {
 	_m.Called(ctx, wg, connectionFailed)
 }
 
+// SendLogChunk provides a mock function with given fields: chunk
+func (_m *SynapseManager) SendLogChunk(chunk *core.LogChunk) error {
+	ret := _m.Called(chunk)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*core.LogChunk) error); ok {
+		r0 = rf(chunk)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewSynapseManager interface {
 	mock.TestingT
 	Cleanup(func())
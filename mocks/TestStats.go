@@ -2,20 +2,24 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // TestStats is an autogenerated mock type for the TestStats type
 type TestStats struct {
 	mock.Mock
 }
 
-// CaptureTestStats provides a mock function with given fields: pid, collectStats
-func (_m *TestStats) CaptureTestStats(pid int32, collectStats bool) error {
-	ret := _m.Called(pid, collectStats)
+// CaptureTestStats provides a mock function with given fields: ctx, pid, collectStats
+func (_m *TestStats) CaptureTestStats(ctx context.Context, pid int32, collectStats bool) error {
+	ret := _m.Called(ctx, pid, collectStats)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int32, bool) error); ok {
-		r0 = rf(pid, collectStats)
+	if rf, ok := ret.Get(0).(func(context.Context, int32, bool) error); ok {
+		r0 = rf(ctx, pid, collectStats)
 	} else {
 		r0 = ret.Error(0)
 	}
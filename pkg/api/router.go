@@ -6,6 +6,7 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/api/testlist"
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
 	"github.com/LambdaTest/test-at-scale/pkg/service/teststats"
 	"github.com/gin-gonic/gin"
 )
@@ -38,6 +39,7 @@ func (r Router) Handler() *gin.Engine {
 	router.GET("/health", health.Handler)
 	router.POST("/results", results.Handler(r.logger, r.testStatsService))
 	router.POST("/test-list", testlist.Handler(r.logger, r.tdResChan))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	return router
 
@@ -0,0 +1,108 @@
+// Package artifactmanager is used for collecting and uploading the build artifacts
+package artifactmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const artifactsCompressedFileName = "artifacts-%s.tzst"
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// artifactManager represents the files/dirs that will be uploaded as artifacts
+type artifactManager struct {
+	azureClient core.AzureClient
+	zstd        core.ZstdCompressor
+	logger      lumber.Logger
+}
+
+// New returns a new ArtifactManager
+func New(z core.ZstdCompressor, azureClient core.AzureClient, logger lumber.Logger) core.ArtifactManager {
+	return &artifactManager{
+		azureClient: azureClient,
+		zstd:        z,
+		logger:      logger,
+	}
+}
+
+func (a *artifactManager) Upload(ctx context.Context, name, workingDir string, patterns ...string) (string, error) {
+	logger := lumber.FromContext(ctx, a.logger)
+	items, err := findArtifacts(workingDir, patterns)
+	if err != nil {
+		logger.Errorf("failed to find artifacts in %s, error: %v", workingDir, err)
+		return "", err
+	}
+	if len(items) == 0 {
+		logger.Infof("No files found matching the artifact patterns %v", patterns)
+		return "", nil
+	}
+	logger.Debugf("Uploading artifacts: %v", items)
+
+	fileName := fmt.Sprintf(artifactsCompressedFileName, unsafeFileNameChars.ReplaceAllString(name, "_"))
+	compressedFilePath := filepath.Join(os.TempDir(), fileName)
+	if err := a.zstd.Compress(ctx, compressedFilePath, false, workingDir, items...); err != nil {
+		logger.Errorf("error while compressing artifacts %s, error: %v", name, err)
+		return "", err
+	}
+	f, err := os.Open(compressedFilePath)
+	if err != nil {
+		logger.Errorf("error while opening compressed artifacts %s, error: %v", name, err)
+		return "", err
+	}
+	defer f.Close()
+
+	sasURL, err := a.azureClient.GetSASURL(ctx, core.PurposeArtifacts, map[string]interface{}{"name": fileName})
+	if err != nil {
+		logger.Errorf("Error while generating SAS Token, error %v", err)
+		return "", err
+	}
+	artifactURL, err := a.azureClient.CreateUsingSASURL(ctx, sasURL, f, "application/zstd")
+	if err != nil {
+		logger.Errorf("error while uploading artifacts %s, error: %v", name, err)
+		return "", err
+	}
+	return artifactURL, nil
+}
+
+// findArtifacts returns the paths relative to workingDir matching any of the glob patterns
+func findArtifacts(workingDir string, patterns []string) ([]string, error) {
+	fsys := os.DirFS(workingDir)
+	matched := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := doublestar.Glob(fsys, path.Clean(pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			matched[match] = true
+		}
+	}
+	items := make([]string, 0, len(matched))
+	for item := range matched {
+		if !hasMatchedParent(item, matched) {
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	return items, nil
+}
+
+// hasMatchedParent reports whether a parent dir of item is matched, since archiving it already includes item
+func hasMatchedParent(item string, matched map[string]bool) bool {
+	for dir := path.Dir(item); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matched[dir] {
+			return true
+		}
+	}
+	return false
+}
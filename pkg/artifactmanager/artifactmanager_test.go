@@ -0,0 +1,101 @@
+package artifactmanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/mocks"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+	"github.com/stretchr/testify/mock"
+)
+
+func createFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		filePath := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s, error: %v", file, err)
+		}
+		if err := os.WriteFile(filePath, []byte("data"), 0644); err != nil {
+			t.Fatalf("failed to write file %s, error: %v", file, err)
+		}
+	}
+}
+
+func Test_findArtifacts(t *testing.T) {
+	workingDir := t.TempDir()
+	createFiles(t, workingDir,
+		"screenshots/a.png",
+		"screenshots/nested/b.png",
+		"coverage/index.html",
+		"src/index.js",
+	)
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"Test no patterns", nil, []string{}},
+		{"Test no matches", []string{"reports/**"}, []string{}},
+		{"Test recursive glob", []string{"screenshots/**/*.png"}, []string{"screenshots/a.png", "screenshots/nested/b.png"}},
+		{"Test directory pattern", []string{"./coverage"}, []string{"coverage"}},
+		{"Test files within matched directory", []string{"screenshots/**"}, []string{"screenshots"}},
+		{
+			"Test overlapping patterns",
+			[]string{"screenshots/*.png", "screenshots/**/*.png"},
+			[]string{"screenshots/a.png", "screenshots/nested/b.png"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findArtifacts(workingDir, tt.patterns)
+			if err != nil {
+				t.Errorf("findArtifacts() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findArtifacts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_artifactManager_Upload(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	workingDir := t.TempDir()
+	createFiles(t, workingDir, "screenshots/a.png")
+	compressedFilePath := filepath.Join(os.TempDir(), "artifacts-task_1.tzst")
+	defer os.Remove(compressedFilePath)
+
+	zstd := new(mocks.ZstdCompressor)
+	zstd.On("Compress", mock.Anything, compressedFilePath, false, workingDir, "screenshots").Return(
+		func(ctx context.Context, compressedFileName string, preservePath bool, workingDirectory string, filesToCompress ...string) error {
+			return os.WriteFile(compressedFileName, []byte("compressed"), 0644)
+		})
+	azureClient := new(mocks.AzureClient)
+	azureClient.On("GetSASURL", mock.Anything, core.PurposeArtifacts, map[string]interface{}{"name": "artifacts-task_1.tzst"}).
+		Return("sas-url", nil)
+	azureClient.On("CreateUsingSASURL", mock.Anything, "sas-url", mock.Anything, "application/zstd").
+		Return("artifact-url", nil)
+
+	a := New(zstd, azureClient, logger)
+	got, err := a.Upload(context.TODO(), "task/1", workingDir, "screenshots/**")
+	if err != nil {
+		t.Fatalf("artifactManager.Upload() error = %v", err)
+	}
+	if got != "artifact-url" {
+		t.Errorf("artifactManager.Upload() = %s, want %s", got, "artifact-url")
+	}
+
+	got, err = a.Upload(context.TODO(), "task/1", workingDir, "reports/**")
+	if err != nil || got != "" {
+		t.Errorf("artifactManager.Upload() = %s, %v, want no upload when nothing matches", got, err)
+	}
+	zstd.AssertNumberOfCalls(t, "Compress", 1)
+}
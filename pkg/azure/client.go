@@ -88,16 +88,17 @@ func NewAzureBlobEnv(cfg *config.NucleusConfig, requests core.Requests, logger l
 
 // FindUsingSASUrl download object based on sasURL
 func (s *store) FindUsingSASUrl(ctx context.Context, sasURL string) (io.ReadCloser, error) {
+	logger := lumber.FromContext(ctx, s.logger)
 	u, err := url.Parse(sasURL)
 	if err != nil {
 		return nil, err
 	}
 	blobClient, err := azblob.NewBlockBlobClientWithNoCredential(u.String(), &azblob.ClientOptions{})
 	if err != nil {
-		s.logger.Errorf("failed to create blob client, error: %v", err)
+		logger.Errorf("failed to create blob client, error: %v", err)
 		return nil, err
 	}
-	s.logger.Debugf("Downloading blob from %s", blobClient.URL())
+	logger.Debugf("Downloading blob from %s", blobClient.URL())
 	out, err := blobClient.Download(ctx, &azblob.DownloadBlobOptions{})
 	if err != nil {
 		return nil, handleError(err)
@@ -108,16 +109,17 @@ func (s *store) FindUsingSASUrl(ctx context.Context, sasURL string) (io.ReadClos
 
 // CreateUsingSASURL creates object using sasURL
 func (s *store) CreateUsingSASURL(ctx context.Context, sasURL string, reader io.Reader, mimeType string) (string, error) {
+	logger := lumber.FromContext(ctx, s.logger)
 	u, err := url.Parse(sasURL)
 	if err != nil {
 		return "", err
 	}
 	blobClient, err := azblob.NewBlockBlobClientWithNoCredential(u.String(), getClientOptions())
 	if err != nil {
-		s.logger.Errorf("failed to create blob client, error: %v", err)
+		logger.Errorf("failed to create blob client, error: %v", err)
 		return "", err
 	}
-	s.logger.Debugf("Uploading blob to %s", blobClient.URL())
+	logger.Debugf("Uploading blob to %s", blobClient.URL())
 
 	_, err = blobClient.UploadStreamToBlockBlob(ctx, reader, azblob.UploadStreamToBlockBlobOptions{
 		HTTPHeaders: &azblob.BlobHTTPHeaders{BlobContentType: &mimeType},
@@ -154,10 +156,11 @@ func (s *store) Create(ctx context.Context, path string, reader io.Reader, mimeT
 
 // GetSASURL calls request neuron to get the SAS url
 func (s *store) GetSASURL(ctx context.Context, purpose core.SASURLPurpose, query map[string]interface{}) (string, error) {
+	logger := lumber.FromContext(ctx, s.logger)
 	reqPayload := &request{Purpose: purpose}
 	reqBody, err := json.Marshal(reqPayload)
 	if err != nil {
-		s.logger.Errorf("failed to marshal request body %v", err)
+		logger.Errorf("failed to marshal request body %v", err)
 		return "", err
 	}
 	defaultQuery, headers := utils.GetDefaultQueryAndHeaders()
@@ -174,7 +177,7 @@ func (s *store) GetSASURL(ctx context.Context, purpose core.SASURLPurpose, query
 	payload := new(response)
 	err = json.Unmarshal(rawBytes, payload)
 	if err != nil {
-		s.logger.Errorf("Error while unmarshalling json, error %v", err)
+		logger.Errorf("Error while unmarshalling json, error %v", err)
 		return "", err
 	}
 	return payload.SASURL, nil
@@ -64,6 +64,7 @@ func NewTestBlockTestService(cfg *config.NucleusConfig, requests core.Requests,
 }
 
 func (tbs *TestBlockTestService) fetchBlockListFromNeuron(ctx context.Context, branch string) error {
+	logger := lumber.FromContext(ctx, tbs.logger)
 	var inp []blocktestAPIResponse
 	query, headers := utils.GetDefaultQueryAndHeaders()
 	query["branch"] = branch
@@ -77,7 +78,7 @@ func (tbs *TestBlockTestService) fetchBlockListFromNeuron(ctx context.Context, b
 	}
 
 	if jsonErr := json.Unmarshal(rawBytes, &inp); jsonErr != nil {
-		tbs.logger.Errorf("Unable to fetch blocklist response: %v", jsonErr)
+		logger.Errorf("Unable to fetch blocklist response: %v", jsonErr)
 		return jsonErr
 	}
 	// populate bl
@@ -95,6 +96,7 @@ func (tbs *TestBlockTestService) fetchBlockListFromNeuron(ctx context.Context, b
 
 // GetBlockTests provides list of blocked test cases
 func (tbs *TestBlockTestService) GetBlockTests(ctx context.Context, blocklistYAML []string, branch string) error {
+	logger := lumber.FromContext(ctx, tbs.logger)
 	tbs.once.Do(func() {
 
 		blocktestLocators := make([]*blocktestLocator, 0, len(blocklistYAML))
@@ -108,22 +110,22 @@ func (tbs *TestBlockTestService) GetBlockTests(ctx context.Context, blocklistYAM
 		tbs.populateBlockList("yml", blocktestLocators)
 
 		if err := tbs.fetchBlockListFromNeuron(ctx, branch); err != nil {
-			tbs.logger.Errorf("Unable to fetch remote blocklist: %v. Ignoring remote response", err)
+			logger.Errorf("Unable to fetch remote blocklist: %v. Ignoring remote response", err)
 			tbs.errChan <- err
 			return
 		}
-		tbs.logger.Infof("Block tests: %+v", tbs.blockTestEntities)
+		logger.Infof("Block tests: %+v", tbs.blockTestEntities)
 
 		// write blocklistest tests on disk
 		marshalledBlocklist, err := json.Marshal(tbs.blockTestEntities)
 		if err != nil {
-			tbs.logger.Errorf("Unable to json marshal blocklist: %+v", err)
+			logger.Errorf("Unable to json marshal blocklist: %+v", err)
 			tbs.errChan <- err
 			return
 		}
 
 		if err = ioutil.WriteFile(global.BlockTestFileLocation, marshalledBlocklist, 0644); err != nil {
-			tbs.logger.Errorf("Unable to write blocklist file: %+v", err)
+			logger.Errorf("Unable to write blocklist file: %+v", err)
 			tbs.errChan <- err
 			return
 		}
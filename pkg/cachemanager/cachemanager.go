@@ -2,18 +2,24 @@ package cachemanager
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/fileutils"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
 )
 
 const (
@@ -24,7 +30,7 @@ const (
 	nodeModules                   = "node_modules"
 	defaultCompressedFileName     = "cache.tzst"
 	workspaceCompressedFilenameV1 = "workspace.tzst"
-	workspaceCompressedFilenameV2 = "workspace-%s.tzst"
+	workspaceCompressedFilenameV2 = "workspace-%s-%x.tzst"
 )
 
 // cache represents the files/dirs that will be cached
@@ -37,6 +43,8 @@ type cache struct {
 	homeDir     string
 }
 
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
 var cacheBlobURL string
 var apiErr error
 
@@ -62,21 +70,28 @@ func (c *cache) getCacheSASURL(ctx context.Context, cacheKey string) (string, er
 	return cacheBlobURL, apiErr
 }
 
-func (c *cache) Download(ctx context.Context, cacheKey string) error {
+func (c *cache) Download(ctx context.Context, cacheKey string) (err error) {
+	logger := lumber.FromContext(ctx, c.logger)
+	defer metrics.ObserveStage(metrics.StageCacheDownload, time.Now())
+	ctx, span := tracing.Start(ctx, tracing.SpanCacheDownload)
+	core.SetProgressStage(ctx, tracing.SpanCacheDownload)
+	defer func() { tracing.End(span, err) }()
 	sasURL, err := c.getCacheSASURL(ctx, cacheKey)
 	if err != nil {
-		c.logger.Errorf("Error while generating SAS Token, error %v", err)
+		logger.Errorf("Error while generating SAS Token, error %v", err)
 		return err
 	}
 	resp, err := c.azureClient.FindUsingSASUrl(ctx, sasURL)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
-			c.logger.Infof("Cache not found for key: %s", cacheKey)
+			logger.Infof("Cache not found for key: %s", cacheKey)
+			metrics.RecordCacheResult(false)
 			return nil
 		}
-		c.logger.Errorf("Error while downloading cache for key: %s, error %v", cacheKey, err)
+		logger.Errorf("Error while downloading cache for key: %s, error %v", cacheKey, err)
 		return err
 	}
+	metrics.RecordCacheResult(true)
 	c.skipUpload = true
 	defer resp.Close()
 
@@ -93,18 +108,23 @@ func (c *cache) Download(ctx context.Context, cacheKey string) error {
 	return c.zstd.Decompress(ctx, cachedFilePath, true, global.RepoDir)
 }
 
-func (c *cache) Upload(ctx context.Context, cacheKey string, itemsToCompress ...string) error {
+func (c *cache) Upload(ctx context.Context, cacheKey string, itemsToCompress ...string) (err error) {
+	logger := lumber.FromContext(ctx, c.logger)
 	if c.skipUpload {
-		c.logger.Infof("Cache hit occurred on the key %s, not saving cache.", cacheKey)
+		logger.Infof("Cache hit occurred on the key %s, not saving cache.", cacheKey)
 		return nil
 	}
+	defer metrics.ObserveStage(metrics.StageCacheUpload, time.Now())
+	ctx, span := tracing.Start(ctx, tracing.SpanCacheUpload)
+	core.SetProgressStage(ctx, tracing.SpanCacheUpload)
+	defer func() { tracing.End(span, err) }()
 
 	validatedItems := make([]string, 0, len(itemsToCompress))
 	if len(itemsToCompress) == 0 {
 		dirs, err := c.getDefaultDirs()
-		c.logger.Debugf("Dirs: %+v", dirs)
+		logger.Debugf("Dirs: %+v", dirs)
 		if err != nil {
-			c.logger.Errorf("failed to get default cache directories, error %v", err)
+			logger.Errorf("failed to get default cache directories, error %v", err)
 			return nil
 		}
 		itemsToCompress = append(itemsToCompress, dirs...)
@@ -118,47 +138,53 @@ func (c *cache) Upload(ctx context.Context, cacheKey string, itemsToCompress ...
 		if exists {
 			validatedItems = append(validatedItems, item)
 		} else {
-			c.logger.Debugf("%s does not exist, skipping upload", item)
+			logger.Debugf("%s does not exist, skipping upload", item)
 		}
 	}
 	if len(validatedItems) == 0 {
-		c.logger.Debugf("No valid files/dirs found to cache")
+		logger.Debugf("No valid files/dirs found to cache")
 		return nil
 	}
 
-	err := c.zstd.Compress(ctx, defaultCompressedFileName, true, global.RepoDir, validatedItems...)
+	err = c.zstd.Compress(ctx, defaultCompressedFileName, true, global.RepoDir, validatedItems...)
 	if err != nil {
-		c.logger.Errorf("error while compressing files with key %s, error: %v", cacheKey, err)
+		logger.Errorf("error while compressing files with key %s, error: %v", cacheKey, err)
 		return err
 	}
 
 	f, err := os.Open(filepath.Join(global.RepoDir, defaultCompressedFileName))
 	if err != nil {
-		c.logger.Errorf("error while opening compressed file with key %s, error: %v", cacheKey, err)
+		logger.Errorf("error while opening compressed file with key %s, error: %v", cacheKey, err)
 		return err
 	}
 
 	defer f.Close()
 	sasURL, err := c.getCacheSASURL(ctx, cacheKey)
 	if err != nil {
-		c.logger.Errorf("Error while generating SAS Token, error %v", err)
+		logger.Errorf("Error while generating SAS Token, error %v", err)
 		return err
 	}
 	_, err = c.azureClient.CreateUsingSASURL(ctx, sasURL, f, "application/zstd")
 	if err != nil {
-		c.logger.Errorf("error while uploading cached file %s with key %s, error: %v", defaultCompressedFileName, cacheKey, err)
+		logger.Errorf("error while uploading cached file %s with key %s, error: %v", defaultCompressedFileName, cacheKey, err)
 		return err
 	}
 	return nil
 }
 
-func (c *cache) CacheWorkspace(ctx context.Context, subModule string) error {
+func (c *cache) CacheWorkspace(ctx context.Context, subModule string, excludePaths ...string) (err error) {
+	logger := lumber.FromContext(ctx, c.logger)
+	ctx, span := tracing.Start(ctx, tracing.SpanCacheWorkspace)
+	core.SetProgressStage(ctx, tracing.SpanCacheWorkspace)
+	defer func() { tracing.End(span, err) }()
 	tmpDir := os.TempDir()
-	workspaceCompressedFilename := workspaceCompressedFilenameV1
-	if subModule != "" {
-		workspaceCompressedFilename = fmt.Sprintf(workspaceCompressedFilenameV2, subModule)
+	workspaceCompressedFilename := getWorkspaceCompressedFilename(subModule)
+	items, err := getWorkspaceItems(global.HomeDir, global.RepoDir, excludePaths)
+	if err != nil {
+		logger.Errorf("failed to list workspace items for submodule %s, error: %v", subModule, err)
+		return err
 	}
-	if err := c.zstd.Compress(ctx, workspaceCompressedFilename, true, tmpDir, global.HomeDir); err != nil {
+	if err := c.zstd.Compress(ctx, workspaceCompressedFilename, true, tmpDir, items...); err != nil {
 		return err
 	}
 	src := filepath.Join(tmpDir, workspaceCompressedFilename)
@@ -170,12 +196,22 @@ func (c *cache) CacheWorkspace(ctx context.Context, subModule string) error {
 }
 
 func (c *cache) ExtractWorkspace(ctx context.Context, subModule string) error {
+	logger := lumber.FromContext(ctx, c.logger)
 	tmpDir := os.TempDir()
-	workspaceCompressedFilename := workspaceCompressedFilenameV1
+	workspaceCompressedFilename := getWorkspaceCompressedFilename(subModule)
+	src := filepath.Join(global.WorkspaceCacheDir, workspaceCompressedFilename)
 	if subModule != "" {
-		workspaceCompressedFilename = fmt.Sprintf(workspaceCompressedFilenameV2, subModule)
+		exists, err := fileutils.CheckIfExists(src)
+		if err != nil {
+			return err
+		}
+		// workspace cached by discovery without submodule support
+		if !exists {
+			logger.Infof("workspace cache for submodule %s not found, extracting complete workspace", subModule)
+			workspaceCompressedFilename = workspaceCompressedFilenameV1
+			src = filepath.Join(global.WorkspaceCacheDir, workspaceCompressedFilename)
+		}
 	}
-	src := filepath.Join(global.WorkspaceCacheDir, workspaceCompressedFilename)
 	dst := filepath.Join(tmpDir, workspaceCompressedFilename)
 	if err := fileutils.CopyFile(src, dst, false); err != nil {
 		return err
@@ -186,6 +222,66 @@ func (c *cache) ExtractWorkspace(ctx context.Context, subModule string) error {
 	return nil
 }
 
+// getWorkspaceCompressedFilename returns the name of the workspace archive for the submodule
+func getWorkspaceCompressedFilename(subModule string) string {
+	if subModule == "" {
+		return workspaceCompressedFilenameV1
+	}
+	// the hash of the name tells apart the submodules whose names are the same once sanitized, e.g. a/b and a_b
+	hash := sha256.Sum256([]byte(subModule))
+	return fmt.Sprintf(workspaceCompressedFilenameV2, unsafeFileNameChars.ReplaceAllString(subModule, "_"), hash[:4])
+}
+
+// getWorkspaceItems returns the paths under root which should be archived, leaving out excludePaths.
+// excludePaths are relative to repoDir. Directories containing an excluded path are expanded
+// so that all their other entries are still archived.
+func getWorkspaceItems(root, repoDir string, excludePaths []string) ([]string, error) {
+	excluded := make(map[string]bool, len(excludePaths))
+	parents := make(map[string]bool)
+	for _, p := range excludePaths {
+		absPath := filepath.Join(repoDir, p)
+		if absPath == repoDir || !strings.HasPrefix(absPath, repoDir+string(filepath.Separator)) {
+			// never exclude the repo root itself or paths outside it
+			continue
+		}
+		excluded[absPath] = true
+		for dir := filepath.Dir(absPath); ; dir = filepath.Dir(dir) {
+			parents[dir] = true
+			if dir == root || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	if !parents[root] {
+		return []string{root}, nil
+	}
+	return expandWorkspaceDir(root, excluded, parents)
+}
+
+func expandWorkspaceDir(dir string, excluded, parents map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		switch {
+		case excluded[entryPath]:
+			continue
+		case parents[entryPath] && entry.IsDir():
+			subItems, err := expandWorkspaceDir(entryPath, excluded, parents)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, subItems...)
+		default:
+			items = append(items, entryPath)
+		}
+	}
+	return items, nil
+}
+
 func (c *cache) getDefaultDirs() ([]string, error) {
 	defaultDirs := []string{}
 	f, err := os.Open(global.RepoDir)
@@ -0,0 +1,73 @@
+package cachemanager
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_getWorkspaceItems(t *testing.T) {
+	homeDir := t.TempDir()
+	repoDir := filepath.Join(homeDir, "repo")
+	for _, dir := range []string{".nvm", "repo/node_modules", "repo/packages/api", "repo/packages/web"} {
+		if err := os.MkdirAll(filepath.Join(homeDir, dir), 0755); err != nil {
+			t.Fatalf("failed to create directory %s, error: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write file, error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		excludePaths []string
+		want         []string
+	}{
+		{"Test without exclusions", nil, []string{homeDir}},
+		{"Test repo root is never excluded", []string{"."}, []string{homeDir}},
+		{"Test paths outside repo are never excluded", []string{"../.nvm"}, []string{homeDir}},
+		{
+			"Test exclude submodule path",
+			[]string{"packages/web"},
+			[]string{
+				filepath.Join(homeDir, ".nvm"),
+				filepath.Join(repoDir, "node_modules"),
+				filepath.Join(repoDir, "package.json"),
+				filepath.Join(repoDir, "packages/api"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getWorkspaceItems(homeDir, repoDir, tt.excludePaths)
+			if err != nil {
+				t.Errorf("getWorkspaceItems() error = %v", err)
+				return
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getWorkspaceItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getWorkspaceCompressedFilename(t *testing.T) {
+	tests := []struct {
+		subModule string
+		want      string
+	}{
+		{"", "workspace.tzst"},
+		{"api", "workspace-api-14c2529e.tzst"},
+		{"web/app v2", "workspace-web_app_v2-b8e35e55.tzst"},
+		{"a/b", "workspace-a_b-c14cddc0.tzst"},
+		{"a_b", "workspace-a_b-648fa9b3.tzst"},
+	}
+	for _, tt := range tests {
+		if got := getWorkspaceCompressedFilename(tt.subModule); got != tt.want {
+			t.Errorf("getWorkspaceCompressedFilename(%q) = %s, want %s", tt.subModule, got, tt.want)
+		}
+	}
+}
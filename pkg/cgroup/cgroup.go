@@ -0,0 +1,242 @@
+// Package cgroup runs the commands of nucleus in a child cgroup (v2) with resource limits, so that a runaway
+// command is killed on its own instead of taking nucleus down with it.
+package cgroup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	mountPoint = "/sys/fs/cgroup"
+	selfCgroup = "/proc/self/cgroup"
+	// nucleusGroup is the leaf nucleus is moved to, as processes may only live in the leaves of cgroup v2
+	nucleusGroup = "nucleus"
+	// commandsGroup is the cgroup of the commands
+	commandsGroup = "commands"
+	// cpuPeriod is the period of the CPU quota in microseconds
+	cpuPeriod = 100000
+)
+
+// ErrUnsupported is returned when cgroup v2 with the cpu and memory controllers is not available
+var ErrUnsupported = errors.New("cgroup v2 with cpu and memory controllers is not available")
+
+// Limits are the resource limits of a cgroup, a zero limit leaves the resource unlimited
+type Limits struct {
+	// MilliCPU is the CPU limit in millicores
+	MilliCPU int64
+	// Memory is the memory limit in bytes
+	Memory int64
+}
+
+// Group is a cgroup the commands are run in
+type Group struct {
+	path   string
+	limits Limits
+	// root is the cgroup of nucleus, which is made read-only once the cgroup of the commands is set up
+	root string
+	// procs is the cgroup.procs file of the cgroup opened before the cgroup of nucleus was made read-only,
+	// through which the commands are moved to it
+	procs *os.File
+}
+
+type groupKey struct{}
+
+// New creates the cgroup of the commands beneath the cgroup of nucleus, limited to limits. The container runtime
+// must delegate the cgroup of nucleus writable, nucleus does not remount the cgroupfs.
+func New(limits Limits) (*Group, error) {
+	g, err := newGroup(mountPoint, selfCgroup, limits)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.protect(); err != nil {
+		g.procs.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+// protect makes the cgroup of nucleus read-only with a bind mount, so that the commands can neither move out of
+// their cgroup nor change its limits or the ones of nucleus. The commands can not undo the mount, as they are
+// executed without CAP_SYS_ADMIN.
+func (g *Group) protect() error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(g.root, &stat); err != nil {
+		return err
+	}
+	if err := syscall.Mount(g.root, g.root, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("failed to bind mount cgroup %s, error: %w", g.root, err)
+	}
+	// the flags of the mount are kept, a mount of a user namespace can not drop them
+	flags := syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY |
+		uintptr(stat.Flags)&(syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC)
+	if err := syscall.Mount("", g.root, "", flags, ""); err != nil {
+		_ = syscall.Unmount(g.root, syscall.MNT_DETACH)
+		return fmt.Errorf("failed to make cgroup %s read-only, error: %w", g.root, err)
+	}
+	return nil
+}
+
+func newGroup(mount, self string, limits Limits) (*Group, error) {
+	current, err := currentCgroup(self)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(mount, current)
+	controllers, err := os.ReadFile(filepath.Join(root, "cgroup.controllers"))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if !hasControllers(string(controllers), "cpu", "memory") {
+		return nil, ErrUnsupported
+	}
+
+	// the controllers can only be delegated to the children of a cgroup without processes
+	leaf := filepath.Join(root, nucleusGroup)
+	if err := os.MkdirAll(leaf, 0755); err != nil {
+		return nil, err
+	}
+	pids, err := readPids(filepath.Join(root, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	for _, pid := range pids {
+		// the processes exiting meanwhile can not be moved
+		if err := writeFile(filepath.Join(leaf, "cgroup.procs"), strconv.Itoa(pid)); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return nil, fmt.Errorf("failed to move process %d to cgroup %s, error: %w", pid, leaf, err)
+		}
+	}
+	if err := writeFile(filepath.Join(root, "cgroup.subtree_control"), "+cpu +memory"); err != nil {
+		return nil, fmt.Errorf("failed to enable the cpu and memory controllers, error: %w", err)
+	}
+
+	g := &Group{path: filepath.Join(root, commandsGroup), limits: limits, root: root}
+	if err := os.MkdirAll(g.path, 0755); err != nil {
+		return nil, err
+	}
+	if limits.Memory > 0 {
+		if err := writeFile(filepath.Join(g.path, "memory.max"), strconv.FormatInt(limits.Memory, 10)); err != nil {
+			return nil, fmt.Errorf("failed to set memory limit, error: %w", err)
+		}
+		// the commands are killed on reaching the limit instead of being swapped out, swap may not be enabled
+		_ = writeFile(filepath.Join(g.path, "memory.swap.max"), "0")
+	}
+	if limits.MilliCPU > 0 {
+		quota := limits.MilliCPU * cpuPeriod / 1000
+		if err := writeFile(filepath.Join(g.path, "cpu.max"), fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+			return nil, fmt.Errorf("failed to set cpu limit, error: %w", err)
+		}
+	}
+	if g.procs, err = os.OpenFile(filepath.Join(g.path, "cgroup.procs"), os.O_WRONLY|os.O_CREATE, 0644); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Limits returns the resource limits of the cgroup
+func (g *Group) Limits() Limits {
+	return g.limits
+}
+
+// OOMKills returns the number of processes of the cgroup killed by the OOM killer, read from its memory events
+func (g *Group) OOMKills() (int, error) {
+	f, err := os.Open(filepath.Join(g.path, "memory.events"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			return strconv.Atoi(fields[1])
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// ContextWithGroup returns a copy of ctx in which the commands are run in g
+func ContextWithGroup(ctx context.Context, g *Group) context.Context {
+	return context.WithValue(ctx, groupKey{}, g)
+}
+
+// FromContext returns the cgroup the commands of ctx are run in, nil if they are not run in a cgroup
+func FromContext(ctx context.Context) *Group {
+	g, _ := ctx.Value(groupKey{}).(*Group)
+	return g
+}
+
+// FormatMemory formats bytes in GiB, or MiB below a GiB, e.g. 7.8GiB
+func FormatMemory(bytes int64) string {
+	if bytes >= 1<<30 {
+		return fmt.Sprintf("%.1fGiB", float64(bytes)/(1<<30))
+	}
+	return fmt.Sprintf("%dMiB", bytes>>20)
+}
+
+// currentCgroup returns the path of the cgroup v2 of the process, from its cgroup file
+func currentCgroup(self string) (string, error) {
+	data, err := os.ReadFile(self)
+	if err != nil {
+		return "", ErrUnsupported
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	// the cgroup v1 hierarchies are listed along with the unified one on hybrid hosts
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "0::") {
+		return "", ErrUnsupported
+	}
+	return strings.TrimPrefix(lines[0], "0::"), nil
+}
+
+func hasControllers(available string, controllers ...string) bool {
+	fields := strings.Fields(available)
+	for _, controller := range controllers {
+		found := false
+		for _, field := range fields {
+			if field == controller {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func readPids(path string) ([]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, field := range strings.Fields(string(data)) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// writeFile writes value to a cgroup interface file, which takes a single value per write
+func writeFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
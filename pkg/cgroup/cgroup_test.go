@@ -0,0 +1,206 @@
+package cgroup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// newFakeCgroupfs creates the interface files of the cgroup of nucleus in a temp dir standing in for cgroupfs
+func newFakeCgroupfs(t *testing.T, self, controllers string) (mount, selfFile string) {
+	mount = t.TempDir()
+	root := filepath.Join(mount, "/kubepods/task")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("failed to create cgroup, error: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(root, "cgroup.controllers"): controllers,
+		filepath.Join(root, "cgroup.procs"):       "1\n",
+	}
+	selfFile = filepath.Join(t.TempDir(), "cgroup")
+	files[selfFile] = self
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s, error: %v", path, err)
+		}
+	}
+	return mount, selfFile
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s, error: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestNewGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		self        string
+		controllers string
+		wantErr     error
+	}{
+		{"cgroup v2", "0::/kubepods/task\n", "cpuset cpu io memory pids", nil},
+		{"cgroup v1", "12:memory:/kubepods/task\n0::/kubepods/task\n", "cpu memory", ErrUnsupported},
+		{"memory controller not delegated", "0::/kubepods/task\n", "cpu pids", ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mount, self := newFakeCgroupfs(t, tt.self, tt.controllers)
+			limits := Limits{MilliCPU: 1900, Memory: 7936 << 20}
+			g, err := newGroup(mount, self, limits)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			root := filepath.Join(mount, "/kubepods/task")
+			if got := readFile(t, filepath.Join(root, nucleusGroup, "cgroup.procs")); got != "1" {
+				t.Errorf("processes of nucleus leaf = %q, want the processes of the cgroup of nucleus", got)
+			}
+			if got := readFile(t, filepath.Join(root, "cgroup.subtree_control")); got != "+cpu +memory" {
+				t.Errorf("cgroup.subtree_control = %q, want the cpu and memory controllers", got)
+			}
+			if got := readFile(t, filepath.Join(g.path, "memory.max")); got != "8321499136" {
+				t.Errorf("memory.max = %q, want 8321499136", got)
+			}
+			if got := readFile(t, filepath.Join(g.path, "cpu.max")); got != "190000 100000" {
+				t.Errorf("cpu.max = %q, want 190000 100000", got)
+			}
+			if got := FromContext(ContextWithGroup(context.Background(), g)); got != g {
+				t.Errorf("FromContext() = %v, want %v", got, g)
+			}
+		})
+	}
+}
+
+func TestOOMKills(t *testing.T) {
+	tests := []struct {
+		name   string
+		events string
+		want   int
+	}{
+		{"no oom kills", "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n", 0},
+		{"oom kills", "low 0\nhigh 0\nmax 12\noom 2\noom_kill 2\noom_group_kill 0\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Group{path: t.TempDir()}
+			if err := os.WriteFile(filepath.Join(g.path, "memory.events"), []byte(tt.events), 0644); err != nil {
+				t.Fatalf("failed to write memory events, error: %v", err)
+			}
+			got, err := g.OOMKills()
+			if err != nil {
+				t.Fatalf("OOMKills() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OOMKills() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMemory(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{7936 << 20, "7.8GiB"},
+		{4096 << 20, "4.0GiB"},
+		{512 << 20, "512MiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatMemory(tt.bytes); got != tt.want {
+			t.Errorf("FormatMemory(%d) = %v, want %v", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestMain(m *testing.M) {
+	// the commands wrapped by the tests re-execute the test binary
+	ExecShim()
+	os.Exit(m.Run())
+}
+
+// newTestGroup returns a group of a temp dir standing in for its cgroup
+func newTestGroup(t *testing.T) *Group {
+	g := &Group{path: t.TempDir()}
+	procs, err := os.OpenFile(filepath.Join(g.path, "cgroup.procs"), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatalf("failed to open cgroup.procs, error: %v", err)
+	}
+	t.Cleanup(func() { procs.Close() })
+	g.procs = procs
+	return g
+}
+
+func TestWrap(t *testing.T) {
+	g := newTestGroup(t)
+	out := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command("sh", "-c", "echo $0 $1 > "+out, "first", "second")
+	if err := g.Wrap(cmd); err != nil {
+		t.Fatalf("Wrap() error = %v", err)
+	}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run wrapped command, error: %v", err)
+	}
+	if got, want := readFile(t, filepath.Join(g.path, "cgroup.procs")), strconv.Itoa(cmd.Process.Pid); got != want {
+		t.Errorf("processes of cgroup = %q, want the command %s", got, want)
+	}
+	if got := readFile(t, out); got != "first second\n" {
+		t.Errorf("output of wrapped command = %q, want the arguments of the command", got)
+	}
+	if err := g.Wrap(exec.Command("does-not-exist")); err == nil {
+		t.Errorf("Wrap() of missing executable error = nil, want error")
+	}
+}
+
+func TestProtect(t *testing.T) {
+	mount, self := newFakeCgroupfs(t, "0::/kubepods/task\n", "cpu memory")
+	g, err := newGroup(mount, self, Limits{Memory: 512 << 20})
+	if err != nil {
+		t.Fatalf("newGroup() error = %v", err)
+	}
+	defer g.procs.Close()
+	if err := g.protect(); err != nil {
+		t.Skipf("can not mount in the test environment, error: %v", err)
+	}
+	t.Cleanup(func() { _ = syscall.Unmount(g.root, syscall.MNT_DETACH) })
+
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"move to cgroup of nucleus", "echo $$ > " + filepath.Join(g.root, nucleusGroup, "cgroup.procs")},
+		{"move to parent cgroup", "echo $$ > " + filepath.Join(g.root, "cgroup.procs")},
+		{"raise memory limit", "echo max > " + filepath.Join(g.path, "memory.max")},
+		{"disable memory controller", "echo -memory > " + filepath.Join(g.root, "cgroup.subtree_control")},
+		{"remount writable", "mount -o remount,bind,rw " + g.root},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", tt.script)
+			if err := g.Wrap(cmd); err != nil {
+				t.Fatalf("Wrap() error = %v", err)
+			}
+			if out, err := cmd.CombinedOutput(); err == nil {
+				t.Errorf("command escaping the cgroup succeeded, output: %s", out)
+			}
+			if got := readFile(t, filepath.Join(g.path, "cgroup.procs")); !strings.Contains(got, strconv.Itoa(cmd.Process.Pid)) {
+				t.Errorf("processes of cgroup = %q, want the command %d", got, cmd.Process.Pid)
+			}
+		})
+	}
+	if got := readFile(t, filepath.Join(g.path, "memory.max")); got != "536870912" {
+		t.Errorf("memory.max = %q, want 536870912", got)
+	}
+}
@@ -0,0 +1,137 @@
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// shimArg marks the re-execution of nucleus which moves itself to a cgroup and executes a command in its place
+const shimArg = "__exec-in-cgroup"
+
+const (
+	// capSysAdmin is CAP_SYS_ADMIN, which allows to mount the cgroupfs writable again
+	capSysAdmin = 21
+	// prCapAmbient and prCapAmbientLower lower an ambient capability with prctl
+	prCapAmbient      = 47
+	prCapAmbientLower = 3
+	// capabilityVersion3 is _LINUX_CAPABILITY_VERSION_3 of capget and capset
+	capabilityVersion3 = 0x20080522
+)
+
+type capHeader struct {
+	version uint32
+	pid     int32
+}
+
+type capData struct {
+	effective   uint32
+	permitted   uint32
+	inheritable uint32
+}
+
+// Wrap rewrites cmd to start in the cgroup. Instead of the command nucleus is re-executed, moves itself to the
+// cgroup and executes the command in its place, so that the command is in the cgroup before any of its code runs
+// and every process it spawns starts in the cgroup. The re-execution inherits the cgroup.procs file of the cgroup,
+// as the cgroupfs is read-only. The executable of nucleus must call ExecShim first thing.
+func (g *Group) Wrap(cmd *exec.Cmd) error {
+	path := cmd.Path
+	// a relative path is taken relative to the directory of the command, like exec.Cmd does
+	if strings.ContainsRune(path, filepath.Separator) && !filepath.IsAbs(path) && cmd.Dir != "" {
+		path = filepath.Join(cmd.Dir, path)
+	}
+	path, err := exec.LookPath(path)
+	if err != nil {
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := cmd.Args
+	if len(args) == 0 {
+		args = []string{cmd.Path}
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, g.procs)
+	// the extra files follow stdin, stdout and stderr
+	fd := 2 + len(cmd.ExtraFiles)
+	cmd.Path = self
+	cmd.Args = append([]string{self, shimArg, strconv.Itoa(fd), path}, args...)
+	return nil
+}
+
+// ExecShim executes the command of a re-execution of nucleus made by Wrap after moving the process to the
+// cgroup, it returns if the process is not such a re-execution.
+func ExecShim() {
+	if len(os.Args) < 5 || os.Args[1] != shimArg {
+		return
+	}
+	fd, path, args := os.Args[2], os.Args[3], os.Args[4:]
+	// the capabilities are per thread, the command must be executed by the thread dropping them
+	runtime.LockOSThread()
+	// the command is run without the limits rather than not at all, as it was before the cgroups
+	if err := moveToCgroup(fd); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to move process to cgroup, error: %v\n", err)
+	}
+	if err := dropCapability(capSysAdmin); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to drop CAP_SYS_ADMIN, error: %v\n", err)
+		os.Exit(126)
+	}
+	err := syscall.Exec(path, args, os.Environ())
+	fmt.Fprintf(os.Stderr, "failed to execute %s, error: %v\n", path, err)
+	os.Exit(126)
+}
+
+// moveToCgroup writes the pid of the process to the inherited cgroup.procs file fd, which is closed so that the
+// command does not inherit it
+func moveToCgroup(fd string) error {
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return err
+	}
+	procs := os.NewFile(uintptr(n), "cgroup.procs")
+	defer procs.Close()
+	_, err = procs.WriteString(strconv.Itoa(os.Getpid()))
+	return err
+}
+
+// dropCapability drops capability from the bounding, ambient, inheritable, permitted and effective sets of the
+// process, so that the command executed next does not have it either
+func dropCapability(capability uintptr) error {
+	inBounding, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_CAPBSET_READ, capability, 0)
+	if errno != 0 {
+		return errno
+	}
+	if inBounding == 1 {
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_CAPBSET_DROP, capability, 0); errno != 0 {
+			return errno
+		}
+	}
+	// kernels without ambient capabilities fail with EINVAL
+	_, _, errno = syscall.RawSyscall6(syscall.SYS_PRCTL, prCapAmbient, prCapAmbientLower, capability, 0, 0, 0)
+	if errno != 0 && errno != syscall.EINVAL {
+		return errno
+	}
+	header := capHeader{version: capabilityVersion3}
+	var data [2]capData
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPGET,
+		uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return errno
+	}
+	mask := ^uint32(1 << (capability % 32))
+	set := &data[capability/32]
+	set.effective &= mask
+	set.permitted &= mask
+	set.inheritable &= mask
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPSET,
+		uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"os"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+	"github.com/LambdaTest/test-at-scale/pkg/utils"
+)
+
+// allowedEnvVars are the env variables of the container required by the user commands
+var allowedEnvVars = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LANGUAGE", "TZ", "TMPDIR", "HOSTNAME",
+	"NVM_DIR", "NVM_BIN", "NVM_INC", "NODE_PATH", "JAVA_HOME",
+}
+
+// tasEnvVars are the env variables set by nucleus which are documented for use in user commands
+var tasEnvVars = []string{
+	"TASK_ID", "ORG_ID", "BUILD_ID", "COMMIT_ID", "REPO_ID", "BRANCH_NAME", "ENV", "CODE_COVERAGE_DIR",
+	"ENDPOINT_POST_TEST_LIST", "ENDPOINT_POST_TEST_RESULTS", "REPO_ROOT", "REPO_CACHE_DIR", "BLOCK_TESTS_FILE",
+	global.SubModuleName, global.ModulePath,
+}
+
+// allowedEnvPrefixes are the prefixes of the allowed locale env variables
+var allowedEnvPrefixes = []string{"LC_"}
+
+// getInheritedEnv returns the env variables of nucleus to be passed to user commands, which are
+// the allowed ones and the ones in inheritEnv. Env variables holding credentials are never passed.
+func getInheritedEnv(inheritEnv []string) []string {
+	allowed := make(map[string]bool, len(allowedEnvVars)+len(tasEnvVars)+len(inheritEnv))
+	for _, names := range [][]string{allowedEnvVars, tasEnvVars, inheritEnv} {
+		for _, name := range names {
+			allowed[name] = true
+		}
+	}
+	envVars := []string{}
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if utils.IsInternalEnv(name) {
+			continue
+		}
+		if allowed[name] || hasAnyPrefix(name, allowedEnvPrefixes) {
+			envVars = append(envVars, env)
+		}
+	}
+	return envVars
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
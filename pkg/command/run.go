@@ -2,21 +2,32 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/logstream"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+	"github.com/LambdaTest/test-at-scale/pkg/procutils"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
+	"github.com/LambdaTest/test-at-scale/pkg/utils"
 )
 
+// outputTailSize is the number of trailing output bytes recorded for every step.
+const outputTailSize = 4096
+
 type manager struct {
 	logger       lumber.Logger
 	secretParser core.SecretParser
 	azureClient  core.AzureClient
+	inheritEnv   []string
+	mu           sync.RWMutex
 }
 
 // NewExecutionManager returns new instance of manger
@@ -28,52 +39,147 @@ func NewExecutionManager(secretParser core.SecretParser,
 		azureClient:  azureClient}
 }
 
-// ExecuteUserCommands executes user commands
+// ExecuteUserCommands executes user commands.
+// Every step runs in a separate shell with its own timeout and retries. The working directory, the exported
+// variables and the functions of a step that succeeds carry over to the next step, as in a single shell session.
 func (m *manager) ExecuteUserCommands(ctx context.Context,
 	commandType core.CommandType,
 	payload *core.Payload,
 	runConfig *core.Run,
 	secretData map[string]string,
 	logwriter core.LogWriterStrategy,
-	cwd string) error {
-	script, err := m.createScript(runConfig.Commands, secretData)
-	if err != nil {
-		return err
-	}
+	cwd string) (results []*core.StepResult, err error) {
+	ctx, logger := lumber.ContextWithFields(ctx, m.logger, lumber.Fields{lumber.FieldStage: commandType})
+	defer metrics.ObserveStage(string(commandType), time.Now())
+	ctx, span := tracing.Start(ctx, string(commandType))
+	core.SetProgressStage(ctx, string(commandType))
+	defer func() { tracing.End(span, err) }()
+	// the results are reported with the task whether the steps fail or not
+	defer func() { core.RecordSteps(ctx, results) }()
 	envVars, err := m.GetEnvVariables(runConfig.EnvMap, secretData)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	azureReader, azureWriter := io.Pipe()
 	defer azureWriter.Close()
-
-	errChan := logwriter.Write(ctx, azureReader)
+	// the logs of an aborted command are uploaded within the grace period
+	logCtx, logCancel := core.GraceContext(ctx)
+	defer logCancel()
+	errChan := logwriter.Write(logCtx, azureReader)
 	defer m.closeAndWriteLog(azureWriter, errChan, commandType)
-	logWriter := lumber.NewWriter(m.logger)
+	logWriter := lumber.NewWriter(logger)
 	defer logWriter.Close()
 	multiWriter := io.MultiWriter(logWriter, azureWriter)
-	maskWriter := logstream.NewMasker(multiWriter, secretData)
-
-	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", script)
-	cmd.Dir = cwd
-	cmd.Env = envVars
-	cmd.Stdout = maskWriter
-	cmd.Stderr = maskWriter
 
-	if startErr := cmd.Start(); startErr != nil {
-		m.logger.Errorf("failed to start command: %s, error: %v", commandType, startErr)
-		return startErr
+	session, err := newShellSession()
+	if err != nil {
+		return nil, err
 	}
-	if execErr := cmd.Wait(); execErr != nil {
-		m.logger.Errorf("command %s, exited with error: %v", commandType, execErr)
-		return execErr
+	defer session.close()
+
+	results = make([]*core.StepResult, 0, len(runConfig.Commands))
+	for i := range runConfig.Commands {
+		step := &runConfig.Commands[i]
+		result := &core.StepResult{Command: maskCommand(step.Run, secretData)}
+		results = append(results, result)
+		script, err := m.createScript([]string{step.Run}, secretData)
+		if err != nil {
+			return results, &core.StepError{Index: i, Err: err}
+		}
+		execErr := m.executeStep(ctx, commandType, step, session.wrap(script), envVars, cwd, multiWriter, secretData, result)
+		if execErr == nil {
+			execErr = session.commit()
+		}
+		if execErr != nil {
+			if step.ContinueOnError && ctx.Err() == nil {
+				logger.Warnf("step %d of command %s failed, continuing as continueOnError is set, error: %v",
+					i+1, commandType, execErr)
+				continue
+			}
+			logger.Errorf("command %s, exited with error: %v", commandType, execErr)
+			return results, &core.StepError{Index: i, Err: execErr}
+		}
 	}
 	azureWriter.Close()
 	if uploadErr := <-errChan; uploadErr != nil {
-		m.logger.Errorf("failed to upload logs for command %s, error: %v", commandType, uploadErr)
-		return uploadErr
+		logger.Errorf("failed to upload logs for command %s, error: %v", commandType, uploadErr)
+		return results, uploadErr
 	}
-	return nil
+	return results, nil
+}
+
+// executeStep runs the step script until it succeeds or its retries are exhausted.
+// Duration and exit code of every attempt are written to the step logs and the
+// outcome of the last attempt is recorded in result.
+func (m *manager) executeStep(ctx context.Context,
+	commandType core.CommandType,
+	step *core.Step,
+	script string,
+	envVars []string,
+	cwd string,
+	writer io.Writer,
+	secretData map[string]string,
+	result *core.StepResult) error {
+	logger := lumber.FromContext(ctx, m.logger)
+	tail := newTailWriter(outputTailSize)
+	stepWriter := logstream.NewMasker(io.MultiWriter(writer, tail), utils.WithInternalSecrets(secretData))
+	result.StartTime = time.Now()
+	defer func() {
+		stepWriter.Close()
+		result.EndTime = time.Now()
+		result.Output = tail.String()
+	}()
+
+	var err error
+	for attempt := 1; attempt <= step.Retries+1; attempt++ {
+		if attempt > 1 {
+			fmt.Fprintf(stepWriter, "retrying step, attempt %d of %d\n", attempt, step.Retries+1)
+		}
+		startTime := time.Now()
+		result.Attempts = attempt
+		result.ExitCode, err = m.runStep(ctx, step.Timeout, script, envVars, cwd, stepWriter)
+		duration := time.Since(startTime).Round(time.Millisecond)
+		logger.Debugf("step of command %s exited with code %d in %s", commandType, result.ExitCode, duration)
+		fmt.Fprintf(stepWriter, "step exited with code %d in %s\n", result.ExitCode, duration)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// runStep runs the script with the given timeout and returns its exit code.
+func (m *manager) runStep(ctx context.Context,
+	timeout time.Duration,
+	script string,
+	envVars []string,
+	cwd string,
+	writer io.Writer) (int, error) {
+	logger := lumber.FromContext(ctx, m.logger)
+	stepCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.Command("/bin/bash", "-c", script)
+	cmd.Dir = cwd
+	cmd.Env = envVars
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	// the step runs in its own process group, so that the processes it spawns are terminated along with it
+	wait, err := procutils.Start(stepCtx, cmd, logger)
+	if err != nil {
+		logger.Errorf("failed to start command, error: %v", err)
+		return -1, err
+	}
+	if err := wait(); err != nil {
+		if errors.Is(stepCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return -1, fmt.Errorf("step timed out after %s", timeout)
+		}
+		return exitCode(err), err
+	}
+	return 0, nil
 }
 
 // ExecuteInternalCommands executes internal commands
@@ -81,27 +187,39 @@ func (m *manager) ExecuteInternalCommands(ctx context.Context,
 	commandType core.CommandType,
 	commands []string,
 	cwd string,
-	envMap, secretData map[string]string) error {
+	envMap, secretData map[string]string) ([]*core.StepResult, error) {
+	ctx, logger := lumber.ContextWithFields(ctx, m.logger, lumber.Fields{lumber.FieldStage: commandType})
 	bashCommands := strings.Join(commands, " && ")
-	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", bashCommands)
+	cmd := exec.Command("/bin/bash", "-c", bashCommands)
 	if cwd != "" {
 		cmd.Dir = cwd
 	}
-	logWriter := lumber.NewWriter(m.logger)
+	logWriter := lumber.NewWriter(logger)
 	defer logWriter.Close()
-	cmd.Stderr = logWriter
-	cmd.Stdout = logWriter
-	m.logger.Debugf("Executing command of type %s", commandType)
-	if err := cmd.Run(); err != nil {
-		m.logger.Errorf("command of type %s failed with error: %v", commandType, err)
-		return err
+	tail := newTailWriter(outputTailSize)
+	writer := logstream.NewMasker(io.MultiWriter(logWriter, tail), utils.WithInternalSecrets(secretData))
+	cmd.Stderr = writer
+	cmd.Stdout = writer
+	logger.Debugf("Executing command of type %s", commandType)
+	result := &core.StepResult{Command: maskCommand(bashCommands, secretData), StartTime: time.Now(), Attempts: 1}
+	err := procutils.Run(ctx, cmd, logger)
+	writer.Close()
+	result.EndTime = time.Now()
+	result.ExitCode = exitCode(err)
+	result.Output = tail.String()
+	if err != nil {
+		logger.Errorf("command of type %s failed with error: %v", commandType, err)
+		return []*core.StepResult{result}, err
 	}
-	return nil
+	return []*core.StepResult{result}, nil
 }
 
-// GetEnvVariables gives set environment variable
+// GetEnvVariables gives the environment variables for user commands, which are the allowed
+// env variables of nucleus along with the ones in the env map.
 func (m *manager) GetEnvVariables(envMap, secretData map[string]string) ([]string, error) {
-	envVars := os.Environ()
+	m.mu.RLock()
+	envVars := getInheritedEnv(m.inheritEnv)
+	m.mu.RUnlock()
 	for k, v := range envMap {
 		val, err := m.secretParser.SubstituteSecret(v, secretData)
 		if err != nil {
@@ -112,9 +230,59 @@ func (m *manager) GetEnvVariables(envMap, secretData map[string]string) ([]strin
 	return envVars, nil
 }
 
+// SetInheritEnv sets the names of the nucleus env variables to be passed to user commands
+func (m *manager) SetInheritEnv(names []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inheritEnv = names
+}
+
 func (m *manager) closeAndWriteLog(azureWriter *io.PipeWriter, errChan <-chan error, commandType core.CommandType) {
 	azureWriter.Close()
 	if uploadErr := <-errChan; uploadErr != nil {
 		m.logger.Errorf("failed to upload logs for command %s, error: %v", commandType, uploadErr)
 	}
 }
+
+// exitCode returns the exit code of a command from the error returned on its completion.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// maskCommand masks the secrets present in command.
+func maskCommand(command string, secretData map[string]string) string {
+	var builder strings.Builder
+	masker := logstream.NewMasker(&builder, utils.WithInternalSecrets(secretData))
+	_, _ = masker.Write([]byte(command))
+	_ = masker.Close()
+	return builder.String()
+}
+
+// tailWriter retains the last size bytes written to it.
+type tailWriter struct {
+	size int
+	buf  []byte
+}
+
+func newTailWriter(size int) *tailWriter {
+	return &tailWriter{size: size}
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.size {
+		t.buf = append(t.buf[:0:0], t.buf[len(t.buf)-t.size:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailWriter) String() string {
+	return string(t.buf)
+}
@@ -1,14 +1,19 @@
 package command
 
 import (
-	"fmt"
+	"bytes"
+	"context"
+	"errors"
 	"os"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/LambdaTest/test-at-scale/mocks"
 	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/logwriter"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 	"github.com/LambdaTest/test-at-scale/pkg/secret"
 	"github.com/LambdaTest/test-at-scale/testutils"
@@ -60,12 +65,17 @@ func Test_manager_GetEnvVariables(t *testing.T) {
 
 	secretParser := secret.New(logger)
 	azureClient := new(mocks.AzureClient)
-	envVars := os.Environ()
+	t.Setenv("TOKEN", "neuron-token")
+	t.Setenv("AZURE_STORAGE_KEY", "storage-key")
+	t.Setenv("REPO_ROOT", "/home/nucleus/repo")
+	t.Setenv("NPM_CONFIG_REGISTRY", "https://registry.npmjs.org")
+	t.Setenv("LC_ALL", "C")
 
 	type fields struct {
 		logger       lumber.Logger
 		secretParser core.SecretParser
 		azureClient  core.AzureClient
+		inheritEnv   []string
 	}
 	type args struct {
 		envMap     map[string]string
@@ -88,7 +98,22 @@ func Test_manager_GetEnvVariables(t *testing.T) {
 				envMap:     map[string]string{"os": "linux", "arch": "amd64", "ver": "1.15"},
 				secretData: map[string]string{"key1": "abc", "key2": "xyz", "key3": "123"},
 			},
-			append(envVars, "arch=amd64 os=linux ver=1.15"),
+			[]string{"LC_ALL=C", "PATH=" + os.Getenv("PATH"), "REPO_ROOT=/home/nucleus/repo", "arch=amd64", "os=linux", "ver=1.15"},
+			false,
+		},
+		{"Test GetEnvVariables with inherited env",
+			fields{
+				logger:       logger,
+				secretParser: secretParser,
+				azureClient:  azureClient,
+				inheritEnv:   []string{"NPM_CONFIG_REGISTRY", "TOKEN", "AZURE_STORAGE_KEY"},
+			},
+			args{
+				envMap:     map[string]string{"secret": "${{ secrets.key1 }}"},
+				secretData: map[string]string{"key1": "abc"},
+			},
+			[]string{"LC_ALL=C", "NPM_CONFIG_REGISTRY=https://registry.npmjs.org", "PATH=" + os.Getenv("PATH"),
+				"REPO_ROOT=/home/nucleus/repo", "secret=abc"},
 			false,
 		},
 	}
@@ -100,18 +125,178 @@ func Test_manager_GetEnvVariables(t *testing.T) {
 				secretParser: tt.fields.secretParser,
 				azureClient:  tt.fields.azureClient,
 			}
+			m.SetInheritEnv(tt.fields.inheritEnv)
 			got, err := m.GetEnvVariables(tt.args.envMap, tt.args.secretData)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("manager.GetEnvVariables() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			sort.Strings(got)
-			sort.Strings(tt.want)
-			received := fmt.Sprintf("%v", got)
-			want := fmt.Sprintf("%v", tt.want)
-			if len(received) != len(want) || received != want {
-				t.Errorf("manager.GetEnvVariables() = \n%v, \nwant \n%v", got, tt.want)
+			// only the env variables set by the test are compared, as the container env differs
+			received := []string{}
+			for _, env := range got {
+				name := strings.SplitN(env, "=", 2)[0]
+				if name == "PATH" || name == "REPO_ROOT" || name == "NPM_CONFIG_REGISTRY" || name == "LC_ALL" ||
+					name == "TOKEN" || name == "AZURE_STORAGE_KEY" || tt.args.envMap[name] != "" {
+					received = append(received, env)
+				}
+			}
+			sort.Strings(received)
+			if !reflect.DeepEqual(received, tt.want) {
+				t.Errorf("manager.GetEnvVariables() = \n%v, \nwant \n%v", received, tt.want)
+			}
+		})
+	}
+}
+
+func Test_manager_ExecuteUserCommands(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	m := &manager{
+		logger:       logger,
+		secretParser: secret.New(logger),
+		azureClient:  new(mocks.AzureClient),
+	}
+	tests := []struct {
+		name          string
+		steps         []core.Step
+		wantErr       bool
+		wantLogs      []string
+		wantExitCodes []int
+	}{
+		{
+			"Test steps run in order",
+			[]core.Step{{Run: "echo first"}, {Run: "echo second"}},
+			false,
+			[]string{"first", "second", "step exited with code 0"},
+			[]int{0, 0},
+		},
+		{
+			"Test failing step is retried",
+			[]core.Step{{Run: "test -f marker || (touch marker && exit 1)", Retries: 2}},
+			false,
+			[]string{"step exited with code 1", "retrying step, attempt 2 of 3", "step exited with code 0"},
+			[]int{0},
+		},
+		{
+			"Test failing step stops execution",
+			[]core.Step{{Run: "exit 3", Retries: 1}, {Run: "echo unreachable"}},
+			true,
+			[]string{"step exited with code 3", "retrying step, attempt 2 of 2"},
+			[]int{3},
+		},
+		{
+			"Test failing step with continueOnError",
+			[]core.Step{{Run: "exit 3", ContinueOnError: true}, {Run: "echo reachable"}},
+			false,
+			[]string{"step exited with code 3", "reachable"},
+			[]int{3, 0},
+		},
+		{
+			"Test shell state carries over to the next step",
+			[]core.Step{
+				{Run: `mkdir sub && cd sub && export GREETING=hello && greet() { echo "$GREETING from $(basename $PWD)"; }`},
+				{Run: "greet"},
+			},
+			false,
+			[]string{"hello from sub"},
+			[]int{0, 0},
+		},
+		{
+			"Test shell state of failed step is discarded",
+			[]core.Step{
+				{Run: "export GREETING=hello && exit 1", ContinueOnError: true},
+				{Run: `echo "greeting=${GREETING:-none}"`},
+			},
+			false,
+			[]string{"step exited with code 1", "greeting=none"},
+			[]int{1, 0},
+		},
+		{
+			"Test step timeout",
+			[]core.Step{{Run: "sleep 5", Timeout: 100 * time.Millisecond}},
+			true,
+			[]string{"step exited with code -1"},
+			[]int{-1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			cwd := t.TempDir()
+			ctx := core.ContextWithSteps(context.TODO())
+			results, err := m.ExecuteUserCommands(ctx, core.PreRun, &core.Payload{}, &core.Run{Commands: tt.steps},
+				map[string]string{}, logwriter.NewBufferLogWriter("", buffer, logger), cwd)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("manager.ExecuteUserCommands() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var stepErr *core.StepError
+			if err != nil && (!errors.As(err, &stepErr) || stepErr.Index != len(results)-1) {
+				t.Errorf("manager.ExecuteUserCommands() error = %v, want the error of the last step run", err)
+			}
+			if recorded := core.StepsFromContext(ctx); !reflect.DeepEqual(recorded, results) {
+				t.Errorf("manager.ExecuteUserCommands() recorded steps %v, want %v", recorded, results)
+			}
+			logs := buffer.String()
+			for _, want := range tt.wantLogs {
+				if !strings.Contains(logs, want) {
+					t.Errorf("manager.ExecuteUserCommands() logs = %q, want to contain %q", logs, want)
+				}
+			}
+			if len(results) != len(tt.wantExitCodes) {
+				t.Fatalf("manager.ExecuteUserCommands() returned %d results, want %d", len(results), len(tt.wantExitCodes))
+			}
+			for i, result := range results {
+				if result.Command != tt.steps[i].Run || result.ExitCode != tt.wantExitCodes[i] {
+					t.Errorf("manager.ExecuteUserCommands() result %d = %+v, want command %q and exit code %d",
+						i, result, tt.steps[i].Run, tt.wantExitCodes[i])
+				}
+				if result.EndTime.Before(result.StartTime) || result.Output == "" {
+					t.Errorf("manager.ExecuteUserCommands() result %d has invalid times or empty output: %+v", i, result)
+				}
+			}
+			if strings.Contains(logs, "+ echo unreachable") {
+				t.Errorf("manager.ExecuteUserCommands() ran a step after a failed step, logs = %q", logs)
 			}
 		})
 	}
 }
+
+func Test_manager_ExecuteInternalCommands(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	m := &manager{logger: logger, secretParser: secret.New(logger)}
+	secretData := map[string]string{"token": "s3cr3t"}
+
+	results, err := m.ExecuteInternalCommands(context.TODO(), core.InstallRunners,
+		[]string{"echo s3cr3t", "exit 2"}, "", nil, secretData)
+	if err == nil {
+		t.Errorf("manager.ExecuteInternalCommands() expected error, got nil")
+	}
+	if len(results) != 1 {
+		t.Fatalf("manager.ExecuteInternalCommands() returned %d results, want 1", len(results))
+	}
+	result := results[0]
+	if result.ExitCode != 2 {
+		t.Errorf("manager.ExecuteInternalCommands() exit code = %d, want 2", result.ExitCode)
+	}
+	if strings.Contains(result.Command, "s3cr3t") || strings.Contains(result.Output, "s3cr3t") {
+		t.Errorf("manager.ExecuteInternalCommands() result has unmasked secret: %+v", result)
+	}
+}
+
+func Test_tailWriter(t *testing.T) {
+	tail := newTailWriter(5)
+	for _, chunk := range []string{"abc", "defg", "h"} {
+		if _, err := tail.Write([]byte(chunk)); err != nil {
+			t.Errorf("tailWriter.Write() error = %v", err)
+		}
+	}
+	if got := tail.String(); got != "defgh" {
+		t.Errorf("tailWriter.String() = %q, want %q", got, "defgh")
+	}
+}
@@ -3,6 +3,8 @@ package command
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -17,11 +19,10 @@ func (m *manager) createScript(commands []string, secretData map[string]string)
 	for _, command := range commands {
 		escaped := fmt.Sprintf("%q", command)
 		escaped = strings.Replace(escaped, "$", `\$`, -1)
-		if len(secretData) > 0 {
-			command, err = m.secretParser.SubstituteSecret(command, secretData)
-			if err != nil {
-				return "", err
-			}
+		// substitute even without secrets, to fail on the ones withheld by the secret policy
+		command, err = m.secretParser.SubstituteSecret(command, secretData)
+		if err != nil {
+			return "", err
 		}
 		buf.WriteString(fmt.Sprintf(
 			traceScript,
@@ -44,3 +45,53 @@ const traceScript = `
 echo + %s
 %s
 `
+
+// sessionScript restores the state of the shell saved by the previous step and saves the state of the step on
+// exit, so that the working directory, the exported variables and the functions carry over from one step to the
+// next as in a single shell session.
+const sessionScript = `
+if [ -f '%[1]s' ]; then
+	source '%[1]s' 2>/dev/null || true
+fi
+trap '{ export -p; declare -f; echo "cd $(printf %%q "$PWD")"; } > '"'%[2]s'" EXIT
+`
+
+// shellSession carries the state of the shell over the steps of a stage, each of which runs in its own shell
+type shellSession struct {
+	dir string
+}
+
+func newShellSession() (*shellSession, error) {
+	dir, err := os.MkdirTemp("", "shell-session-")
+	if err != nil {
+		return nil, err
+	}
+	return &shellSession{dir: dir}, nil
+}
+
+// wrap returns the script of a step run in the session
+func (s *shellSession) wrap(script string) string {
+	return fmt.Sprintf(sessionScript, s.statePath(), s.nextStatePath()) + script
+}
+
+// commit makes the state saved by the last step the state of the next step, it is called once the step
+// succeeds so that a retried or failed step starts from and leaves behind the state before it
+func (s *shellSession) commit() error {
+	if err := os.Rename(s.nextStatePath(), s.statePath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// close removes the saved state of the session
+func (s *shellSession) close() {
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *shellSession) statePath() string {
+	return filepath.Join(s.dir, "state.sh")
+}
+
+func (s *shellSession) nextStatePath() string {
+	return filepath.Join(s.dir, "next-state.sh")
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type gracePeriodKey struct{}
+
+// detachedContext carries the values of its parent without its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+func (d detachedContext) Value(key interface{}) interface{}     { return d.parent.Value(key) }
+
+// gracePeriod is the grace period of a task, which starts once when the task is canceled
+type gracePeriod struct {
+	period   time.Duration
+	task     context.Context
+	once     sync.Once
+	deadline time.Time
+}
+
+// end returns the end of the grace period, set on the first call
+func (g *gracePeriod) end() time.Time {
+	g.once.Do(func() { g.deadline = time.Now().Add(g.period) })
+	return g.deadline
+}
+
+// ContextWithGracePeriod returns a copy of ctx in which the work of an aborted task, e.g. flushing the results
+// of the runner and uploading the logs, may continue for period after ctx is canceled
+func ContextWithGracePeriod(ctx context.Context, period time.Duration) context.Context {
+	g := &gracePeriod{period: period, task: ctx}
+	// the deadline is recorded on cancellation, so that the work noticing it later gets no extra time
+	go func() {
+		<-ctx.Done()
+		g.end()
+	}()
+	return context.WithValue(ctx, gracePeriodKey{}, g)
+}
+
+// GraceContext returns a context carrying the values of ctx, which is canceled once the grace period of the task
+// has passed after ctx is canceled. All the work of the task shares the one deadline of its grace period, while
+// the work canceled on its own is given the whole grace period. Without a grace period it is canceled along
+// with ctx. The returned cancel function must be called once the work done within the grace period completes.
+func GraceContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := detachedContext{parent: ctx}
+	if ctx.Err() != nil {
+		return context.WithDeadline(detached, graceDeadline(ctx))
+	}
+	graceCtx, cancel := context.WithCancel(detached)
+	go func() {
+		select {
+		case <-graceCtx.Done():
+			return
+		case <-ctx.Done():
+		}
+		deadlineCtx, deadlineCancel := context.WithDeadline(graceCtx, graceDeadline(ctx))
+		defer deadlineCancel()
+		<-deadlineCtx.Done()
+		cancel()
+	}()
+	return graceCtx, cancel
+}
+
+// graceDeadline returns the end of the grace period of the canceled ctx
+func graceDeadline(ctx context.Context) time.Time {
+	g, ok := ctx.Value(gracePeriodKey{}).(*gracePeriod)
+	if !ok {
+		return time.Now()
+	}
+	if g.task.Err() != nil {
+		return g.end()
+	}
+	return time.Now().Add(g.period)
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestGraceContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		withGrace   bool
+	}{
+		{"without grace period", 0, false},
+		{"with grace period", 50 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+			if tt.withGrace {
+				ctx = ContextWithGracePeriod(ctx, tt.gracePeriod)
+			}
+			ctx, cancel := context.WithCancel(ctx)
+			graceCtx, graceCancel := GraceContext(ctx)
+			defer graceCancel()
+			if got := graceCtx.Value(ctxKey{}); got != "value" {
+				t.Errorf("GraceContext() value = %v, want the values of the parent", got)
+			}
+
+			canceledAt := time.Now()
+			cancel()
+			select {
+			case <-graceCtx.Done():
+			case <-time.After(time.Second):
+				t.Fatalf("GraceContext() not canceled after the grace period")
+			}
+			if elapsed := time.Since(canceledAt); elapsed < tt.gracePeriod {
+				t.Errorf("GraceContext() canceled after %s, want at least %s", elapsed, tt.gracePeriod)
+			}
+		})
+	}
+}
+
+func TestGraceContextSharesDeadline(t *testing.T) {
+	gracePeriod := 100 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = ContextWithGracePeriod(ctx, gracePeriod)
+	first, firstCancel := GraceContext(ctx)
+	defer firstCancel()
+
+	canceledAt := time.Now()
+	cancel()
+	<-time.After(gracePeriod / 2)
+	// the work started after the cancellation gets what is left of the grace period
+	second, secondCancel := GraceContext(ctx)
+	defer secondCancel()
+	deadline, ok := second.Deadline()
+	if !ok || deadline.Sub(canceledAt) > gracePeriod+10*time.Millisecond {
+		t.Errorf("GraceContext() deadline = %s after cancellation, want the grace period %s", deadline.Sub(canceledAt), gracePeriod)
+	}
+	for _, graceCtx := range []context.Context{first, second} {
+		select {
+		case <-graceCtx.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("GraceContext() not canceled after the grace period")
+		}
+	}
+	if elapsed := time.Since(canceledAt); elapsed > gracePeriod+50*time.Millisecond {
+		t.Errorf("GraceContext() canceled after %s, want the end of the grace period %s", elapsed, gracePeriod)
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// heartbeat reports the progress of a running task to neuron at regular intervals, so that a hung
+// task can be told apart from a long one. The task is aborted when the heartbeat can not be delivered
+// maxMissed times in a row.
+type heartbeat struct {
+	task      Task
+	payload   TaskPayload
+	interval  time.Duration
+	maxMissed int
+	abort     context.CancelFunc
+	logger    lumber.Logger
+	aborted   int32
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// startHeartbeat starts reporting the progress of ctx along with a copy of payload, a zero
+// interval disables the heartbeat.
+func startHeartbeat(ctx context.Context,
+	task Task,
+	payload *TaskPayload,
+	interval time.Duration,
+	maxMissed int,
+	abort context.CancelFunc,
+	logger lumber.Logger) *heartbeat {
+	h := &heartbeat{
+		task:      task,
+		payload:   *payload,
+		interval:  interval,
+		maxMissed: maxMissed,
+		abort:     abort,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if interval <= 0 {
+		close(h.done)
+		return h
+	}
+	go h.run(ctx)
+	return h
+}
+
+func (h *heartbeat) run(ctx context.Context) {
+	defer close(h.done)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	missed := 0
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		beat := h.payload
+		progress := ProgressFromContext(ctx)
+		beat.Progress = &progress
+		// a beat is not retried past the next one
+		beatCtx, cancel := context.WithTimeout(ctx, h.interval)
+		err := h.task.UpdateStatus(beatCtx, &beat)
+		cancel()
+		if err == nil {
+			missed = 0
+			continue
+		}
+		missed++
+		h.logger.Errorf("failed to send heartbeat %d of %d, error: %v", missed, h.maxMissed, err)
+		if missed >= h.maxMissed {
+			h.logger.Errorf("aborting task %s as its heartbeat could not be delivered", h.payload.TaskID)
+			atomic.StoreInt32(&h.aborted, 1)
+			h.abort()
+			return
+		}
+	}
+}
+
+// Stop stops the heartbeat and waits for the beat being sent, so that no beat follows the final status
+func (h *heartbeat) Stop() {
+	select {
+	case <-h.done:
+	default:
+		close(h.stop)
+		<-h.done
+	}
+}
+
+// Aborted returns whether the task was aborted by the heartbeat
+func (h *heartbeat) Aborted() bool {
+	return atomic.LoadInt32(&h.aborted) == 1
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// fakeTask records the heartbeats, testutils imports core so the mocks can not be used here
+type fakeTask struct {
+	mu    sync.Mutex
+	err   error
+	beats []TaskPayload
+}
+
+func (f *fakeTask) UpdateStatus(ctx context.Context, payload *TaskPayload) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.beats = append(f.beats, *payload)
+	return f.err
+}
+
+func (f *fakeTask) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.beats)
+}
+
+func newTestLogger(t *testing.T) lumber.Logger {
+	logger, err := lumber.NewLogger(lumber.LoggingConfig{ConsoleLevel: lumber.Debug}, true, lumber.InstanceZapLogger)
+	if err != nil {
+		t.Fatalf("failed to create logger, error: %v", err)
+	}
+	return logger
+}
+
+func TestHeartbeat(t *testing.T) {
+	logger := newTestLogger(t)
+	ctx := ContextWithProgress(context.Background())
+	SetProgressStage(ctx, "discovery")
+	for _, subModule := range []string{"web", "api"} {
+		subModuleCtx, _ := lumber.ContextWithFields(ctx, logger, lumber.Fields{lumber.FieldSubModule: subModule})
+		SetProgressStage(subModuleCtx, "execution")
+	}
+	webCtx, _ := lumber.ContextWithFields(ctx, logger, lumber.Fields{lumber.FieldSubModule: "web"})
+	SetProgressStage(webCtx, "send_results")
+	AddTestsCompleted(ctx, 3)
+	AddTestsCompleted(ctx, 2)
+
+	task := &fakeTask{}
+	aborted := false
+	h := startHeartbeat(ctx, task, &TaskPayload{TaskID: "task", Status: Running},
+		10*time.Millisecond, 2, func() { aborted = true }, logger)
+	for task.count() < 2 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.Stop()
+
+	if h.Aborted() || aborted {
+		t.Errorf("heartbeat aborted the task, want the task to run while the heartbeats are delivered")
+	}
+	want := TaskProgress{
+		Stage:          "discovery",
+		SubModules:     []SubModuleProgress{{Name: "api", Stage: "execution"}, {Name: "web", Stage: "send_results"}},
+		TestsCompleted: 5,
+	}
+	beat := task.beats[0]
+	if beat.TaskID != "task" || beat.Status != Running {
+		t.Errorf("heartbeat payload = %+v, want the payload of the task", beat)
+	}
+	if beat.Progress == nil || !reflect.DeepEqual(*beat.Progress, want) {
+		t.Errorf("heartbeat progress = %+v, want %+v", beat.Progress, want)
+	}
+	count := task.count()
+	time.Sleep(30 * time.Millisecond)
+	if got := task.count(); got != count {
+		t.Errorf("heartbeats sent after Stop() = %d, want 0", got-count)
+	}
+}
+
+func TestHeartbeatAbort(t *testing.T) {
+	logger := newTestLogger(t)
+	ctx, cancel := context.WithCancel(ContextWithProgress(context.Background()))
+	defer cancel()
+
+	task := &fakeTask{err: errors.New("connection refused")}
+	h := startHeartbeat(ctx, task, &TaskPayload{TaskID: "task"}, 10*time.Millisecond, 3, cancel, logger)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("task was not aborted after the heartbeats failed")
+	}
+	h.Stop()
+
+	if !h.Aborted() {
+		t.Errorf("Aborted() = false, want true")
+	}
+	if got := task.count(); got != 3 {
+		t.Errorf("heartbeats sent = %d, want 3", got)
+	}
+}
+
+func TestHeartbeatDisabled(t *testing.T) {
+	task := &fakeTask{}
+	h := startHeartbeat(context.Background(), task, &TaskPayload{}, 0, 3, func() {}, newTestLogger(t))
+	time.Sleep(20 * time.Millisecond)
+	h.Stop()
+	if got := task.count(); got != 0 {
+		t.Errorf("heartbeats sent = %d, want 0 when the heartbeat is disabled", got)
+	}
+}
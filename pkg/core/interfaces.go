@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"io"
+
+	"github.com/LambdaTest/test-at-scale/config"
 )
 
 // PayloadManager defines operations for payload
@@ -68,7 +70,7 @@ type CoverageService interface {
 
 // TestStats is used for servicing stat collection
 type TestStats interface {
-	CaptureTestStats(pid int32, collectStats bool) error
+	CaptureTestStats(ctx context.Context, pid int32, collectStats bool) error
 }
 
 // Task is a service to update task status at neuron
@@ -101,6 +103,13 @@ type ZstdCompressor interface {
 	Decompress(ctx context.Context, filePath string, preservePath bool, workingDirectory string) error
 }
 
+// ArtifactManager collects and uploads the artifacts generated by a build
+type ArtifactManager interface {
+	// Upload compresses the files matching the glob patterns relative to workingDir and uploads them
+	// as artifact name. It returns the URL of the uploaded artifact, or an empty URL when no file matches.
+	Upload(ctx context.Context, name, workingDir string, patterns ...string) (string, error)
+}
+
 // CacheStore defines operation for working with the cache
 //go:generate mockery  --name  CacheStore  --keeptree  --output  ../mocks/CacheStore.go
 type CacheStore interface {
@@ -108,8 +117,9 @@ type CacheStore interface {
 	Download(ctx context.Context, cacheKey string) error
 	// Upload creates, compresses and uploads cache at cacheKey
 	Upload(ctx context.Context, cacheKey string, itemsToCompress ...string) error
-	// CacheWorkspace caches the workspace onto a mounted volume
-	CacheWorkspace(ctx context.Context, subModule string) error
+	// CacheWorkspace caches the workspace onto a mounted volume.
+	// For a subModule, excludePaths relative to repo root are left out of the cache.
+	CacheWorkspace(ctx context.Context, subModule string, excludePaths ...string) error
 	// ExtractWorkspace extracts the workspace cache from mounted volume
 	ExtractWorkspace(ctx context.Context, subModule string) error
 }
@@ -120,6 +130,11 @@ type SecretParser interface {
 	GetOauthSecret(filepath string) (*Oauth, error)
 	// GetRepoSecret parses the repo secret for given path
 	GetRepoSecret(string) (map[string]string, error)
+	// GetSecretPolicy parses the policy of repo secrets for given path
+	GetSecretPolicy(path string) (*config.SecretPolicy, error)
+	// ApplySecretPolicy returns the secrets made available to the build by all the policies,
+	// SubstituteSecret fails for the secrets withheld.
+	ApplySecretPolicy(secretData map[string]string, payload *Payload, policies ...*config.SecretPolicy) map[string]string
 	// SubstituteSecret replace secret placeholders with their respective values
 	SubstituteSecret(command string, secretData map[string]string) (string, error)
 	// Expired reports whether the token is expired.
@@ -128,23 +143,27 @@ type SecretParser interface {
 
 // ExecutionManager has responsibility for executing the preRun, postRun and internal commands
 type ExecutionManager interface {
-	// ExecuteUserCommands executes the preRun or postRun commands given by user in his yaml.
+	// ExecuteUserCommands executes the preRun or postRun commands given by user in his yaml
+	// and returns the result of every step run.
 	ExecuteUserCommands(ctx context.Context,
 		commandType CommandType,
 		payload *Payload,
 		runConfig *Run,
 		secretData map[string]string,
 		logwriter LogWriterStrategy,
-		cwd string) error
+		cwd string) ([]*StepResult, error)
 
 	// ExecuteInternalCommands executes the commands like installing runners and test discovery.
 	ExecuteInternalCommands(ctx context.Context,
 		commandType CommandType,
 		commands []string,
 		cwd string, envMap,
-		secretData map[string]string) error
+		secretData map[string]string) ([]*StepResult, error)
 	// GetEnvVariables get the environment variables from the env map given by user.
 	GetEnvVariables(envMap, secretData map[string]string) ([]string, error)
+	// SetInheritEnv sets the names of the nucleus env variables to be passed to user commands
+	// in addition to the allowed ones.
+	SetInheritEnv(names []string)
 }
 
 // Requests is a util interface for making API Requests
@@ -176,6 +195,16 @@ type LogWriterStrategy interface {
 	Write(ctx context.Context, reader io.Reader) <-chan error
 }
 
+// LogWriterFactory creates the log writers selected in the configuration
+type LogWriterFactory interface {
+	// NewLogWriter returns the log writer of the logs of purpose
+	NewLogWriter(purpose SASURLPurpose, payload *Payload) LogWriterStrategy
+	// NewStoreLogWriter returns the log writer storing the logs of purpose, without streaming them live
+	NewStoreLogWriter(purpose SASURLPurpose, payload *Payload) LogWriterStrategy
+	// WithLogStream returns writer along with the live stream of the logs of purpose, if enabled
+	WithLogStream(writer LogWriterStrategy, purpose SASURLPurpose, payload *Payload) LogWriterStrategy
+}
+
 // Builder builds the driver for given tas yml version
 type Builder interface {
 	// GetDriver returns driver for use
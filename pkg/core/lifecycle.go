@@ -10,16 +10,22 @@ import (
 	"time"
 
 	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/cgroup"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/fileutils"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
 )
 
 const (
 	endpointPostTestResults = "http://localhost:9876/results"
 	endpointPostTestList    = "http://localhost:9876/test-list"
 	languageJs              = "javascript"
+	stageClone              = "clone"
+	stageExtractWorkspace   = "extractworkspace"
+	metricsSnapshotFile     = "metrics.prom"
 )
 
 // NewPipeline creates and returns a new Pipeline instance
@@ -34,13 +40,19 @@ func NewPipeline(cfg *config.NucleusConfig, logger lumber.Logger) (*Pipeline, er
 func (pl *Pipeline) Start(ctx context.Context) (err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	ctx = ContextWithProgress(ctx)
+	ctx = ContextWithSteps(ctx)
+	ctx = ContextWithGracePeriod(ctx, time.Duration(pl.Cfg.GracePeriod)*time.Second)
 	startTime := time.Now()
+	ctx, taskSpan := tracing.Start(ctx, tracing.SpanTask)
 
 	pl.Logger.Debugf("Starting pipeline.....")
 	pl.Logger.Debugf("Fetching config")
 
 	// fetch configuration
-	payload, err := pl.PayloadManager.FetchPayload(ctx, pl.Cfg.PayloadAddress)
+	fetchCtx, fetchSpan := tracing.Start(ctx, tracing.SpanFetchPayload)
+	payload, err := pl.PayloadManager.FetchPayload(fetchCtx, pl.Cfg.PayloadAddress)
+	tracing.End(fetchSpan, err)
 	if err != nil {
 		pl.Logger.Fatalf("error while fetching payload: %v", err)
 	}
@@ -50,11 +62,17 @@ func (pl *Pipeline) Start(ctx context.Context) (err error) {
 		pl.Logger.Fatalf("error while validating payload %v", err)
 	}
 
-	pl.Logger.Debugf("Payload for current task: %+v \n", *payload)
+	ctx, logger := lumber.ContextWithFields(ctx, pl.Logger, lumber.Fields{
+		lumber.FieldOrgID:   payload.OrgID,
+		lumber.FieldBuildID: payload.BuildID,
+		lumber.FieldTaskID:  payload.TaskID,
+	})
+	taskSpan.SetAttributes(tracing.Attributes(lumber.FieldsFromContext(ctx))...)
+	logger.Debugf("Payload for current task: %+v \n", *payload)
 
 	if pl.Cfg.CoverageMode {
 		if err = pl.CoverageService.MergeAndUpload(ctx, payload); err != nil {
-			pl.Logger.Fatalf("error while merge and upload coverage files %v", err)
+			logger.Fatalf("error while merge and upload coverage files %v", err)
 		}
 		os.Exit(0)
 	}
@@ -63,21 +81,32 @@ func (pl *Pipeline) Start(ctx context.Context) (err error) {
 	pl.Payload = payload
 
 	taskPayload := pl.getTaskPayload(payload, startTime)
+	taskPayload.TraceID = tracing.TraceID(ctx)
 	payload.TaskType = taskPayload.Type
-	pl.Logger.Infof("Running nucleus in %s mode", taskPayload.Type)
+	logger.Infof("Running nucleus in %s mode", taskPayload.Type)
+	ctx, group := pl.setupCgroup(ctx, payload.LicenseTier, logger)
 
 	go func() {
 		// marking task to running state
 		if err = pl.Task.UpdateStatus(context.Background(), taskPayload); err != nil {
-			pl.Logger.Fatalf("failed to update task status %v", err)
+			logger.Fatalf("failed to update task status %v", err)
 		}
 	}()
 
+	heartbeatTask := pl.Heartbeat
+	if heartbeatTask == nil {
+		heartbeatTask = pl.Task
+	}
+	heartbeat := startHeartbeat(ctx, heartbeatTask, taskPayload,
+		time.Duration(pl.Cfg.HeartbeatInterval)*time.Second, pl.Cfg.MaxMissedHeartbeats, cancel, logger)
+
 	// update task status when pipeline exits
 	defer func() {
+		heartbeat.Stop()
 		taskPayload.EndTime = time.Now()
+		taskPayload.Steps = StepsFromContext(ctx)
 		if p := recover(); p != nil {
-			pl.Logger.Errorf("panic stack trace: %v\n%s", p, string(debug.Stack()))
+			logger.Errorf("panic stack trace: %v\n%s", p, string(debug.Stack()))
 			taskPayload.Status = Error
 			taskPayload.Remark = errs.GenericErrRemark.Error()
 		} else if err != nil {
@@ -85,7 +114,10 @@ func (pl *Pipeline) Start(ctx context.Context) (err error) {
 				taskPayload.Status = Aborted
 				taskPayload.Remark = "Task aborted"
 			} else {
-				if _, ok := err.(*errs.StatusFailed); ok {
+				var stepsFailed *StepsFailed
+				if errors.As(err, &stepsFailed) {
+					taskPayload.Status = Failed
+				} else if _, ok := err.(*errs.StatusFailed); ok {
 					taskPayload.Status = Failed
 				} else {
 					taskPayload.Status = Error
@@ -93,37 +125,82 @@ func (pl *Pipeline) Start(ctx context.Context) (err error) {
 				taskPayload.Remark = err.Error()
 			}
 		}
+		// an aborted task is reported as such, its commands may have been killed on the way down
+		if remark := oomRemark(group, logger); remark != nil && taskPayload.Status != Passed && taskPayload.Status != Aborted {
+			taskPayload.Status = Failed
+			taskPayload.Remark = remark.Error()
+		}
+		if heartbeat.Aborted() {
+			taskPayload.Status = Aborted
+			taskPayload.Remark = errs.ErrHeartbeatFailed.Error()
+		}
+		// the container exits with the task, so the metrics are left behind in the logs directory
+		tracing.End(taskSpan, err)
+		if snapshotErr := metrics.WriteSnapshot(filepath.Join(pl.Cfg.LogWriter.Dir, metricsSnapshotFile)); snapshotErr != nil {
+			logger.Errorf("failed to write metrics snapshot, error: %v", snapshotErr)
+		}
+		if heartbeat.Aborted() {
+			// neuron could not be reached, so the final status is sent once like a heartbeat instead of being retried
+			statusCtx, statusCancel := context.WithTimeout(context.Background(),
+				time.Duration(pl.Cfg.HeartbeatInterval)*time.Second)
+			defer statusCancel()
+			if err = heartbeatTask.UpdateStatus(statusCtx, taskPayload); err != nil {
+				logger.Errorf("failed to update status of task aborted by heartbeat %v", err)
+			}
+			return
+		}
 		if err = pl.Task.UpdateStatus(context.Background(), taskPayload); err != nil {
-			pl.Logger.Fatalf("failed to update task status %v", err)
+			logger.Fatalf("failed to update task status %v", err)
 		}
 	}()
 
 	oauth, err := pl.SecretParser.GetOauthSecret(global.OauthSecretPath)
 	if err != nil {
-		pl.Logger.Errorf("failed to get oauth secret %v", err)
+		logger.Errorf("failed to get oauth secret %v", err)
 		return err
 	}
 	// read secrets
 	secretMap, err := pl.SecretParser.GetRepoSecret(global.RepoSecretPath)
 	if err != nil {
-		pl.Logger.Errorf("Error in fetching Repo secrets %v", err)
+		logger.Errorf("Error in fetching Repo secrets %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
+	// the secrets are not left on disk for the user commands to read
+	if removeErr := os.Remove(global.RepoSecretPath); removeErr != nil && !os.IsNotExist(removeErr) {
+		logger.Errorf("failed to remove repo secrets file, error: %v", removeErr)
+	}
+	secretPolicy, err := pl.SecretParser.GetSecretPolicy(global.SecretPolicyPath)
+	if err != nil {
+		logger.Errorf("Error in fetching secret policy %v", err)
+		err = errs.New(errs.GenericErrRemark.Error())
+		return err
+	}
+	secretMap = pl.SecretParser.ApplySecretPolicy(secretMap, payload, secretPolicy)
 	if pl.Cfg.DiscoverMode {
-		pl.Logger.Infof("Cloning repo ...")
-		err = pl.GitManager.Clone(ctx, pl.Payload, oauth)
+		logger.Infof("Cloning repo ...")
+		cloneCtx, _ := lumber.ContextWithFields(ctx, pl.Logger, lumber.Fields{lumber.FieldStage: stageClone})
+		cloneCtx, cloneSpan := tracing.Start(cloneCtx, tracing.SpanClone)
+		SetProgressStage(cloneCtx, tracing.SpanClone)
+		cloneStart := time.Now()
+		err = pl.GitManager.Clone(cloneCtx, pl.Payload, oauth)
+		metrics.ObserveStage(metrics.StageClone, cloneStart)
+		tracing.End(cloneSpan, err)
 		if err != nil {
-			pl.Logger.Errorf("Unable to clone repo '%s': %s", payload.RepoLink, err)
+			logger.Errorf("Unable to clone repo '%s': %s", payload.RepoLink, err)
 			err = &errs.StatusFailed{Remark: fmt.Sprintf("Unable to clone repo: %s", payload.RepoLink)}
 			return err
 		}
 	} else {
-		pl.Logger.Debugf("Extracting workspace")
+		logger.Debugf("Extracting workspace")
 		// Replicate workspace
-		// TODO this will be changed after parallel discovery support
-		if err = pl.CacheStore.ExtractWorkspace(ctx, ""); err != nil {
-			pl.Logger.Errorf("Error replicating workspace: %+v", err)
+		extractCtx, _ := lumber.ContextWithFields(ctx, pl.Logger, lumber.Fields{lumber.FieldStage: stageExtractWorkspace})
+		extractCtx, extractSpan := tracing.Start(extractCtx, tracing.SpanExtractWorkspace)
+		SetProgressStage(extractCtx, tracing.SpanExtractWorkspace)
+		err = pl.CacheStore.ExtractWorkspace(extractCtx, pl.Cfg.SubModule)
+		tracing.End(extractSpan, err)
+		if err != nil {
+			logger.Errorf("Error replicating workspace: %+v", err)
 			err = errs.New(errs.GenericErrRemark.Error())
 			return err
 		}
@@ -131,7 +208,7 @@ func (pl *Pipeline) Start(ctx context.Context) (err error) {
 	coverageDir := filepath.Join(global.CodeCoverageDir, payload.OrgID, payload.RepoID, payload.BuildTargetCommit)
 	if payload.CollectCoverage {
 		if err = fileutils.CreateIfNotExists(coverageDir, true); err != nil {
-			pl.Logger.Errorf("failed to create coverage directory %v", err)
+			logger.Errorf("failed to create coverage directory %v", err)
 			err = errs.New(errs.GenericErrRemark.Error())
 			return err
 		}
@@ -142,15 +219,15 @@ func (pl *Pipeline) Start(ctx context.Context) (err error) {
 	}
 	version, err := pl.TASConfigManager.GetVersion(filePath)
 	if err != nil {
-		pl.Logger.Errorf("Unable to load tas yaml file, error: %v", err)
+		logger.Errorf("Unable to load tas yaml file, error: %v", err)
 		err = &errs.StatusFailed{Remark: err.Error()}
 		return err
 	}
-	pl.Logger.Infof("TAS Version %f", version)
+	logger.Infof("TAS Version %f", version)
 	pl.setEnv(payload, coverageDir)
 	newDriver, err := pl.Builder.GetDriver(version, filePath)
 	if err != nil {
-		pl.Logger.Errorf("error crearing driver, error %v", err)
+		logger.Errorf("error crearing driver, error %v", err)
 		return err
 	}
 	if pl.Cfg.DiscoverMode {
@@ -184,6 +261,54 @@ func (pl *Pipeline) getTaskPayload(payload *Payload, startTime time.Time) *TaskP
 	return taskPayload
 }
 
+// setupCgroup returns a copy of ctx in which the commands are run in a cgroup limited to the specs of tier
+// minus the reserve of nucleus. The commands run without limits when cgroup v2 is not available.
+func (pl *Pipeline) setupCgroup(ctx context.Context, tier Tier, logger lumber.Logger) (context.Context, *cgroup.Group) {
+	if !pl.Cfg.Cgroup.Enabled {
+		return ctx, nil
+	}
+	specs, ok := TierOpts[tier]
+	if !ok {
+		specs = TierOpts[Small]
+	}
+	limits := cgroup.Limits{
+		MilliCPU: int64(specs.CPU*1000) - int64(pl.Cfg.Cgroup.CPUReserve),
+		Memory:   (specs.RAM - int64(pl.Cfg.Cgroup.MemoryReserve)) << 20,
+	}
+	// a tier too small for the reserve is left unlimited
+	if limits.MilliCPU < 0 {
+		limits.MilliCPU = 0
+	}
+	if limits.Memory < 0 {
+		limits.Memory = 0
+	}
+	group, err := cgroup.New(limits)
+	if err != nil {
+		logger.Warnf("running commands without resource limits, failed to create cgroup: %v", err)
+		return ctx, nil
+	}
+	logger.Infof("running commands in cgroup limited to %d millicores and %s of memory",
+		limits.MilliCPU, cgroup.FormatMemory(limits.Memory))
+	return cgroup.ContextWithGroup(ctx, group), group
+}
+
+// oomRemark returns the remark of the task if its commands were killed on reaching the memory limit of group
+func oomRemark(group *cgroup.Group, logger lumber.Logger) error {
+	if group == nil {
+		return nil
+	}
+	kills, err := group.OOMKills()
+	if err != nil {
+		logger.Errorf("failed to read memory events of cgroup, error: %v", err)
+		return nil
+	}
+	if kills == 0 || group.Limits().Memory == 0 {
+		return nil
+	}
+	logger.Errorf("%d processes killed on reaching the memory limit", kills)
+	return errs.ErrOutOfMemory(cgroup.FormatMemory(group.Limits().Memory))
+}
+
 func (pl *Pipeline) setEnv(payload *Payload, coverageDir string) {
 	// set testing taskID, orgID and buildID as environment variable
 	os.Setenv("TASK_ID", payload.TaskID)
@@ -4,10 +4,14 @@
 package core
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"gopkg.in/yaml.v3"
 )
 
 // ExecutionID type
@@ -23,6 +27,7 @@ const (
 	PurposePreRunLogs     SASURLPurpose = "pre_run_logs"
 	PurposePostRunLogs    SASURLPurpose = "post_run_logs"
 	PurposeExecutionLogs  SASURLPurpose = "execution_logs"
+	PurposeArtifacts      SASURLPurpose = "artifacts"
 )
 
 // Tier type of synapse
@@ -72,6 +77,60 @@ const (
 	RenameCloneFile CommandType = "renameclonefile"
 )
 
+// StepResult represents the outcome of a single command run by the ExecutionManager
+type StepResult struct {
+	// SubModule is the submodule the step is run for, empty for the top level steps
+	SubModule string `json:"submodule,omitempty"`
+	// Stage is the stage of the task the step is run in, e.g. prerun or postrun
+	Stage CommandType `json:"stage,omitempty"`
+	// Command is the command with secrets masked
+	Command   string    `json:"command"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	ExitCode  int       `json:"exit_code"`
+	Attempts  int       `json:"attempts"`
+	// Output is the tail of the command output
+	Output string `json:"output,omitempty"`
+}
+
+// StepsFailed is the error returned when user commands fail, it carries the results of the steps run
+type StepsFailed struct {
+	Remark string
+	Steps  []*StepResult
+}
+
+func (e *StepsFailed) Error() string {
+	return e.Remark
+}
+
+// StepError is the error of the user commands failed at the step at Index
+type StepError struct {
+	Index int
+	Err   error
+}
+
+func (e *StepError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// NewStepsFailed returns a StepsFailed error whose remark names the step err failed at, if any
+func NewStepsFailed(remark string, steps []*StepResult, err error) *StepsFailed {
+	var stepErr *StepError
+	if errors.As(err, &stepErr) && stepErr.Index >= 0 && stepErr.Index < len(steps) {
+		step := steps[stepErr.Index]
+		if step.ExitCode != 0 {
+			remark = fmt.Sprintf("%s: step %d `%s` exited with code %d", remark, stepErr.Index+1, step.Command, step.ExitCode)
+		} else {
+			remark = fmt.Sprintf("%s: step %d `%s` failed, %v", remark, stepErr.Index+1, step.Command, stepErr.Err)
+		}
+	}
+	return &StepsFailed{Remark: remark, Steps: steps}
+}
+
 // EventType represents the webhook event
 type EventType string
 
@@ -136,6 +195,7 @@ type Pipeline struct {
 	CoverageService      CoverageService
 	TestStats            TestStats
 	Task                 Task
+	Heartbeat            Task
 	SecretParser         SecretParser
 	Builder              Builder
 }
@@ -153,6 +213,7 @@ type DiscoveryResult struct {
 	OrgID           string             `json:"orgID"`
 	Branch          string             `json:"branch"`
 	SubModule       string             `json:"subModule"`
+	Matrix          map[string]string  `json:"matrix,omitempty"`
 }
 
 // ExecutionResult represents the request body for test and test suite execution
@@ -170,6 +231,8 @@ type ExecutionResults struct {
 	CommitID string            `json:"commitID"`
 	TaskType TaskType          `json:"taskType"`
 	Results  []ExecutionResult `json:"results"`
+	Matrix   map[string]string `json:"matrix,omitempty"`
+	Status   Status            `json:"status,omitempty"`
 }
 
 // TestReportResponsePayload represents the response body for test and test suite report api.
@@ -242,19 +305,23 @@ const (
 
 // TaskPayload repersent task response given by nucleus to neuron
 type TaskPayload struct {
-	TaskID      string    `json:"task_id"`
-	Status      Status    `json:"status"`
-	RepoSlug    string    `json:"repo_slug"`
-	RepoLink    string    `json:"repo_link"`
-	RepoID      string    `json:"repo_id"`
-	OrgID       string    `json:"org_id"`
-	GitProvider string    `json:"git_provider"`
-	CommitID    string    `json:"commit_id,omitempty"`
-	BuildID     string    `json:"build_id"`
-	StartTime   time.Time `json:"start_time"`
-	EndTime     time.Time `json:"end_time,omitempty"`
-	Remark      string    `json:"remark,omitempty"`
-	Type        TaskType  `json:"type"`
+	TaskID      string        `json:"task_id"`
+	Status      Status        `json:"status"`
+	RepoSlug    string        `json:"repo_slug"`
+	RepoLink    string        `json:"repo_link"`
+	RepoID      string        `json:"repo_id"`
+	OrgID       string        `json:"org_id"`
+	GitProvider string        `json:"git_provider"`
+	CommitID    string        `json:"commit_id,omitempty"`
+	BuildID     string        `json:"build_id"`
+	StartTime   time.Time     `json:"start_time"`
+	EndTime     time.Time     `json:"end_time,omitempty"`
+	Remark      string        `json:"remark,omitempty"`
+	Type        TaskType      `json:"type"`
+	Steps       []*StepResult `json:"steps,omitempty"`
+	Artifacts   []string      `json:"artifacts,omitempty"`
+	TraceID     string        `json:"trace_id,omitempty"`
+	Progress    *TaskProgress `json:"progress,omitempty"`
 }
 
 // CoverageManifest for post processing coverage job
@@ -301,24 +368,29 @@ type Oauth struct {
 
 // TASConfig represents the .tas.yml file
 type TASConfig struct {
-	SmartRun          bool               `yaml:"smartRun"`
-	Framework         string             `yaml:"framework" validate:"required,oneof=jest mocha jasmine golang junit"`
-	Blocklist         []string           `yaml:"blocklist"`
-	Postmerge         *Merge             `yaml:"postMerge" validate:"omitempty"`
-	Premerge          *Merge             `yaml:"preMerge" validate:"omitempty"`
-	Cache             *Cache             `yaml:"cache" validate:"omitempty"`
-	Prerun            *Run               `yaml:"preRun" validate:"omitempty"`
-	Postrun           *Run               `yaml:"postRun" validate:"omitempty"`
-	Parallelism       int                `yaml:"parallelism"`
-	SplitMode         SplitMode          `yaml:"splitMode" validate:"oneof=test file"`
-	SkipCache         bool               `yaml:"skipCache"`
-	ConfigFile        string             `yaml:"configFile" validate:"omitempty"`
-	CoverageThreshold *CoverageThreshold `yaml:"coverageThreshold" validate:"omitempty"`
-	Tier              Tier               `yaml:"tier" validate:"oneof=xsmall small medium large xlarge"`
-	NodeVersion       string             `yaml:"nodeVersion" validate:"omitempty,semver"`
-	ContainerImage    string             `yaml:"containerImage"`
-	FrameworkVersion  int                `yaml:"frameworkVersion" validate:"omitempty"`
-	Version           string             `yaml:"version" validate:"required"`
+	SmartRun          bool                 `yaml:"smartRun"`
+	Framework         string               `yaml:"framework" validate:"required,oneof=jest mocha jasmine golang junit"`
+	Blocklist         []string             `yaml:"blocklist"`
+	Postmerge         *Merge               `yaml:"postMerge" validate:"omitempty"`
+	Premerge          *Merge               `yaml:"preMerge" validate:"omitempty"`
+	Cache             *Cache               `yaml:"cache" validate:"omitempty"`
+	Prerun            *Run                 `yaml:"preRun" validate:"omitempty"`
+	Postrun           *Run                 `yaml:"postRun" validate:"omitempty"`
+	Parallelism       int                  `yaml:"parallelism"`
+	SplitMode         SplitMode            `yaml:"splitMode" validate:"oneof=test file"`
+	SkipCache         bool                 `yaml:"skipCache"`
+	ConfigFile        string               `yaml:"configFile" validate:"omitempty"`
+	CoverageThreshold *CoverageThreshold   `yaml:"coverageThreshold" validate:"omitempty"`
+	Tier              Tier                 `yaml:"tier" validate:"oneof=xsmall small medium large xlarge"`
+	NodeVersion       string               `yaml:"nodeVersion" validate:"omitempty,semver"`
+	ContainerImage    string               `yaml:"containerImage"`
+	FrameworkVersion  int                  `yaml:"frameworkVersion" validate:"omitempty"`
+	Version           string               `yaml:"version" validate:"required"`
+	Artifacts         []string             `yaml:"artifacts" validate:"omitempty,dive,required"`
+	Services          []Service            `yaml:"services" validate:"omitempty,dive"`
+	MaskPatterns      []string             `yaml:"maskPatterns" validate:"omitempty,dive,required"`
+	InheritEnv        []string             `yaml:"inheritEnv" validate:"omitempty,dive,required"`
+	SecretPolicy      *config.SecretPolicy `yaml:"secretPolicy" validate:"omitempty"`
 }
 
 // CoverageThreshold reprents the code coverage threshold
@@ -345,10 +417,47 @@ type Modifier struct {
 
 // Run represents  pre and post runs
 type Run struct {
-	Commands []string          `yaml:"command" validate:"omitempty,gt=0"`
+	Commands []Step            `yaml:"command" validate:"omitempty,gt=0,dive"`
 	EnvMap   map[string]string `yaml:"env" validate:"omitempty,gt=0"`
 }
 
+// Step represents a single command of pre and post runs.
+// It can be written either as a plain command or as an object.
+type Step struct {
+	Run             string        `yaml:"run" json:"run" validate:"required"`
+	Timeout         time.Duration `yaml:"timeout" json:"timeout,omitempty" validate:"min=0"`
+	Retries         int           `yaml:"retries" json:"retries,omitempty" validate:"min=0,max=10"`
+	ContinueOnError bool          `yaml:"continueOnError" json:"continueOnError,omitempty"`
+	If              *Condition    `yaml:"if" json:"if,omitempty" validate:"omitempty"`
+}
+
+// Condition restricts when a step or a submodule runs.
+// Every field given must match, and a field matches if any of its values matches.
+type Condition struct {
+	EventType  []EventType `yaml:"eventType" json:"eventType,omitempty" validate:"omitempty,dive,oneof=push pull-request"`
+	BranchName []string    `yaml:"branchName" json:"branchName,omitempty" validate:"omitempty,dive,required"`
+	// Changed holds globs, relative to repo root, matched against the files changed in the build
+	Changed []string `yaml:"changed" json:"changed,omitempty" validate:"omitempty,dive,required"`
+}
+
+// UnmarshalYAML decodes a step given either as a plain command or as an object
+func (s *Step) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&s.Run)
+	}
+	type step Step
+	return value.Decode((*step)(s))
+}
+
+// MarshalJSON encodes steps having only a command as plain strings
+func (s Step) MarshalJSON() ([]byte, error) {
+	if s.Timeout == 0 && s.Retries == 0 && !s.ContinueOnError && s.If == nil {
+		return json.Marshal(s.Run)
+	}
+	type step Step
+	return json.Marshal(step(s))
+}
+
 // Merge represents pre and post merge
 type Merge struct {
 	Patterns []string          `yaml:"pattern" validate:"required,gt=0"`
@@ -380,39 +489,50 @@ const (
 
 // TASConfigV2 repersent TASConfig for version 2 and above
 type TASConfigV2 struct {
-	SmartRun          bool               `yaml:"smartRun"`
-	Cache             *Cache             `yaml:"cache" validate:"omitempty"`
-	Tier              Tier               `yaml:"tier" validate:"oneof=xsmall small medium large xlarge"`
-	PostMerge         *MergeV2           `yaml:"postMerge" validate:"omitempty"`
-	PreMerge          *MergeV2           `yaml:"preMerge" validate:"omitempty"`
-	SkipCache         bool               `yaml:"skipCache"`
-	CoverageThreshold *CoverageThreshold `yaml:"coverageThreshold" validate:"omitempty"`
-	Parallelism       int                `yaml:"parallelism"` // TODO: will be supported later
-	Version           string             `yaml:"version" validate:"required"`
-	SplitMode         SplitMode          `yaml:"splitMode" validate:"oneof=test file"`
-	ContainerImage    string             `yaml:"containerImage"`
-	NodeVersion       string             `yaml:"nodeVersion" validate:"omitempty,semver"`
+	SmartRun          bool                 `yaml:"smartRun"`
+	Cache             *Cache               `yaml:"cache" validate:"omitempty"`
+	Tier              Tier                 `yaml:"tier" validate:"oneof=xsmall small medium large xlarge"`
+	PostMerge         *MergeV2             `yaml:"postMerge" validate:"omitempty"`
+	PreMerge          *MergeV2             `yaml:"preMerge" validate:"omitempty"`
+	SkipCache         bool                 `yaml:"skipCache"`
+	CoverageThreshold *CoverageThreshold   `yaml:"coverageThreshold" validate:"omitempty"`
+	Parallelism       int                  `yaml:"parallelism"` // TODO: will be supported later
+	Version           string               `yaml:"version" validate:"required"`
+	SplitMode         SplitMode            `yaml:"splitMode" validate:"oneof=test file"`
+	ContainerImage    string               `yaml:"containerImage"`
+	NodeVersion       string               `yaml:"nodeVersion" validate:"omitempty,semver"`
+	Artifacts         []string             `yaml:"artifacts" validate:"omitempty,dive,required"`
+	Services          []Service            `yaml:"services" validate:"omitempty,dive"`
+	MaskPatterns      []string             `yaml:"maskPatterns" validate:"omitempty,dive,required"`
+	InheritEnv        []string             `yaml:"inheritEnv" validate:"omitempty,dive,required"`
+	SecretPolicy      *config.SecretPolicy `yaml:"secretPolicy" validate:"omitempty"`
 }
 
 // MergeV2 repersent MergeConfig for version 2 and above
 type MergeV2 struct {
-	PreRun     *Run              `yaml:"preRun" validate:"omitempty"`
-	SubModules []SubModule       `yaml:"subModules" validate:"required,gt=0"`
-	EnvMap     map[string]string `yaml:"env" validate:"omitempty,gt=0"`
+	PreRun     *Run                `yaml:"preRun" validate:"omitempty"`
+	SubModules []SubModule         `yaml:"subModules" validate:"required,gt=0"`
+	EnvMap     map[string]string   `yaml:"env" validate:"omitempty,gt=0"`
+	Matrix     map[string][]string `yaml:"matrix" validate:"omitempty,dive,gt=0,dive,required"`
 }
 
 // SubModule represent the structure of subModule yaml v2
 type SubModule struct {
-	Name               string   `yaml:"name" validate:"required"`
-	Path               string   `yaml:"path" validate:"required"`
-	Patterns           []string `yaml:"pattern" validate:"required,gt=0"`
-	Framework          string   `yaml:"framework" validate:"required,oneof=jest mocha jasmine"`
-	Blocklist          []string `yaml:"blocklist"`
-	Prerun             *Run     `yaml:"preRun" validate:"omitempty"`
-	Postrun            *Run     `yaml:"postRun" validate:"omitempty"`
-	RunPrerunEveryTime bool     `yaml:"runPreRunEveryTime"`
-	Parallelism        int      `yaml:"parallelism"` // TODO: will be supported later
-	ConfigFile         string   `yaml:"configFile" validate:"omitempty"`
+	Name               string              `yaml:"name" validate:"required"`
+	Path               string              `yaml:"path" validate:"required"`
+	Patterns           []string            `yaml:"pattern" validate:"required,gt=0"`
+	Framework          string              `yaml:"framework" validate:"required,oneof=jest mocha jasmine"`
+	Blocklist          []string            `yaml:"blocklist"`
+	Prerun             *Run                `yaml:"preRun" validate:"omitempty"`
+	Postrun            *Run                `yaml:"postRun" validate:"omitempty"`
+	RunPrerunEveryTime bool                `yaml:"runPreRunEveryTime"`
+	Parallelism        int                 `yaml:"parallelism"` // TODO: will be supported later
+	ConfigFile         string              `yaml:"configFile" validate:"omitempty"`
+	If                 *Condition          `yaml:"if" validate:"omitempty"`
+	Artifacts          []string            `yaml:"artifacts" validate:"omitempty,dive,required"`
+	Matrix             map[string][]string `yaml:"matrix" validate:"omitempty,dive,gt=0,dive,required"`
+	// MatrixValues holds the coordinates of a submodule variant expanded from the matrix
+	MatrixValues map[string]string `yaml:"-"`
 }
 
 // TasVersion used to identify yaml version
@@ -453,6 +573,7 @@ type TestExecutionArgs struct {
 	SecretData        map[string]string
 	FrameWorkVersion  int
 	CWD               string
+	Matrix            map[string]string
 }
 
 // YMLParsingRequestMessage defines yml parsing request received from TAS server
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// TaskProgress is the progress of a task, reported to neuron with each heartbeat
+type TaskProgress struct {
+	Stage          string              `json:"stage"`
+	SubModules     []SubModuleProgress `json:"sub_modules,omitempty"`
+	TestsCompleted int                 `json:"tests_completed"`
+}
+
+// SubModuleProgress is the current stage of a submodule, the stages of the submodules run concurrently
+type SubModuleProgress struct {
+	Name  string `json:"name"`
+	Stage string `json:"stage"`
+}
+
+type progressKey struct{}
+
+// progressTracker tracks the progress of a task, which is updated by the stages running concurrently
+type progressTracker struct {
+	mu             sync.Mutex
+	stage          string
+	subModules     map[string]string
+	testsCompleted int
+}
+
+// ContextWithProgress returns a copy of ctx tracking the progress of the task
+func ContextWithProgress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, progressKey{}, &progressTracker{subModules: make(map[string]string)})
+}
+
+// SetProgressStage records stage as the current stage of the submodule of ctx, or of the task of ctx
+// when ctx has no submodule
+func SetProgressStage(ctx context.Context, stage string) {
+	tracker, ok := ctx.Value(progressKey{}).(*progressTracker)
+	if !ok {
+		return
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if value, ok := lumber.FieldsFromContext(ctx)[lumber.FieldSubModule]; ok {
+		tracker.subModules[fmt.Sprint(value)] = stage
+		return
+	}
+	tracker.stage = stage
+}
+
+// AddTestsCompleted adds count to the tests completed by the task of ctx
+func AddTestsCompleted(ctx context.Context, count int) {
+	tracker, ok := ctx.Value(progressKey{}).(*progressTracker)
+	if !ok {
+		return
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.testsCompleted += count
+}
+
+// ProgressFromContext returns the progress of the task of ctx
+func ProgressFromContext(ctx context.Context) TaskProgress {
+	tracker, ok := ctx.Value(progressKey{}).(*progressTracker)
+	if !ok {
+		return TaskProgress{}
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	progress := TaskProgress{Stage: tracker.stage, TestsCompleted: tracker.testsCompleted}
+	for name, stage := range tracker.subModules {
+		progress.SubModules = append(progress.SubModules, SubModuleProgress{Name: name, Stage: stage})
+	}
+	sort.Slice(progress.SubModules, func(i, j int) bool { return progress.SubModules[i].Name < progress.SubModules[j].Name })
+	return progress
+}
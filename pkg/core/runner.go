@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/LambdaTest/test-at-scale/config"
@@ -114,6 +115,34 @@ type RunnerOptions struct {
 	LogfilePath       string            `json:"logfile_path"`
 	PodType           PodType           `json:"pod_type"`
 	Tier              Tier              `json:"tier"`
+	Services          []Service         `json:"services,omitempty"`
+	// ServiceNetworkID is the network on which the services are started
+	ServiceNetworkID string `json:"-"`
+	// ServiceContainerIDs are the containers started for the services
+	ServiceContainerIDs []string `json:"-"`
+	// ServicesMu guards the services, which are stopped both when the container completes and when it is destroyed
+	ServicesMu sync.Mutex `json:"-"`
+}
+
+// Service represents a container, like a database, started alongside the tests
+type Service struct {
+	Name        string            `yaml:"name" json:"name" validate:"required,hostname_rfc1123"`
+	Image       string            `yaml:"image" json:"image" validate:"required"`
+	Env         map[string]string `yaml:"env" json:"env,omitempty"`
+	Ports       []int             `yaml:"ports" json:"ports,omitempty" validate:"omitempty,dive,min=1,max=65535"`
+	HealthCheck *HealthCheck      `yaml:"healthCheck" json:"healthCheck,omitempty" validate:"omitempty"`
+	// CPU is the number of cores the service is limited to, the synapse default if not set
+	CPU float32 `yaml:"cpu" json:"cpu,omitempty" validate:"min=0"`
+	// RAM is the memory in MiB the service is limited to, the synapse default if not set
+	RAM int64 `yaml:"ram" json:"ram,omitempty" validate:"min=0"`
+}
+
+// HealthCheck represents the command run inside a service container to check if it is ready
+type HealthCheck struct {
+	Command  string        `yaml:"command" json:"command" validate:"required"`
+	Interval time.Duration `yaml:"interval" json:"interval,omitempty" validate:"min=0"`
+	Timeout  time.Duration `yaml:"timeout" json:"timeout,omitempty" validate:"min=0"`
+	Retries  int           `yaml:"retries" json:"retries,omitempty" validate:"min=0"`
 }
 
 // VaultOpts provides the vault path options
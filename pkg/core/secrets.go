@@ -1,6 +1,10 @@
 package core
 
-import "github.com/LambdaTest/test-at-scale/config"
+import (
+	"context"
+
+	"github.com/LambdaTest/test-at-scale/config"
+)
 
 // Secret struct for holding secret data
 type Secret map[string]string
@@ -18,14 +22,38 @@ type SecretsManager interface {
 	// GetDockerSecrets returns Mode , RegistryAuth, and URL for pulling remote docker image
 	GetDockerSecrets(r *RunnerOptions) (ContainerImageConfig, error)
 
+	// GetRegistryAuth returns the auth of the registry hosting image, empty if no credential is configured for it
+	GetRegistryAuth(image string) (string, error)
+
 	// GetSynapseName returns synapse name mentioned in config
 	GetSynapseName() string
-	// GetOauthToken returns oauth token
-	GetOauthToken() *Oauth
+	// GetOauthToken returns oauth token of the git credential matching repo
+	GetOauthToken(repo *GitRepo) *Oauth
+
+	// GetGitSecretBytes get git secrets of the credential matching repo in bytes
+	GetGitSecretBytes(repo *GitRepo) ([]byte, error)
+
+	// GetRepoSecretBytes get the repo secrets made available to build by the secret policy in bytes,
+	// all the repo secrets if build is not known
+	GetRepoSecretBytes(repo string, build *Payload) ([]byte, error)
 
-	// GetGitSecretBytes get git secrets in bytes
-	GetGitSecretBytes() ([]byte, error)
+	// GetSecretPolicyBytes get the policy of repo secrets in bytes
+	GetSecretPolicyBytes() ([]byte, error)
+}
+
+// SecretProvider defines a source of repo and git secrets
+type SecretProvider interface {
+	// Name returns the name of the provider
+	Name() string
+	// GetRepoSecrets returns the secrets of repo, nil if the provider has none
+	GetRepoSecrets(ctx context.Context, repo string) (map[string]string, error)
+	// GetGitSecrets returns the git credentials, nil if the provider has none
+	GetGitSecrets(ctx context.Context) ([]config.GitConfig, error)
+}
 
-	// GetRepoSecretBytes get repo secrets in bytes
-	GetRepoSecretBytes(repo string) ([]byte, error)
+// GitRepo identifies the repo for which a git credential is selected, the fields not known are empty
+type GitRepo struct {
+	Provider string
+	Host     string
+	Slug     string
 }
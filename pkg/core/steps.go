@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+type stepsKey struct{}
+
+// stepsRecorder records the results of the user command steps of a task, which are run concurrently for submodules
+type stepsRecorder struct {
+	mu    sync.Mutex
+	steps []*StepResult
+}
+
+// ContextWithSteps returns a copy of ctx recording the results of the steps run by the task
+func ContextWithSteps(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stepsKey{}, &stepsRecorder{})
+}
+
+// RecordSteps adds the results of steps run by the task of ctx, which are marked with the submodule and the
+// stage of ctx
+func RecordSteps(ctx context.Context, steps []*StepResult) {
+	recorder, ok := ctx.Value(stepsKey{}).(*stepsRecorder)
+	if !ok {
+		return
+	}
+	fields := lumber.FieldsFromContext(ctx)
+	for _, step := range steps {
+		if value, ok := fields[lumber.FieldSubModule]; ok {
+			step.SubModule = fmt.Sprint(value)
+		}
+		if value, ok := fields[lumber.FieldStage]; ok {
+			step.Stage = CommandType(fmt.Sprint(value))
+		}
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.steps = append(recorder.steps, steps...)
+}
+
+// StepsFromContext returns the results of the steps run by the task of ctx
+func StepsFromContext(ctx context.Context) []*StepResult {
+	recorder, ok := ctx.Value(stepsKey{}).(*stepsRecorder)
+	if !ok {
+		return nil
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return append([]*StepResult(nil), recorder.steps...)
+}
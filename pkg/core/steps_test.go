@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+func TestNewStepsFailed(t *testing.T) {
+	steps := []*StepResult{
+		{Command: "npm ci", ExitCode: 0},
+		{Command: "npm run lint", ExitCode: 2},
+		{Command: "echo ${{ secrets.NPM_TOKEN }}"},
+		{Command: "npm run build", ExitCode: 0},
+	}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"failed step", &StepError{Index: 1, Err: errors.New("exit status 2")},
+			"Failed in running pre-run steps: step 2 `npm run lint` exited with code 2"},
+		{"step failed before running", fmt.Errorf("wrapped: %w", &StepError{Index: 2, Err: errors.New("secret NPM_TOKEN not found")}),
+			"Failed in running pre-run steps: step 3 `echo ${{ secrets.NPM_TOKEN }}` failed, secret NPM_TOKEN not found"},
+		{"failed after the steps", errors.New("failed to upload logs"), "Failed in running pre-run steps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewStepsFailed("Failed in running pre-run steps", steps, tt.err)
+			if got.Remark != tt.want {
+				t.Errorf("NewStepsFailed() remark = %q, want %q", got.Remark, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordSteps(t *testing.T) {
+	ctx := ContextWithSteps(context.Background())
+	logger, err := lumber.NewLogger(lumber.LoggingConfig{EnableConsole: true}, true, lumber.InstanceZapLogger)
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	topCtx, _ := lumber.ContextWithFields(ctx, logger, lumber.Fields{lumber.FieldStage: PreRun})
+	apiCtx, _ := lumber.ContextWithFields(ctx, logger, lumber.Fields{lumber.FieldSubModule: "api"})
+	apiCtx, _ = lumber.ContextWithFields(apiCtx, logger, lumber.Fields{lumber.FieldStage: PostRun})
+	RecordSteps(topCtx, []*StepResult{{Command: "npm ci"}})
+	RecordSteps(apiCtx, []*StepResult{{Command: "npm run report"}})
+	want := []*StepResult{
+		{Stage: PreRun, Command: "npm ci"},
+		{SubModule: "api", Stage: PostRun, Command: "npm run report"},
+	}
+	if got := StepsFromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("StepsFromContext() = %v, want %v", got, want)
+	}
+	preRun := []*StepResult{{Command: "npm ci"}}
+	RecordSteps(context.Background(), preRun)
+	if got := StepsFromContext(context.Background()); got != nil {
+		t.Errorf("StepsFromContext() without recorder = %v, want nil", got)
+	}
+}
@@ -9,4 +9,8 @@ import (
 type SynapseManager interface {
 	// InitiateConnection initiates the connection with LT cloud
 	InitiateConnection(ctx context.Context, wg *sync.WaitGroup, connectionFailed chan struct{})
+	// SendLogChunk sends the log chunk streamed by a task to LT cloud
+	SendLogChunk(chunk *LogChunk) error
+	// Connected returns whether the websocket connection to LT cloud is open
+	Connected() bool
 }
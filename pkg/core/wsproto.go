@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 // MessageType defines type of message
 type MessageType string
 
@@ -21,6 +23,7 @@ const (
 	MsgBuildAbort        MessageType = "build_abort"
 	MsgYMLParsingRequest MessageType = "yml_parsing_request"
 	MsgYMLParsingResult  MessageType = "yml_parsing_result"
+	MsgLogChunk          MessageType = "log_chunk"
 )
 
 // JobInfo types
@@ -75,3 +78,25 @@ type JobInfo struct {
 type BuildAbortMsg struct {
 	BuildID string `json:"build_id"`
 }
+
+// MaxLogChunkSize is the maximum size of an encoded LogChunk accepted by synapse
+const MaxLogChunkSize = 1 << 20
+
+// LogChunk struct defines a frame of log lines streamed live from a task. The frames of a stream
+// are numbered by Seq, as the frames spooled while disconnected are sent after the newer ones.
+type LogChunk struct {
+	OrgID    string        `json:"org_id"`
+	BuildID  string        `json:"build_id"`
+	TaskID   string        `json:"task_id"`
+	StreamID string        `json:"stream_id"`
+	Purpose  SASURLPurpose `json:"purpose"`
+	Seq      int           `json:"seq"`
+	Dropped  int           `json:"dropped"`
+	Lines    []LogLine     `json:"lines"`
+}
+
+// LogLine struct defines a timestamped log line
+type LogLine struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
 	"github.com/LambdaTest/test-at-scale/pkg/urlmanager"
 )
 
@@ -197,29 +198,32 @@ func (dm *diffManager) parseGitDiff(gitprovider string, eventType core.EventType
 }
 
 // GetChangedFiles Figure out changed files
-func (dm *diffManager) GetChangedFiles(ctx context.Context, payload *core.Payload, oauth *core.Oauth) (map[string]int, error) {
-	// map to store file and type of change (added, removed, modified)
-	var m map[string]int
-
+func (dm *diffManager) GetChangedFiles(ctx context.Context,
+	payload *core.Payload,
+	oauth *core.Oauth) (m map[string]int, err error) {
+	logger := lumber.FromContext(ctx, dm.logger)
+	_, span := tracing.Start(ctx, tracing.SpanDiff)
+	defer func() { tracing.End(span, err) }()
+
+	// m stores the files and their type of change (added, removed, modified)
 	var diff []byte
-	var err error
 	if payload.EventType == core.EventPullRequest {
 		diff, err = dm.getPRDiff(payload.GitProvider, payload.RepoLink, payload.PullRequestNumber, oauth)
 		if err != nil {
-			dm.logger.Errorf("failed to parse pr diff for gitprovider: %s error: %v", payload.GitProvider, err)
+			logger.Errorf("failed to parse pr diff for gitprovider: %s error: %v", payload.GitProvider, err)
 			return nil, err
 		}
 	} else {
 		diff, err = dm.getCommitDiff(payload.GitProvider, payload.RepoLink, oauth, payload.BuildBaseCommit, payload.BuildTargetCommit, payload.ForkSlug)
 		if err != nil {
-			dm.logger.Errorf("failed to get commit diff for gitprovider: %s error: %v", payload.GitProvider, err)
+			logger.Errorf("failed to get commit diff for gitprovider: %s error: %v", payload.GitProvider, err)
 			return nil, err
 		}
 	}
 
 	m, err = dm.parseGitDiff(payload.GitProvider, payload.EventType, diff)
 	if err != nil {
-		dm.logger.Errorf("failed to parse gitdiff for gitprovider: %s error: %v", payload.GitProvider, err)
+		logger.Errorf("failed to parse gitdiff for gitprovider: %s error: %v", payload.GitProvider, err)
 		return nil, err
 	}
 	return m, nil
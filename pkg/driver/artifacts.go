@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"context"
+	"path"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// uploadArtifacts uploads the files matching the patterns, relative to repo root, and lists the
+// artifact in the task result. Failure in uploading artifacts does not fail the task. The artifacts of
+// an aborted task are uploaded within the grace period of the task.
+func uploadArtifacts(ctx context.Context,
+	artifactManager core.ArtifactManager,
+	logger lumber.Logger,
+	taskPayload *core.TaskPayload,
+	name string,
+	patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+	graceCtx, cancel := core.GraceContext(ctx)
+	defer cancel()
+	logger.Infof("Uploading artifacts")
+	artifactURL, err := artifactManager.Upload(graceCtx, name, global.RepoDir, patterns...)
+	if err != nil {
+		logger.Errorf("Unable to upload artifacts, error: %v", err)
+		return
+	}
+	if artifactURL != "" {
+		taskPayload.Artifacts = append(taskPayload.Artifacts, artifactURL)
+	}
+}
+
+// getArtifactPatterns returns the top level artifact patterns along with the
+// submodule artifact patterns made relative to repo root.
+func getArtifactPatterns(tasConfig *core.TASConfigV2, subModule *core.SubModule) []string {
+	patterns := make([]string, 0, len(tasConfig.Artifacts)+len(subModule.Artifacts))
+	patterns = append(patterns, tasConfig.Artifacts...)
+	for _, pattern := range subModule.Artifacts {
+		patterns = append(patterns, path.Join(subModule.Path, pattern))
+	}
+	return patterns
+}
@@ -0,0 +1,63 @@
+package driver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/mocks"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetArtifactPatterns(t *testing.T) {
+	tasConfig := &core.TASConfigV2{Artifacts: []string{"logs/*.log"}}
+	tests := []struct {
+		name      string
+		subModule *core.SubModule
+		want      []string
+	}{
+		{"Test top level artifacts only", &core.SubModule{Path: "./packages/api"}, []string{"logs/*.log"}},
+		{
+			"Test submodule artifacts are relative to submodule path",
+			&core.SubModule{Path: "./packages/api", Artifacts: []string{"screenshots/**", "./coverage"}},
+			[]string{"logs/*.log", "packages/api/screenshots/**", "packages/api/coverage"},
+		},
+		{
+			"Test submodule at repo root",
+			&core.SubModule{Path: ".", Artifacts: []string{"coverage"}},
+			[]string{"logs/*.log", "coverage"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getArtifactPatterns(tasConfig, tt.subModule); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getArtifactPatterns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadArtifactsOfAbortedTask(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(core.ContextWithGracePeriod(context.Background(), time.Minute))
+	cancel()
+	artifactManager := new(mocks.ArtifactManager)
+	artifactManager.On("Upload", mock.Anything, "task", mock.Anything, "logs/*.log").Return(
+		func(ctx context.Context, name, root string, patterns ...string) string {
+			if ctx.Err() != nil {
+				t.Errorf("Upload() called with canceled context, want the grace period context")
+			}
+			return "https://artifacts/task.zip"
+		}, nil)
+	taskPayload := &core.TaskPayload{}
+	uploadArtifacts(ctx, artifactManager, logger, taskPayload, "task", []string{"logs/*.log"})
+	if want := []string{"https://artifacts/task.zip"}; !reflect.DeepEqual(taskPayload.Artifacts, want) {
+		t.Errorf("artifacts = %v, want %v", taskPayload.Artifacts, want)
+	}
+}
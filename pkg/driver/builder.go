@@ -8,6 +8,8 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -25,8 +27,11 @@ type (
 		ExecutionManager     core.ExecutionManager
 		TASConfigManager     core.TASConfigManager
 		CacheStore           core.CacheStore
+		ArtifactManager      core.ArtifactManager
 		DiffManager          core.DiffManager
 		ListSubModuleService core.ListSubModuleService
+		SecretParser         core.SecretParser
+		LogWriterFactory     core.LogWriterFactory
 	}
 	NodeInstaller struct {
 		logger           lumber.Logger
@@ -46,8 +51,11 @@ func (b *Builder) GetDriver(version int, filePath string) (core.Driver, error) {
 			ExecutionManager:     b.ExecutionManager,
 			TASConfigManager:     b.TASConfigManager,
 			CacheStore:           b.CacheStore,
+			ArtifactManager:      b.ArtifactManager,
 			DiffManager:          b.DiffManager,
 			ListSubModuleService: b.ListSubModuleService,
+			SecretParser:         b.SecretParser,
+			LogWriterFactory:     b.LogWriterFactory,
 			TASVersion:           firstVersion,
 			TASFilePath:          filePath,
 			nodeInstaller: NodeInstaller{
@@ -65,8 +73,11 @@ func (b *Builder) GetDriver(version int, filePath string) (core.Driver, error) {
 			ExecutionManager:     b.ExecutionManager,
 			TASConfigManager:     b.TASConfigManager,
 			CacheStore:           b.CacheStore,
+			ArtifactManager:      b.ArtifactManager,
 			DiffManager:          b.DiffManager,
 			ListSubModuleService: b.ListSubModuleService,
+			SecretParser:         b.SecretParser,
+			LogWriterFactory:     b.LogWriterFactory,
 			TASVersion:           secondVersion,
 			TASFilePath:          filePath,
 			nodeInstaller: NodeInstaller{
@@ -79,7 +90,22 @@ func (b *Builder) GetDriver(version int, filePath string) (core.Driver, error) {
 	}
 }
 
-func (n *NodeInstaller) InstallNodeVersion(ctx context.Context, nodeVersion string) error {
+func (n *NodeInstaller) InstallNodeVersion(ctx context.Context, nodeVersion string) (err error) {
+	ctx, span := tracing.Start(ctx, tracing.SpanNodeInstall, attribute.String("nodeVersion", nodeVersion))
+	core.SetProgressStage(ctx, tracing.SpanNodeInstall)
+	defer func() { tracing.End(span, err) }()
+	if err = n.installNode(ctx, nodeVersion); err != nil {
+		return err
+	}
+	origPath := os.Getenv("PATH")
+	os.Setenv("PATH", fmt.Sprintf("%s:%s", nodeBinPath(nodeVersion), origPath))
+	return nil
+}
+
+// installNode installs the node version without making it the default one, so that
+// matrix variants can use different versions through their own PATH.
+func (n *NodeInstaller) installNode(ctx context.Context, nodeVersion string) error {
+	logger := lumber.FromContext(ctx, n.logger)
 	// Running the `source` commands in a directory where .nvmrc is present, exits with exitCode 3
 	// https://github.com/nvm-sh/nvm/issues/1985
 	// TODO [good-to-have]: Auto-read and install from .nvmrc file, if present
@@ -87,14 +113,16 @@ func (n *NodeInstaller) InstallNodeVersion(ctx context.Context, nodeVersion stri
 		"source /home/nucleus/.nvm/nvm.sh",
 		fmt.Sprintf("nvm install %s", nodeVersion),
 	}
-	n.logger.Infof("Using user-defined node version: %v", nodeVersion)
-	err := n.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallNodeVer, commands, "", nil, nil)
+	logger.Infof("Using user-defined node version: %v", nodeVersion)
+	_, err := n.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallNodeVer, commands, "", nil, nil)
 	if err != nil {
-		n.logger.Errorf("Unable to install user-defined nodeversion %v", err)
+		logger.Errorf("Unable to install user-defined nodeversion %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
-	origPath := os.Getenv("PATH")
-	os.Setenv("PATH", fmt.Sprintf("/home/nucleus/.nvm/versions/node/v%s/bin:%s", nodeVersion, origPath))
 	return nil
 }
+
+func nodeBinPath(nodeVersion string) string {
+	return fmt.Sprintf("/home/nucleus/.nvm/versions/node/v%s/bin", nodeVersion)
+}
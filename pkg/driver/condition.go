@@ -0,0 +1,149 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// conditionContext holds the build attributes against which `if` conditions are evaluated
+type conditionContext struct {
+	eventType  core.EventType
+	branchName string
+	diff       map[string]int
+	diffExists bool
+	logger     lumber.Logger
+}
+
+func newConditionContext(payload *core.Payload, diff map[string]int, diffExists bool, logger lumber.Logger) *conditionContext {
+	return &conditionContext{
+		eventType:  payload.EventType,
+		branchName: payload.BranchName,
+		diff:       diff,
+		diffExists: diffExists,
+		logger:     logger,
+	}
+}
+
+// matches reports whether the condition holds for the build, a nil condition always holds.
+func (c *conditionContext) matches(condition *core.Condition) bool {
+	if condition == nil {
+		return true
+	}
+	if len(condition.EventType) > 0 && !c.matchesEvent(condition.EventType) {
+		return false
+	}
+	if len(condition.BranchName) > 0 && !matchesAny(condition.BranchName, c.branchName) {
+		return false
+	}
+	if len(condition.Changed) > 0 && !c.matchesChanged(condition.Changed) {
+		return false
+	}
+	return true
+}
+
+func (c *conditionContext) matchesEvent(eventTypes []core.EventType) bool {
+	for _, eventType := range eventTypes {
+		if eventType == c.eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesChanged reports whether any changed file matches the globs.
+// Without a diff every file is considered changed.
+func (c *conditionContext) matchesChanged(globs []string) bool {
+	if !c.diffExists {
+		return true
+	}
+	for file := range c.diff {
+		if matchesAny(globs, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterSteps returns a copy of run with the steps whose condition does not hold left out.
+func (c *conditionContext) filterSteps(run *core.Run) *core.Run {
+	if run == nil {
+		return nil
+	}
+	filtered := &core.Run{EnvMap: run.EnvMap, Commands: make([]core.Step, 0, len(run.Commands))}
+	for i := range run.Commands {
+		if !c.matches(run.Commands[i].If) {
+			c.logger.Infof("Skipping step `%s` as its `if` condition is not met", run.Commands[i].Run)
+			continue
+		}
+		filtered.Commands = append(filtered.Commands, run.Commands[i])
+	}
+	return filtered
+}
+
+// filterSubModules returns the submodules whose condition holds, with their steps filtered.
+func (c *conditionContext) filterSubModules(subModuleList []core.SubModule) []core.SubModule {
+	filtered := make([]core.SubModule, 0, len(subModuleList))
+	for i := range subModuleList {
+		if !c.matches(subModuleList[i].If) {
+			c.logger.Infof("Skipping submodule %s as its `if` condition is not met", subModuleList[i].Name)
+			continue
+		}
+		subModule := subModuleList[i]
+		subModule.Prerun = c.filterSteps(subModule.Prerun)
+		subModule.Postrun = c.filterSteps(subModule.Postrun)
+		filtered = append(filtered, subModule)
+	}
+	return filtered
+}
+
+// usesChangedCondition reports whether any step of the runs has a condition on changed files.
+func usesChangedCondition(runs ...*core.Run) bool {
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		for i := range run.Commands {
+			if run.Commands[i].If != nil && len(run.Commands[i].If.Changed) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getConditionContext builds the condition context for execution, the diff is fetched only when
+// one of the runs has a condition on changed files.
+func getConditionContext(ctx context.Context,
+	diffManager core.DiffManager,
+	payload *core.Payload,
+	oauth *core.Oauth,
+	logger lumber.Logger,
+	runs ...*core.Run) (*conditionContext, error) {
+	if !usesChangedCondition(runs...) {
+		return newConditionContext(payload, nil, false, logger), nil
+	}
+	diff, err := diffManager.GetChangedFiles(ctx, payload, oauth)
+	if err != nil {
+		if errors.Is(err, errs.ErrGitDiffNotFound) {
+			return newConditionContext(payload, nil, false, logger), nil
+		}
+		logger.Errorf("Unable to identify changed files %s", err)
+		return nil, errs.New("Error occurred in fetching diff from GitHub")
+	}
+	return newConditionContext(payload, diff, true, logger), nil
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		// patterns are validated while loading the tas config, so the error can be ignored
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+)
+
+func TestConditionContextMatches(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	payload := &core.Payload{EventType: core.EventPullRequest, BranchName: "release/v1"}
+	diff := map[string]int{"packages/api/src/index.js": 1}
+
+	tests := []struct {
+		name       string
+		condition  *core.Condition
+		diffExists bool
+		want       bool
+	}{
+		{"nil condition", nil, true, true},
+		{"matching event", &core.Condition{EventType: []core.EventType{core.EventPush, core.EventPullRequest}}, true, true},
+		{"non matching event", &core.Condition{EventType: []core.EventType{core.EventPush}}, true, false},
+		{"matching branch glob", &core.Condition{BranchName: []string{"main", "release/*"}}, true, true},
+		{"non matching branch glob", &core.Condition{BranchName: []string{"main"}}, true, false},
+		{"matching changed glob", &core.Condition{Changed: []string{"packages/api/**"}}, true, true},
+		{"non matching changed glob", &core.Condition{Changed: []string{"packages/web/**"}}, true, false},
+		{"changed glob without diff", &core.Condition{Changed: []string{"packages/web/**"}}, false, true},
+		{
+			"all fields must match",
+			&core.Condition{EventType: []core.EventType{core.EventPullRequest}, Changed: []string{"packages/web/**"}},
+			true,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConditionContext(payload, diff, tt.diffExists, logger)
+			if got := c.matches(tt.condition); got != tt.want {
+				t.Errorf("conditionContext.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionContextFilterSubModules(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	payload := &core.Payload{EventType: core.EventPush, BranchName: "main"}
+	c := newConditionContext(payload, map[string]int{"packages/api/index.js": 1}, true, logger)
+
+	prerun := &core.Run{
+		Commands: []core.Step{
+			{Run: "yarn"},
+			{Run: "yarn lint", If: &core.Condition{EventType: []core.EventType{core.EventPullRequest}}},
+		},
+		EnvMap: map[string]string{"key": "value"},
+	}
+	subModules := []core.SubModule{
+		{Name: "api", Prerun: prerun, If: &core.Condition{Changed: []string{"packages/api/**"}}},
+		{Name: "web", Prerun: prerun, If: &core.Condition{Changed: []string{"packages/web/**"}}},
+	}
+	got := c.filterSubModules(subModules)
+	if len(got) != 1 || got[0].Name != "api" {
+		t.Fatalf("conditionContext.filterSubModules() = %+v, want only submodule api", got)
+	}
+	want := &core.Run{Commands: []core.Step{{Run: "yarn"}}, EnvMap: prerun.EnvMap}
+	if !reflect.DeepEqual(got[0].Prerun, want) {
+		t.Errorf("conditionContext.filterSubModules() prerun = %+v, want %+v", got[0].Prerun, want)
+	}
+	if len(subModules[0].Prerun.Commands) != 2 {
+		t.Errorf("conditionContext.filterSubModules() modified the given submodules")
+	}
+}
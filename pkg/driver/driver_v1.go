@@ -12,7 +12,6 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
-	"github.com/LambdaTest/test-at-scale/pkg/logwriter"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 	"github.com/LambdaTest/test-at-scale/pkg/utils"
 	"golang.org/x/sync/errgroup"
@@ -31,8 +30,11 @@ type (
 		ExecutionManager     core.ExecutionManager
 		TASConfigManager     core.TASConfigManager
 		CacheStore           core.CacheStore
+		ArtifactManager      core.ArtifactManager
 		DiffManager          core.DiffManager
 		ListSubModuleService core.ListSubModuleService
+		SecretParser         core.SecretParser
+		LogWriterFactory     core.LogWriterFactory
 		TASVersion           int
 		TASFilePath          string
 	}
@@ -46,17 +48,21 @@ type (
 
 func (d *driverV1) RunDiscovery(ctx context.Context, payload *core.Payload,
 	taskPayload *core.TaskPayload, oauth *core.Oauth, coverageDir string, secretMap map[string]string) error {
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldStage: core.Discovery})
 	tas, err := d.TASConfigManager.LoadAndValidate(ctx, d.TASVersion, d.TASFilePath, payload.EventType, payload.LicenseTier, d.TASFilePath)
 	if err != nil {
-		d.logger.Errorf("Unable to load tas yaml file, error: %v", err)
+		logger.Errorf("Unable to load tas yaml file, error: %v", err)
 		err = &errs.StatusFailed{Remark: err.Error()}
 		return err
 	}
 	tasConfig := tas.(*core.TASConfig)
+	addMaskPatterns(tasConfig.MaskPatterns, logger)
+	d.ExecutionManager.SetInheritEnv(tasConfig.InheritEnv)
+	secretMap = d.SecretParser.ApplySecretPolicy(secretMap, payload, tasConfig.SecretPolicy)
 	language := global.FrameworkLanguageMap[tasConfig.Framework]
 	setupResults, err := d.setUp(ctx, payload, tasConfig, oauth, language)
 	if err != nil {
-		d.logger.Errorf("Error while doing common opertations error %v", err)
+		logger.Errorf("Error while doing common opertations error %v", err)
 		return err
 	}
 
@@ -64,28 +70,31 @@ func (d *driverV1) RunDiscovery(ctx context.Context, payload *core.Payload,
 		return postErr
 	}
 
+	condition := newConditionContext(payload, setupResults.diff, setupResults.diffExists, logger)
+	tasConfig.Prerun = condition.filterSteps(tasConfig.Prerun)
 	if tasConfig.Prerun != nil {
-		d.logger.Infof("Running pre-run steps for top module")
-		azureLogWriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposePreRunLogs, d.logger)
-		err = d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload, tasConfig.Prerun, secretMap, azureLogWriter, global.RepoDir)
-		if err != nil {
-			d.logger.Errorf("Unable to run pre-run steps %v", err)
-			err = &errs.StatusFailed{Remark: "Failed in running pre-run steps"}
+		logger.Infof("Running pre-run steps for top module")
+		logWriter := d.LogWriterFactory.NewLogWriter(core.PurposePreRunLogs, payload)
+		stepResults, runErr := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload, tasConfig.Prerun,
+			secretMap, logWriter, global.RepoDir)
+		if runErr != nil {
+			logger.Errorf("Unable to run pre-run steps %v", runErr)
+			err = newStepsFailed("Failed in running pre-run steps", stepResults, runErr)
 			return err
 		}
 	}
 
-	err = d.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallRunners, global.InstallRunnerCmds, global.RepoDir, nil, nil)
+	_, err = d.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallRunners, global.InstallRunnerCmds, global.RepoDir, nil, nil)
 	if err != nil {
-		d.logger.Errorf("Unable to install custom runners %v", err)
+		logger.Errorf("Unable to install custom runners %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
 
-	d.logger.Debugf("Caching workspace")
+	logger.Debugf("Caching workspace")
 
 	if err = d.CacheStore.CacheWorkspace(ctx, ""); err != nil {
-		d.logger.Errorf("Error caching workspace: %+v", err)
+		logger.Errorf("Error caching workspace: %+v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
@@ -94,51 +103,60 @@ func (d *driverV1) RunDiscovery(ctx context.Context, payload *core.Payload,
 
 	discoveryResult, err := d.TestDiscoveryService.Discover(ctx, &args)
 	if err != nil {
-		d.logger.Errorf("Unable to perform test discovery: %+v", err)
+		logger.Errorf("Unable to perform test discovery: %+v", err)
 		err = &errs.StatusFailed{Remark: "Failed in discovering tests"}
 		return err
 	}
 
 	populateDiscovery(discoveryResult, tasConfig)
 	if err = d.TestDiscoveryService.SendResult(ctx, discoveryResult); err != nil {
-		d.logger.Errorf("error while sending discovery API call , error %v", err)
+		logger.Errorf("error while sending discovery API call , error %v", err)
 		return err
 	}
 	if language == languageJs {
 		if err = d.CacheStore.Upload(ctx, setupResults.cacheKey, tasConfig.Cache.Paths...); err != nil {
-			d.logger.Errorf("Unable to upload cache: %v", err)
+			logger.Errorf("Unable to upload cache: %v", err)
 			err = errs.New(errs.GenericErrRemark.Error())
 			return err
 		}
 	}
 
 	taskPayload.Status = core.Passed
-	d.logger.Debugf("Cache uploaded successfully")
+	logger.Debugf("Cache uploaded successfully")
 	return nil
 	// return nil
 }
 
 func (d *driverV1) RunExecution(ctx context.Context, payload *core.Payload,
 	taskPayload *core.TaskPayload, oauth *core.Oauth, coverageDir string, secretMap map[string]string) error {
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldStage: core.Execution})
 	tas, err := d.TASConfigManager.LoadAndValidate(ctx, 1, d.TASFilePath, payload.EventType, payload.LicenseTier, d.TASFilePath)
 	if err != nil {
-		d.logger.Errorf("Unable to load tas yaml file, error: %v", err)
+		logger.Errorf("Unable to load tas yaml file, error: %v", err)
 		err = &errs.StatusFailed{Remark: err.Error()}
 		return err
 	}
 	tasConfig := tas.(*core.TASConfig)
+	addMaskPatterns(tasConfig.MaskPatterns, logger)
+	d.ExecutionManager.SetInheritEnv(tasConfig.InheritEnv)
+	secretMap = d.SecretParser.ApplySecretPolicy(secretMap, payload, tasConfig.SecretPolicy)
 	if cachErr := d.setCache(tasConfig); cachErr != nil {
 		return cachErr
 	}
+	// artifacts are collected even if tests or postRun steps fail
+	defer uploadArtifacts(ctx, d.ArtifactManager, logger, taskPayload, payload.TaskID, tasConfig.Artifacts)
 	if errG := d.BlockTestService.GetBlockTests(ctx, tasConfig.Blocklist, payload.BranchName); errG != nil {
-		d.logger.Errorf("Unable to fetch blocklisted tests: %v", errG)
+		logger.Errorf("Unable to fetch blocklisted tests: %v", errG)
 		errG = errs.New(errs.GenericErrRemark.Error())
 		return errG
 	}
 	buildArgs := d.buildTestExecutionArgs(payload, tasConfig, secretMap, coverageDir)
 	executionResults, err := d.TestExecutionService.Run(ctx, &buildArgs)
+	if ctx.Err() != nil {
+		return sendPartialResults(ctx, d.TestExecutionService, logger, executionResults)
+	}
 	if err != nil {
-		d.logger.Infof("Unable to perform test execution: %v", err)
+		logger.Infof("Unable to perform test execution: %v", err)
 		err = &errs.StatusFailed{Remark: "Failed in executing tests."}
 		if executionResults == nil {
 			return err
@@ -147,20 +165,27 @@ func (d *driverV1) RunExecution(ctx context.Context, payload *core.Payload,
 
 	resp, err := d.TestExecutionService.SendResults(ctx, executionResults)
 	if err != nil {
-		d.logger.Errorf("error while sending test reports %v", err)
+		logger.Errorf("error while sending test reports %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
 
 	taskPayload.Status = resp.TaskStatus
-	logWriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposePostRunLogs, d.logger)
+	logWriter := d.LogWriterFactory.NewLogWriter(core.PurposePostRunLogs, payload)
+
+	condition, err := getConditionContext(ctx, d.DiffManager, payload, oauth, logger, tasConfig.Postrun)
+	if err != nil {
+		return err
+	}
+	tasConfig.Postrun = condition.filterSteps(tasConfig.Postrun)
 
 	if tasConfig.Postrun != nil {
-		d.logger.Infof("Running post-run steps")
-		err = d.ExecutionManager.ExecuteUserCommands(ctx, core.PostRun, payload, tasConfig.Postrun, secretMap, logWriter, global.RepoDir)
-		if err != nil {
-			d.logger.Errorf("Unable to run post-run steps %v", err)
-			err = &errs.StatusFailed{Remark: "Failed in running post-run steps."}
+		logger.Infof("Running post-run steps")
+		stepResults, runErr := d.ExecutionManager.ExecuteUserCommands(ctx, core.PostRun, payload, tasConfig.Postrun,
+			secretMap, logWriter, global.RepoDir)
+		if runErr != nil {
+			logger.Errorf("Unable to run post-run steps %v", runErr)
+			err = newStepsFailed("Failed in running post-run steps", stepResults, runErr)
 			return err
 		}
 	}
@@ -169,7 +194,8 @@ func (d *driverV1) RunExecution(ctx context.Context, payload *core.Payload,
 
 func (d *driverV1) setUp(ctx context.Context, payload *core.Payload,
 	tasConfig *core.TASConfig, oauth *core.Oauth, language string) (*setUpResultV1, error) {
-	d.logger.Infof("Tas yaml: %+v", tasConfig)
+	logger := lumber.FromContext(ctx, d.logger)
+	logger.Infof("Tas yaml: %+v", tasConfig)
 	if err := d.setCache(tasConfig); err != nil {
 		return nil, err
 	}
@@ -187,7 +213,7 @@ func (d *driverV1) setUp(ctx context.Context, payload *core.Payload,
 	}
 	blYml := tasConfig.Blocklist
 	if errG := d.BlockTestService.GetBlockTests(ctx, blYml, payload.BranchName); errG != nil {
-		d.logger.Errorf("Unable to fetch blocklisted tests: %v", errG)
+		logger.Errorf("Unable to fetch blocklisted tests: %v", errG)
 		errG = errs.New(errs.GenericErrRemark.Error())
 		return nil, errG
 	}
@@ -196,7 +222,7 @@ func (d *driverV1) setUp(ctx context.Context, payload *core.Payload,
 	if language == languageJs {
 		g.Go(func() error {
 			if errG := d.CacheStore.Download(errCtx, cacheKey); errG != nil {
-				d.logger.Errorf("Unable to download cache: %v", errG)
+				logger.Errorf("Unable to download cache: %v", errG)
 				errG = errs.New(errs.GenericErrRemark.Error())
 				return errG
 			}
@@ -204,7 +230,7 @@ func (d *driverV1) setUp(ctx context.Context, payload *core.Payload,
 		})
 	}
 
-	d.logger.Infof("Identifying changed files ...")
+	logger.Infof("Identifying changed files ...")
 	diffExists := true
 	diff := map[string]int{}
 	g.Go(func() error {
@@ -213,7 +239,7 @@ func (d *driverV1) setUp(ctx context.Context, payload *core.Payload,
 			if errors.Is(errG, errs.ErrGitDiffNotFound) {
 				diffExists = false
 			} else {
-				d.logger.Errorf("Unable to identify changed files %s", errG)
+				logger.Errorf("Unable to identify changed files %s", errG)
 				errG = errs.New("Error occurred in fetching diff from GitHub")
 				return errG
 			}
@@ -256,7 +282,7 @@ func (d *driverV1) buildTestExecutionArgs(payload *core.Payload, tasConfig *core
 	secretMap map[string]string,
 	coverageDir string) core.TestExecutionArgs {
 	testPattern, envMap := d.getEnvAndPattern(payload, tasConfig)
-	logWriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposeExecutionLogs, d.logger)
+	logWriter := d.LogWriterFactory.NewLogWriter(core.PurposeExecutionLogs, payload)
 	return core.TestExecutionArgs{
 		Payload:           payload,
 		CoverageDir:       coverageDir,
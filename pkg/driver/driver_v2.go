@@ -6,10 +6,12 @@ package driver
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -22,7 +24,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-const preRunLog = "Running Pre Run on Top level"
+const (
+	preRunLog   = "Running Pre Run on Top level"
+	nodeModules = "node_modules"
+)
 
 type (
 	driverV2 struct {
@@ -33,8 +38,11 @@ type (
 		ExecutionManager     core.ExecutionManager
 		TASConfigManager     core.TASConfigManager
 		CacheStore           core.CacheStore
+		ArtifactManager      core.ArtifactManager
 		DiffManager          core.DiffManager
 		ListSubModuleService core.ListSubModuleService
+		SecretParser         core.SecretParser
+		LogWriterFactory     core.LogWriterFactory
 		nodeInstaller        NodeInstaller
 		TestDiscoveryService core.TestDiscoveryService
 		TASVersion           int
@@ -50,27 +58,31 @@ type (
 
 func (d *driverV2) RunDiscovery(ctx context.Context, payload *core.Payload,
 	taskPayload *core.TaskPayload, oauth *core.Oauth, coverageDir string, secretMap map[string]string) error {
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldStage: core.Discovery})
 	// do something
-	d.logger.Debugf("Running in %d version", d.TASVersion)
+	logger.Debugf("Running in %d version", d.TASVersion)
 	tas, err := d.TASConfigManager.LoadAndValidate(ctx, d.TASVersion, d.TASFilePath, payload.EventType, payload.LicenseTier, d.TASFilePath)
 	if err != nil {
-		d.logger.Errorf("Unable to load tas yaml file, error: %v", err)
+		logger.Errorf("Unable to load tas yaml file, error: %v", err)
 		err = &errs.StatusFailed{Remark: err.Error()}
 		return err
 	}
 	tasConfig := tas.(*core.TASConfigV2)
+	addMaskPatterns(tasConfig.MaskPatterns, logger)
+	d.ExecutionManager.SetInheritEnv(tasConfig.InheritEnv)
+	secretMap = d.SecretParser.ApplySecretPolicy(secretMap, payload, tasConfig.SecretPolicy)
 	taskPayload.Status = core.Passed
 	setUpResult, err := d.setUpDiscovery(ctx, payload, tasConfig, oauth)
 	if err != nil {
 		return err
 	}
 	mainBuffer := new(bytes.Buffer)
-	azureLogWriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposePreRunLogs, d.logger)
+	logWriter := d.LogWriterFactory.NewStoreLogWriter(core.PurposePreRunLogs, payload)
 
 	defer func() {
-		if writeErr := <-azureLogWriter.Write(ctx, mainBuffer); writeErr != nil {
+		if writeErr := <-logWriter.Write(ctx, mainBuffer); writeErr != nil {
 			// error in writing log should not fail the build
-			d.logger.Errorf("error in writing pre run log, error %v", writeErr)
+			logger.Errorf("error in writing pre run log, error %v", writeErr)
 		}
 	}()
 
@@ -88,40 +100,61 @@ func (d *driverV2) RunDiscovery(ctx context.Context, payload *core.Payload,
 	}
 	if err = d.CacheStore.Upload(ctx, setUpResult.cacheKey, tasConfig.Cache.Paths...); err != nil {
 		// cache upload failure should not fail the task
-		d.logger.Errorf("Unable to upload cache: %v", err)
+		logger.Errorf("Unable to upload cache: %v", err)
 	}
-	d.logger.Debugf("Cache uploaded successfully")
+	logger.Debugf("Cache uploaded successfully")
 
 	return nil
 }
 
 func (d *driverV2) RunExecution(ctx context.Context, payload *core.Payload,
 	taskPayload *core.TaskPayload, oauth *core.Oauth, coverageDir string, secretMap map[string]string) error {
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldStage: core.Execution})
 	tas, err := d.TASConfigManager.LoadAndValidate(ctx, d.TASVersion, d.TASFilePath, payload.EventType, payload.LicenseTier, d.TASFilePath)
 	if err != nil {
-		d.logger.Errorf("Unable to load tas yaml file, error: %v", err)
+		logger.Errorf("Unable to load tas yaml file, error: %v", err)
 		err = &errs.StatusFailed{Remark: err.Error()}
 		return err
 	}
 
 	subModuleName := os.Getenv(global.SubModuleName)
 	tasConfig := tas.(*core.TASConfigV2)
+	addMaskPatterns(tasConfig.MaskPatterns, logger)
+	d.ExecutionManager.SetInheritEnv(tasConfig.InheritEnv)
+	secretMap = d.SecretParser.ApplySecretPolicy(secretMap, payload, tasConfig.SecretPolicy)
 	if cachErr := d.setCache(tasConfig); cachErr != nil {
 		return cachErr
 	}
 	subModule, err := d.findSubmodule(tasConfig, payload, subModuleName)
 	if err != nil {
-		d.logger.Errorf("Error finding sub module %s in tas config file", subModuleName)
+		logger.Errorf("Error finding sub module %s in tas config file", subModuleName)
 		return err
 	}
+	ctx, logger = lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldSubModule: subModule.Name})
+	// artifacts are collected even if tests or postRun steps fail
+	defer uploadArtifacts(ctx, d.ArtifactManager, logger, taskPayload, payload.TaskID, getArtifactPatterns(tasConfig, subModule))
 	// Get blocklist data before execution
 	blYML := subModule.Blocklist
 	if err = d.BlockTestService.GetBlockTests(ctx, blYML, payload.BranchName); err != nil {
-		d.logger.Errorf("Unable to fetch blocklisted tests: %v", err)
+		logger.Errorf("Unable to fetch blocklisted tests: %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
 
+	runs := []*core.Run{subModule.Postrun}
+	if subModule.RunPrerunEveryTime {
+		runs = append(runs, subModule.Prerun)
+	}
+	condition, err := getConditionContext(ctx, d.DiffManager, payload, oauth, logger, runs...)
+	if err != nil {
+		return err
+	}
+	subModule.Prerun = withMatrixEnv(condition.filterSteps(subModule.Prerun), subModule)
+	subModule.Postrun = withMatrixEnv(condition.filterSteps(subModule.Postrun), subModule)
+	if err := d.nodeInstaller.installMatrixNodeVersions(ctx, []core.SubModule{*subModule}); err != nil {
+		return err
+	}
+
 	modulePath := path.Join(global.RepoDir, subModule.Path)
 	// PRE RUN steps should be run only if RunPrerunEveryTime is set to true
 	if subModule.Prerun != nil && subModule.RunPrerunEveryTime {
@@ -131,25 +164,29 @@ func (d *driverV2) RunExecution(ctx context.Context, payload *core.Payload,
 	}
 	args := d.buildTestExecutionArgs(payload, tasConfig, subModule, secretMap, coverageDir)
 	testResult, err := d.TestExecutionService.Run(ctx, &args)
+	if ctx.Err() != nil {
+		return sendPartialResults(ctx, d.TestExecutionService, logger, testResult)
+	}
 	if err != nil {
 		return err
 	}
 	resp, err := d.TestExecutionService.SendResults(ctx, testResult)
 	if err != nil {
-		d.logger.Errorf("error while sending test reports %v", err)
+		logger.Errorf("error while sending test reports %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
 	taskPayload.Status = resp.TaskStatus
 
 	if subModule.Postrun != nil {
-		d.logger.Infof("Running post-run steps")
-		azureLogwriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposePostRunLogs, d.logger)
-
-		err = d.ExecutionManager.ExecuteUserCommands(ctx, core.PostRun, payload, subModule.Postrun, secretMap, azureLogwriter, modulePath)
-		if err != nil {
-			d.logger.Errorf("Unable to run post-run steps %v", err)
-			err = &errs.StatusFailed{Remark: "Failed in running post-run steps."}
+		logger.Infof("Running post-run steps")
+		logWriter := d.LogWriterFactory.NewLogWriter(core.PurposePostRunLogs, payload)
+
+		stepResults, runErr := d.ExecutionManager.ExecuteUserCommands(ctx, core.PostRun, payload, subModule.Postrun,
+			secretMap, logWriter, modulePath)
+		if runErr != nil {
+			logger.Errorf("Unable to run post-run steps %v", runErr)
+			err = newStepsFailed("Failed in running post-run steps", stepResults, runErr)
 			return err
 		}
 	}
@@ -162,26 +199,28 @@ func (d *driverV2) runPreRunBeforeTestExecution(ctx context.Context,
 	payload *core.Payload,
 	secretMap map[string]string,
 	modulePath string) error {
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldSubModule: subModule.Name})
 	if tasConfig.NodeVersion != "" {
 		// install node version before preRuns
 		if err := d.nodeInstaller.InstallNodeVersion(ctx, tasConfig.NodeVersion); err != nil {
-			d.logger.Debugf("error while installing node of version %s, error %v ", tasConfig.NodeVersion, err)
+			logger.Debugf("error while installing node of version %s, error %v ", tasConfig.NodeVersion, err)
 			return err
 		}
 	}
 
-	d.logger.Infof("Running pre-run steps for submodule %s", subModule.Name)
-	azureLogwriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposePreRunLogs, d.logger)
-	err := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload, subModule.Prerun, secretMap, azureLogwriter, modulePath)
+	logger.Infof("Running pre-run steps for submodule %s", subModule.Name)
+	logWriter := d.LogWriterFactory.NewLogWriter(core.PurposePreRunLogs, payload)
+	stepResults, err := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload, subModule.Prerun,
+		secretMap, logWriter, modulePath)
 	if err != nil {
-		d.logger.Errorf("Unable to run pre-run steps %v", err)
-		err = &errs.StatusFailed{Remark: "Failed in running pre-run steps"}
+		logger.Errorf("Unable to run pre-run steps %v", err)
+		err = newStepsFailed("Failed in running pre-run steps", stepResults, err)
 		return err
 	}
-	d.logger.Debugf("installing runners at path %s", modulePath)
-	if err = d.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallRunners, global.InstallRunnerCmds,
+	logger.Debugf("installing runners at path %s", modulePath)
+	if _, err = d.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallRunners, global.InstallRunnerCmds,
 		modulePath, nil, nil); err != nil {
-		d.logger.Errorf("Unable to install custom runners %v", err)
+		logger.Errorf("Unable to install custom runners %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
@@ -198,6 +237,13 @@ func (d *driverV2) runDiscoveryHelper(ctx context.Context,
 	diffExists bool,
 	mainBuffer *bytes.Buffer,
 	secretMap map[string]string) error {
+	logger := lumber.FromContext(ctx, d.logger)
+	condition := newConditionContext(payload, diff, diffExists, logger)
+	subModuleList = condition.filterSubModules(subModuleList)
+	topPreRun = condition.filterSteps(topPreRun)
+	for i := range subModuleList {
+		subModuleList[i].Prerun = withMatrixEnv(subModuleList[i].Prerun, &subModuleList[i])
+	}
 	totalSubmoduleCount := len(subModuleList)
 	if apiErr := d.ListSubModuleService.Send(ctx, payload.BuildID, totalSubmoduleCount); apiErr != nil {
 		return apiErr
@@ -208,15 +254,11 @@ func (d *driverV2) runDiscoveryHelper(ctx context.Context,
 			return err
 		}
 	}
-
-	if err := d.runPreRunCommand(ctx, topPreRun, mainBuffer, payload, secretMap, taskPayload, subModuleList); err != nil {
+	if err := d.nodeInstaller.installMatrixNodeVersions(ctx, subModuleList); err != nil {
 		return err
 	}
-	d.logger.Debugf("Caching workspace")
-	// TODO: this will be change after we move to parallel pod executuon
-	if err := d.CacheStore.CacheWorkspace(ctx, ""); err != nil {
-		d.logger.Errorf("Error caching workspace: %+v", err)
-		err = errs.New(errs.GenericErrRemark.Error())
+
+	if err := d.runPreRunCommand(ctx, topPreRun, mainBuffer, payload, secretMap, taskPayload, subModuleList); err != nil {
 		return err
 	}
 
@@ -240,67 +282,131 @@ func (d *driverV2) runDiscoveryHelper(ctx context.Context,
 	return nil
 }
 
+// cacheWorkspace caches a separate workspace for the submodule, so that execution pods
+// only extract the paths required by their own submodule.
+func (d *driverV2) cacheWorkspace(ctx context.Context, subModule *core.SubModule, subModuleList []core.SubModule) error {
+	logger := lumber.FromContext(ctx, d.logger)
+	excludePaths := getWorkspaceExcludePaths(global.RepoDir, subModule, subModuleList)
+	logger.Debugf("Caching workspace for submodule %s, excluding paths %v", subModule.Name, excludePaths)
+	if err := d.CacheStore.CacheWorkspace(ctx, subModule.Name, excludePaths...); err != nil {
+		logger.Errorf("Error caching workspace: %+v", err)
+		return errs.New(errs.GenericErrRemark.Error())
+	}
+	return nil
+}
+
 func (d *driverV2) runPreRunCommand(ctx context.Context,
 	topPreRun *core.Run,
 	mainBuffer *bytes.Buffer, payload *core.Payload,
 	secretMap map[string]string, taskPayload *core.TaskPayload,
 	subModuleList []core.SubModule) error {
+	logger := lumber.FromContext(ctx, d.logger)
 	totalSubmoduleCount := len(subModuleList)
 
-	errChannelPreRun := make(chan error, totalSubmoduleCount)
-
-	preRunWaitGroup := sync.WaitGroup{}
-
 	if topPreRun != nil {
-		d.logger.Debugf("Running Pre Run on top level")
+		logger.Debugf("Running Pre Run on top level")
 		if _, err := mainBuffer.WriteString(preRunLog); err != nil {
 			return err
 		}
-		bufferWirter := logwriter.NewBufferLogWriter("TOP-LEVEL", mainBuffer, d.logger)
-		if err := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload,
+		bufferWirter := d.LogWriterFactory.WithLogStream(logwriter.NewBufferLogWriter("TOP-LEVEL", mainBuffer, logger),
+			core.PurposePreRunLogs, payload)
+		if stepResults, err := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload,
 			topPreRun, secretMap, bufferWirter, global.RepoDir); err != nil {
-			d.logger.Errorf("Error occurred running top level PreRun , err %v", err)
-			return err
+			logger.Errorf("Error occurred running top level PreRun , err %v", err)
+			return newStepsFailed("Failed in running top level pre-run steps", stepResults, err)
 		}
 	}
 
-	bufferList := []*bytes.Buffer{}
-
-	d.logger.Debugf("pre run on top level ended")
-	for i := 0; i < totalSubmoduleCount; i++ {
-		preRunWaitGroup.Add(1)
-
-		newBuffer := new(bytes.Buffer)
-
-		bufferList = append(bufferList, newBuffer)
-
-		go func(subModule *core.SubModule) {
-			defer preRunWaitGroup.Done()
-			bufferWirterSubmodule := logwriter.NewBufferLogWriter(subModule.Name, newBuffer, d.logger)
-			dicoveryErr := d.runPreRunForEachSubModule(ctx, payload, subModule, secretMap, bufferWirterSubmodule)
-			if dicoveryErr != nil {
-				taskPayload.Status = core.Error
-				d.logger.Errorf("error while running discovery for sub module %s, error %v", subModule.Name, dicoveryErr)
+	logger.Debugf("pre run on top level ended")
+	bufferList := make([]*bytes.Buffer, totalSubmoduleCount)
+	for i := range bufferList {
+		bufferList[i] = new(bytes.Buffer)
+	}
+	defer func() {
+		for i := 0; i < totalSubmoduleCount; i++ {
+			mainBuffer.WriteString(bufferList[i].String())
+		}
+	}()
+	// the matrix variants of a submodule share its path, so they are run in rounds of one variant per path and the
+	// workspace of each variant is cached before the pre-run steps of the next one change it. The workspaces are
+	// cached once all the pre-run steps of a round are done, as the submodules share the root and HOME.
+	for _, indexes := range preRunRounds(groupByModulePath(subModuleList)) {
+		err := runInParallel(indexes, func(i int) error {
+			subModule := &subModuleList[i]
+			bufferWirterSubmodule := d.LogWriterFactory.WithLogStream(
+				logwriter.NewBufferLogWriter(subModule.Name, bufferList[i], logger), core.PurposePreRunLogs, payload)
+			if err := d.runPreRunForEachSubModule(ctx, payload, subModule, secretMap, bufferWirterSubmodule); err != nil {
+				logger.Errorf("error while running discovery for sub module %s, error %v", subModule.Name, err)
+				return err
 			}
-			errChannelPreRun <- dicoveryErr
-		}(&subModuleList[i])
+			return nil
+		})
+		if err != nil {
+			taskPayload.Status = core.Error
+			logger.Debugf("pre run failed with error %v", err)
+			return err
+		}
+		if err := runInParallel(indexes, func(i int) error {
+			return d.cacheWorkspace(ctx, &subModuleList[i], subModuleList)
+		}); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	preRunWaitGroup.Wait()
-
-	for i := 0; i < totalSubmoduleCount; i++ {
-		mainBuffer.WriteString(bufferList[i].String())
+// preRunRounds returns the rounds the pre-run steps of the submodules grouped by path are run in, the n-th round
+// holds the n-th submodule of each group
+func preRunRounds(pathGroups [][]int) [][]int {
+	rounds := [][]int{}
+	for _, group := range pathGroups {
+		for round, i := range group {
+			if round == len(rounds) {
+				rounds = append(rounds, nil)
+			}
+			rounds[round] = append(rounds[round], i)
+		}
 	}
-	for i := 0; i < totalSubmoduleCount; i++ {
-		e := <-errChannelPreRun
-		if e != nil {
-			d.logger.Debugf("pre run failed with error %v", e)
-			return e
+	return rounds
+}
+
+// runInParallel calls fn for each of indexes concurrently, it returns the first error in the order of indexes
+func runInParallel(indexes []int, fn func(i int) error) error {
+	errList := make([]error, len(indexes))
+	wg := sync.WaitGroup{}
+	for j, i := range indexes {
+		wg.Add(1)
+		go func(j, i int) {
+			defer wg.Done()
+			errList[j] = fn(i)
+		}(j, i)
+	}
+	wg.Wait()
+	for _, err := range errList {
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// groupByModulePath returns the indexes of the submodules grouped by their path, in the order of subModuleList
+func groupByModulePath(subModuleList []core.SubModule) [][]int {
+	groups := [][]int{}
+	groupIndex := map[string]int{}
+	for i := range subModuleList {
+		modulePath := cleanModulePath(subModuleList[i].Path)
+		j, ok := groupIndex[modulePath]
+		if !ok {
+			j = len(groups)
+			groupIndex[modulePath] = j
+			groups = append(groups, nil)
+		}
+		groups[j] = append(groups[j], i)
+	}
+	return groups
+}
+
 func (d *driverV2) runDiscoveryForEachSubModule(ctx context.Context,
 	payload *core.Payload,
 	subModule *core.SubModule,
@@ -308,11 +414,12 @@ func (d *driverV2) runDiscoveryForEachSubModule(ctx context.Context,
 	diff map[string]int,
 	diffExists bool,
 	secretMap map[string]string) error {
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldSubModule: subModule.Name})
 	args := d.buildDiscoveryArgs(payload, tasConfig, subModule, secretMap, diffExists, diff)
 
 	discoveryResult, err := d.TestDiscoveryService.Discover(ctx, &args)
 	if err != nil {
-		d.logger.Errorf("Unable to perform test discovery: %+v", err)
+		logger.Errorf("Unable to perform test discovery: %+v", err)
 		err = &errs.StatusFailed{Remark: "Failed in discovering tests"}
 		return err
 	}
@@ -328,29 +435,29 @@ func (d *driverV2) runPreRunForEachSubModule(ctx context.Context,
 	subModule *core.SubModule,
 	secretMap map[string]string,
 	bufferWirterSubmodule core.LogWriterStrategy) error {
-	d.logger.Debugf("Running discovery for sub module %s", subModule.Name)
+	ctx, logger := lumber.ContextWithFields(ctx, d.logger, lumber.Fields{lumber.FieldSubModule: subModule.Name})
+	logger.Debugf("Running discovery for sub module %s", subModule.Name)
 	blYML := subModule.Blocklist
 	if err := d.BlockTestService.GetBlockTests(ctx, blYML, payload.BranchName); err != nil {
-		d.logger.Errorf("Unable to fetch blocklisted tests: %v", err)
+		logger.Errorf("Unable to fetch blocklisted tests: %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
 	modulePath := path.Join(global.RepoDir, subModule.Path)
 	// PRE RUN steps
 	if subModule.Prerun != nil {
-		d.logger.Infof("Running pre-run steps for submodule %s", subModule.Name)
-		err := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload, subModule.Prerun,
+		logger.Infof("Running pre-run steps for submodule %s", subModule.Name)
+		stepResults, err := d.ExecutionManager.ExecuteUserCommands(ctx, core.PreRun, payload, subModule.Prerun,
 			secretMap, bufferWirterSubmodule, modulePath)
 		if err != nil {
-			d.logger.Errorf("Unable to run pre-run steps %v", err)
-			err = &errs.StatusFailed{Remark: "Failed in running pre-run steps"}
-			return err
+			logger.Errorf("Unable to run pre-run steps %v", err)
+			return newStepsFailed("Failed in running pre-run steps", stepResults, err)
 		}
-		d.logger.Debugf("error checks end")
+		logger.Debugf("error checks end")
 	}
-	err := d.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallRunners, global.InstallRunnerCmds, modulePath, nil, nil)
+	_, err := d.ExecutionManager.ExecuteInternalCommands(ctx, core.InstallRunners, global.InstallRunnerCmds, modulePath, nil, nil)
 	if err != nil {
-		d.logger.Errorf("Unable to install custom runners %v", err)
+		logger.Errorf("Unable to install custom runners %v", err)
 		err = errs.New(errs.GenericErrRemark.Error())
 		return err
 	}
@@ -362,6 +469,7 @@ func (d *driverV2) setUpDiscovery(ctx context.Context,
 	payload *core.Payload,
 	tasConfig *core.TASConfigV2,
 	oauth *core.Oauth) (*setUpResultV2, error) {
+	logger := lumber.FromContext(ctx, d.logger)
 	if err := d.setCache(tasConfig); err != nil {
 		return nil, err
 	}
@@ -370,7 +478,7 @@ func (d *driverV2) setUpDiscovery(ctx context.Context,
 	g, errCtx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		if errG := d.CacheStore.Download(errCtx, cacheKey); errG != nil {
-			d.logger.Errorf("Unable to download cache: %v", errG)
+			logger.Errorf("Unable to download cache: %v", errG)
 			errG = errs.New(errs.GenericErrRemark.Error())
 			return errG
 		}
@@ -384,7 +492,7 @@ func (d *driverV2) setUpDiscovery(ctx context.Context,
 			if errors.Is(errG, errs.ErrGitDiffNotFound) {
 				diffExists = false
 			} else {
-				d.logger.Errorf("Unable to identify changed files %s", errG)
+				logger.Errorf("Unable to identify changed files %s", errG)
 				errG = errs.New("Error occurred in fetching diff from GitHub")
 				return errG
 			}
@@ -427,14 +535,16 @@ func (d *driverV2) buildDiscoveryArgs(payload *core.Payload, tasConfig *core.TAS
 }
 
 func getEnv(payload *core.Payload, tasConfig *core.TASConfigV2, subModule *core.SubModule) map[string]string {
-	var envMap map[string]string
+	var mergeEnvMap map[string]string
 	if payload.EventType == core.EventPullRequest {
-		envMap = tasConfig.PreMerge.EnvMap
+		mergeEnvMap = tasConfig.PreMerge.EnvMap
 	} else {
-		envMap = tasConfig.PostMerge.EnvMap
+		mergeEnvMap = tasConfig.PostMerge.EnvMap
 	}
-	if envMap == nil {
-		envMap = map[string]string{}
+	// copy the env, as submodules are discovered concurrently
+	envMap := make(map[string]string, len(mergeEnvMap))
+	for k, v := range mergeEnvMap {
+		envMap[k] = v
 	}
 
 	// overwrite the existing env with more specific one
@@ -443,6 +553,9 @@ func getEnv(payload *core.Payload, tasConfig *core.TASConfigV2, subModule *core.
 			envMap[k] = v
 		}
 	}
+	for k, v := range getMatrixEnv(subModule) {
+		envMap[k] = v
+	}
 	if path.Join(global.RepoDir, subModule.Path) == global.RepoDir {
 		envMap[global.ModulePath] = ""
 	} else {
@@ -455,6 +568,7 @@ func populateTestDiscoveryV2(testDiscoveryResult *core.DiscoveryResult, subModul
 	testDiscoveryResult.Parallelism = subModule.Parallelism
 	testDiscoveryResult.SplitMode = tasConfig.SplitMode
 	testDiscoveryResult.SubModule = subModule.Name
+	testDiscoveryResult.Matrix = subModule.MatrixValues
 }
 
 func (d *driverV2) findSubmodule(tasConfig *core.TASConfigV2, payload *core.Payload, subModuleName string) (*core.SubModule, error) {
@@ -483,17 +597,18 @@ func (d *driverV2) buildTestExecutionArgs(payload *core.Payload,
 	envMap := getEnv(payload, tasConfig, subModule)
 	modulePath := path.Join(global.RepoDir, subModule.Path)
 
-	azureLogWriter := logwriter.NewAzureLogWriter(d.AzureClient, core.PurposeExecutionLogs, d.logger)
+	logWriter := d.LogWriterFactory.NewLogWriter(core.PurposeExecutionLogs, payload)
 	return core.TestExecutionArgs{
 		Payload:           payload,
 		CoverageDir:       coverageDir,
-		LogWriterStrategy: azureLogWriter,
+		LogWriterStrategy: logWriter,
 		TestPattern:       target,
 		EnvMap:            envMap,
 		TestConfigFile:    subModule.ConfigFile,
 		FrameWork:         subModule.Framework,
 		SecretData:        secretMap,
 		CWD:               modulePath,
+		Matrix:            subModule.MatrixValues,
 	}
 }
 
@@ -526,3 +641,95 @@ func (d *driverV2) setCache(tasConfig *core.TASConfigV2) error {
 	}
 	return nil
 }
+
+// getWorkspaceExcludePaths returns the paths of other submodules which are not required by subModule.
+// Paths enclosing or enclosed by the submodule path and workspace packages the submodule
+// depends upon through node_modules are never excluded.
+func getWorkspaceExcludePaths(repoDir string, subModule *core.SubModule, subModuleList []core.SubModule) []string {
+	modulePath := cleanModulePath(subModule.Path)
+	candidates := []string{}
+	for i := 0; i < len(subModuleList); i++ {
+		otherPath := cleanModulePath(subModuleList[i].Path)
+		if isWithinPath(modulePath, otherPath) || isWithinPath(otherPath, modulePath) {
+			continue
+		}
+		candidates = append(candidates, otherPath)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	required := map[string]bool{}
+	visited := map[string]bool{modulePath: true}
+	queue := []string{modulePath}
+	for len(queue) > 0 {
+		pkgDir := queue[0]
+		queue = queue[1:]
+		for _, target := range getLinkedPackagePaths(repoDir, pkgDir) {
+			for _, candidate := range candidates {
+				if isWithinPath(candidate, target) {
+					required[candidate] = true
+				}
+			}
+			if !visited[target] {
+				visited[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	excludePaths := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !required[candidate] {
+			excludePaths = append(excludePaths, candidate)
+		}
+	}
+	return excludePaths
+}
+
+// getLinkedPackagePaths returns the repo relative paths of the dependencies of the package at pkgDir
+// which are symlinked into node_modules, as done by yarn, npm and pnpm workspaces.
+func getLinkedPackagePaths(repoDir, pkgDir string) []string {
+	content, err := os.ReadFile(filepath.Join(repoDir, pkgDir, global.PackageJSON))
+	if err != nil {
+		return nil
+	}
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil
+	}
+	linked := []string{}
+	for _, depType := range []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"} {
+		deps := map[string]string{}
+		if err := json.Unmarshal(pkg[depType], &deps); err != nil {
+			continue
+		}
+		for name := range deps {
+			for _, nodeModulesDir := range []string{filepath.Join(repoDir, pkgDir, nodeModules), filepath.Join(repoDir, nodeModules)} {
+				depPath := filepath.Join(nodeModulesDir, name)
+				info, err := os.Lstat(depPath)
+				if err != nil {
+					continue
+				}
+				if info.Mode()&os.ModeSymlink != 0 {
+					if target, err := filepath.EvalSymlinks(depPath); err == nil {
+						if rel, err := filepath.Rel(repoDir, target); err == nil && !strings.HasPrefix(rel, "..") {
+							linked = append(linked, rel)
+						}
+					}
+				}
+				break
+			}
+		}
+	}
+	return linked
+}
+
+func cleanModulePath(modulePath string) string {
+	return filepath.Clean(strings.TrimPrefix(modulePath, "./"))
+}
+
+// isWithinPath reports whether child is parent or lies inside it
+func isWithinPath(parent, child string) bool {
+	return parent == "." || parent == child || strings.HasPrefix(child, parent+string(filepath.Separator))
+}
@@ -1,8 +1,12 @@
 package driver
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
 )
 
 type testArgs struct {
@@ -106,3 +110,64 @@ func TestGetSubmoduleBasedDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWorkspaceExcludePaths(t *testing.T) {
+	repoDir := t.TempDir()
+	for _, dir := range []string{"packages/api", "packages/web", "packages/shared", "packages/api/node_modules/@org", "tools"} {
+		if err := os.MkdirAll(filepath.Join(repoDir, dir), 0755); err != nil {
+			t.Fatalf("failed to create directory %s, error: %v", dir, err)
+		}
+	}
+	packageJSON := `{"dependencies": {"@org/shared": "1.0.0", "lodash": "4.17.21"}}`
+	if err := os.WriteFile(filepath.Join(repoDir, "packages/api/package.json"), []byte(packageJSON), 0644); err != nil {
+		t.Fatalf("failed to write package.json, error: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(repoDir, "packages/shared"),
+		filepath.Join(repoDir, "packages/api/node_modules/@org/shared")); err != nil {
+		t.Fatalf("failed to create symlink, error: %v", err)
+	}
+
+	subModules := []core.SubModule{
+		{Name: "root", Path: "."},
+		{Name: "api", Path: "./packages/api"},
+		{Name: "web", Path: "packages/web/"},
+		{Name: "shared", Path: "packages/shared"},
+		{Name: "tools", Path: "tools"},
+	}
+	tests := []struct {
+		name      string
+		subModule *core.SubModule
+		want      []string
+	}{
+		{"root submodule excludes nothing", &subModules[0], nil},
+		{"linked workspace package is kept", &subModules[1], []string{"packages/web", "tools"}},
+		{"submodule without dependencies", &subModules[2], []string{"packages/api", "packages/shared", "tools"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getWorkspaceExcludePaths(repoDir, tt.subModule, subModules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getWorkspaceExcludePaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByModulePath(t *testing.T) {
+	subModules := []core.SubModule{
+		{Name: "api (node: 14)", Path: "./packages/api"},
+		{Name: "web", Path: "packages/web"},
+		{Name: "api (node: 16)", Path: "packages/api/"},
+	}
+	want := [][]int{{0, 2}, {1}}
+	if got := groupByModulePath(subModules); !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByModulePath() = %v, want %v", got, want)
+	}
+}
+
+func TestPreRunRounds(t *testing.T) {
+	want := [][]int{{0, 1, 4}, {2, 3}, {5}}
+	if got := preRunRounds([][]int{{0, 2, 5}, {1}, {4, 3}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("preRunRounds() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,17 @@
+package driver
+
+import (
+	"github.com/LambdaTest/test-at-scale/pkg/logstream"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// addMaskPatterns registers the user defined patterns of credentials to be masked in the logs
+func addMaskPatterns(patterns []string, logger lumber.Logger) {
+	if len(patterns) == 0 {
+		return
+	}
+	// patterns are validated while loading the tas config, so this is not expected to fail
+	if err := logstream.AddPatterns(patterns...); err != nil {
+		logger.Errorf("failed to add mask patterns, error: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+)
+
+// getMatrixEnv returns the env of a matrix variant, the `node` value selects the node version through PATH
+func getMatrixEnv(subModule *core.SubModule) map[string]string {
+	envMap := make(map[string]string, len(subModule.MatrixValues))
+	for k, v := range subModule.MatrixValues {
+		if k == global.MatrixNodeKey {
+			envMap["PATH"] = fmt.Sprintf("%s:%s", nodeBinPath(v), os.Getenv("PATH"))
+			continue
+		}
+		envMap[k] = v
+	}
+	return envMap
+}
+
+// withMatrixEnv returns a copy of run with the env of the matrix variant added
+func withMatrixEnv(run *core.Run, subModule *core.SubModule) *core.Run {
+	if run == nil || len(subModule.MatrixValues) == 0 {
+		return run
+	}
+	envMap := make(map[string]string, len(run.EnvMap)+len(subModule.MatrixValues))
+	for k, v := range run.EnvMap {
+		envMap[k] = v
+	}
+	for k, v := range getMatrixEnv(subModule) {
+		envMap[k] = v
+	}
+	return &core.Run{Commands: run.Commands, EnvMap: envMap}
+}
+
+// installMatrixNodeVersions installs the node versions used by the matrix variants
+func (n *NodeInstaller) installMatrixNodeVersions(ctx context.Context, subModuleList []core.SubModule) error {
+	installed := map[string]bool{}
+	for i := range subModuleList {
+		nodeVersion, ok := subModuleList[i].MatrixValues[global.MatrixNodeKey]
+		if !ok || installed[nodeVersion] {
+			continue
+		}
+		if err := n.installNode(ctx, nodeVersion); err != nil {
+			return err
+		}
+		installed[nodeVersion] = true
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package driver
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+)
+
+func TestWithMatrixEnv(t *testing.T) {
+	subModule := &core.SubModule{
+		Name:         "api[DB=mysql,node=18.17.0]",
+		MatrixValues: map[string]string{"DB": "mysql", "node": "18.17.0"},
+	}
+	run := &core.Run{Commands: []core.Step{{Run: "yarn"}}, EnvMap: map[string]string{"DB": "sqlite", "CI": "true"}}
+
+	got := withMatrixEnv(run, subModule)
+	want := map[string]string{
+		"CI":   "true",
+		"DB":   "mysql",
+		"PATH": "/home/nucleus/.nvm/versions/node/v18.17.0/bin:" + os.Getenv("PATH"),
+	}
+	if !reflect.DeepEqual(got.EnvMap, want) {
+		t.Errorf("withMatrixEnv() env = %v, want %v", got.EnvMap, want)
+	}
+	if run.EnvMap["DB"] != "sqlite" {
+		t.Errorf("withMatrixEnv() modified the given run")
+	}
+	if got := withMatrixEnv(run, &core.SubModule{Name: "api"}); got != run {
+		t.Errorf("withMatrixEnv() = %v, want the given run for submodule without matrix", got)
+	}
+	if got := withMatrixEnv(nil, subModule); got != nil {
+		t.Errorf("withMatrixEnv() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,27 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// sendPartialResults reports the results received before the task was aborted, within the grace period of the task.
+// It returns the cancellation of ctx, so that the task is marked aborted.
+func sendPartialResults(ctx context.Context,
+	testExecutionService core.TestExecutionService,
+	logger lumber.Logger,
+	executionResults *core.ExecutionResults) error {
+	if executionResults == nil {
+		return ctx.Err()
+	}
+	graceCtx, cancel := core.GraceContext(ctx)
+	defer cancel()
+	executionResults.Status = core.Aborted
+	logger.Infof("Sending partial results of %d test files", len(executionResults.Results))
+	if _, err := testExecutionService.SendResults(graceCtx, executionResults); err != nil {
+		logger.Errorf("error while sending partial test reports %v", err)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,57 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/mocks"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendPartialResults(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialize logger, error: %v", err)
+	}
+	tests := []struct {
+		name    string
+		results *core.ExecutionResults
+		sendErr error
+		sent    bool
+	}{
+		{"no results flushed before abort", nil, nil, false},
+		{"partial results", &core.ExecutionResults{TaskID: "task", Results: []core.ExecutionResult{{}}}, nil, true},
+		{"partial results not delivered", &core.ExecutionResults{TaskID: "task"}, errors.New("connection refused"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(core.ContextWithGracePeriod(context.Background(), time.Minute))
+			cancel()
+			tes := new(mocks.TestExecutionService)
+			tes.On("SendResults", mock.Anything, mock.Anything).Return(
+				func(ctx context.Context, payload *core.ExecutionResults) *core.TestReportResponsePayload {
+					if ctx.Err() != nil {
+						t.Errorf("SendResults() called with canceled context, want the grace period context")
+					}
+					if payload.Status != core.Aborted {
+						t.Errorf("SendResults() status = %v, want %v", payload.Status, core.Aborted)
+					}
+					return &core.TestReportResponsePayload{TaskID: payload.TaskID, TaskStatus: core.Aborted}
+				},
+				tt.sendErr)
+
+			if err := sendPartialResults(ctx, tes, logger, tt.results); !errors.Is(err, context.Canceled) {
+				t.Errorf("sendPartialResults() error = %v, want %v", err, context.Canceled)
+			}
+			if tt.sent {
+				tes.AssertNumberOfCalls(t, "SendResults", 1)
+			} else {
+				tes.AssertNotCalled(t, "SendResults", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+)
+
+// newStepsFailed returns the error for failed user commands. The remark of errors to be fixed by
+// the user, like referencing a secret withheld by the secret policy, is added to the remark.
+func newStepsFailed(remark string, steps []*core.StepResult, err error) error {
+	var statusFailed *errs.StatusFailed
+	if errors.As(err, &statusFailed) {
+		return &core.StepsFailed{Remark: fmt.Sprintf("%s: %s", remark, statusFailed.Remark), Steps: steps}
+	}
+	return core.NewStepsFailed(remark, steps, err)
+}
@@ -0,0 +1,102 @@
+// Package envelope encrypts the secrets stored at rest in the synapse configuration
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+)
+
+// Prefix marks the encrypted values, which hold the base64 encoded nonce followed by the AES-256-GCM sealed value.
+const Prefix = "enc:v1:"
+
+const keySize = 32
+
+var (
+	// ErrKeyNotFound is returned when neither a key file nor a key is configured
+	ErrKeyNotFound = fmt.Errorf("secrets key not found, set the %s or %s env variable", global.SecretsKeyFileEnv, global.SecretsKeyEnv)
+	// ErrDecrypt is returned when a value cannot be decrypted with the key
+	ErrDecrypt = errors.New("failed to decrypt value, the key is wrong or the value is corrupted")
+)
+
+// IsEncrypted reports whether value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// LoadKey loads the base64 encoded 256 bit key from keyFile, or from the file in the SYN_SECRETS_KEY_FILE
+// env variable if keyFile is empty, and then from the SYN_SECRETS_KEY env variable.
+func LoadKey(keyFile string) ([]byte, error) {
+	if keyFile == "" {
+		keyFile = os.Getenv(global.SecretsKeyFileEnv)
+	}
+	encodedKey := os.Getenv(global.SecretsKeyEnv)
+	if keyFile != "" {
+		body, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encodedKey = string(body)
+	}
+	if encodedKey == "" {
+		return nil, ErrKeyNotFound
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, fmt.Errorf("secrets key is not base64 encoded: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("secrets key is %d bytes long, expected %d bytes", len(key), keySize)
+	}
+	return key, nil
+}
+
+// Encrypt returns the encrypted form of value
+func Encrypt(key []byte, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return Prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of the encrypted value
+func Decrypt(key []byte, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("value is not encrypted, it must start with %s", Prefix)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", ErrDecrypt
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
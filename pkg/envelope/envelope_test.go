@@ -0,0 +1,84 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+)
+
+func testKey(b byte) []byte {
+	key := make([]byte, keySize)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := testKey(1)
+	for _, value := range []string{"", "token", "multi\nline secret"} {
+		encrypted, err := Encrypt(key, value)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		if !IsEncrypted(encrypted) || strings.Contains(encrypted, value) && value != "" {
+			t.Errorf("Encrypt() = %s, want encrypted value", encrypted)
+		}
+		got, err := Decrypt(key, encrypted)
+		if err != nil || got != value {
+			t.Errorf("Decrypt() = %q, %v, want %q", got, err, value)
+		}
+	}
+
+	encrypted, _ := Encrypt(key, "token")
+	if _, err := Decrypt(testKey(2), encrypted); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() with wrong key error = %v, want %v", err, ErrDecrypt)
+	}
+	tampered := encrypted[:len(encrypted)-2] + "AA"
+	if _, err := Decrypt(key, tampered); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Decrypt() of tampered value error = %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := Decrypt(key, "token"); err == nil {
+		t.Errorf("Decrypt() of plaintext value, want error")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	encodedKey := base64.StdEncoding.EncodeToString(testKey(1))
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(encodedKey+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		keyFile string
+		fileEnv string
+		keyEnv  string
+		wantErr bool
+	}{
+		{"Test key file", keyFile, "", "", false},
+		{"Test key file env", "", keyFile, "", false},
+		{"Test key env", "", "", encodedKey, false},
+		{"Test no key", "", "", "", true},
+		{"Test short key", "", "", base64.StdEncoding.EncodeToString([]byte("short")), true},
+		{"Test invalid key", "", "", "not base64!", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(global.SecretsKeyFileEnv, tt.fileEnv)
+			t.Setenv(global.SecretsKeyEnv, tt.keyEnv)
+			key, err := LoadKey(tt.keyFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(key) != string(testKey(1)) {
+				t.Errorf("LoadKey() = %v, want %v", key, testKey(1))
+			}
+		})
+	}
+}
@@ -34,6 +34,11 @@ func ErrInvalidPayload(errMsg string) error {
 	return New(errMsg)
 }
 
+// ErrOutOfMemory returns the remark of the tasks whose processes were killed on reaching the memory limit.
+func ErrOutOfMemory(limit string) error {
+	return New(fmt.Sprintf("test process killed: out of memory at %s", limit))
+}
+
 // ErrSecretNotFound represents the error when a secret is not found in map.
 func ErrSecretNotFound(secret string) error {
 	return New(fmt.Sprintf("secret with name %s not found", secret))
@@ -69,6 +74,8 @@ var (
 	ErrMissingAccessToken = New("Missing OAuth access token. Please add an OAuth token")
 	// ErrSubModuleNotFound will be thrown if submodule is not present in yml
 	ErrSubModuleNotFound = New("Submodule not found in tas config file")
+	// ErrHeartbeatFailed is the remark of the tasks aborted as their heartbeats could not be delivered
+	ErrHeartbeatFailed = New("Task aborted as its heartbeat could not be delivered")
 )
 
 type StatusFailed struct {
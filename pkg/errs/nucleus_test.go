@@ -40,3 +40,11 @@ func TestErrSecretNotFound(t *testing.T) {
 		t.Errorf("Received: %v, Expected: %v", got, want)
 	}
 }
+
+func TestErrOutOfMemory(t *testing.T) {
+	got := ErrOutOfMemory("7.8GiB")
+	want := "test process killed: out of memory at 7.8GiB"
+	if got.Error() != want {
+		t.Errorf("Received: %v, Expected: %v", got, want)
+	}
+}
@@ -215,6 +215,13 @@ func ErrDockerVolCrt(err string) Err {
 		Message: fmt.Sprintf("Docker volume create failed with error:  \n%s", err)}
 }
 
+// ErrDockerService function returns error with code "ERR::DOCKER::SVC"
+func ErrDockerService(err string) Err {
+	return Err{
+		Code:    "ERR::DOCKER::SVC",
+		Message: fmt.Sprintf("Docker service containers failed with error:  \n%s", err)}
+}
+
 // ErrDockerCP function returns error with code "ERR::DOCKER::CP"
 func ErrDockerCP(err string) Err {
 	return Err{
@@ -259,3 +266,6 @@ var ERR_SNK_RD_WRT_MSM = Err{
 var CR_AUTH_NF = Err{
 	Code:    "CR::AUTH:NF",
 	Message: "Container registry auth are not present for private repo"}
+
+// ErrLogQueueFull should be raised when the queue of the streamed logs is full
+var ErrLogQueueFull = New("log stream queue is full")
@@ -49,6 +49,7 @@ func NewGitManager(logger lumber.Logger, execManager core.ExecutionManager) core
 }
 
 func (gm *gitManager) Clone(ctx context.Context, payload *core.Payload, oauth *core.Oauth) error {
+	logger := lumber.FromContext(ctx, gm.logger)
 	repoLink := payload.RepoLink
 	repoItems := strings.Split(repoLink, "/")
 	repoName := repoItems[len(repoItems)-1]
@@ -56,19 +57,19 @@ func (gm *gitManager) Clone(ctx context.Context, payload *core.Payload, oauth *c
 
 	archiveURL, err := urlmanager.GetCloneURL(payload.GitProvider, repoLink, repoName, commitID, payload.ForkSlug, payload.RepoSlug)
 	if err != nil {
-		gm.logger.Errorf("failed to get clone url for provider %s, error %v", payload.GitProvider, err)
+		logger.Errorf("failed to get clone url for provider %s, error %v", payload.GitProvider, err)
 		return err
 	}
 
-	gm.logger.Debugf("cloning from %s", archiveURL)
+	logger.Debugf("cloning from %s", archiveURL)
 	err = gm.downloadFile(ctx, archiveURL, commitID+".zip", oauth)
 	if err != nil {
-		gm.logger.Errorf("failed to download file %v", err)
+		logger.Errorf("failed to download file %v", err)
 		return err
 	}
 
 	if err = gm.initGit(ctx, payload, oauth); err != nil {
-		gm.logger.Errorf("failed to initialize git, error %v", err)
+		logger.Errorf("failed to initialize git, error %v", err)
 		return err
 	}
 
@@ -77,6 +78,7 @@ func (gm *gitManager) Clone(ctx context.Context, payload *core.Payload, oauth *c
 
 // downloadFile clones the archive from github and extracts the file if it is a zip file.
 func (gm *gitManager) downloadFile(ctx context.Context, archiveURL, fileName string, oauth *core.Oauth) error {
+	logger := lumber.FromContext(ctx, gm.logger)
 	header := getHeaderMap(oauth)
 	respBody, stausCode, err := gm.request.MakeAPIRequest(ctx, http.MethodGet, archiveURL, nil, nil, header)
 	if err != nil {
@@ -88,7 +90,7 @@ func (gm *gitManager) downloadFile(ctx context.Context, archiveURL, fileName str
 
 	err = gm.copyAndExtractFile(ctx, respBody, fileName)
 	if err != nil {
-		gm.logger.Errorf("failed to copy file %v", err)
+		logger.Errorf("failed to copy file %v", err)
 		return err
 	}
 	return nil
@@ -97,14 +99,15 @@ func (gm *gitManager) downloadFile(ctx context.Context, archiveURL, fileName str
 // copyAndExtractFile copies the content of http response directly to the local storage
 // and extracts the file if it is a zip file.
 func (gm *gitManager) copyAndExtractFile(ctx context.Context, respBody []byte, path string) error {
+	logger := lumber.FromContext(ctx, gm.logger)
 	out, err := os.Create(path)
 	if err != nil {
-		gm.logger.Errorf("failed to create file err %v", err)
+		logger.Errorf("failed to create file err %v", err)
 		return err
 	}
 	_, err = out.Write(respBody)
 	if err != nil {
-		gm.logger.Errorf("failed to write to file %v", err)
+		logger.Errorf("failed to write to file %v", err)
 		out.Close()
 		return err
 	}
@@ -115,7 +118,7 @@ func (gm *gitManager) copyAndExtractFile(ctx context.Context, respBody []byte, p
 		zip := archiver.NewZip()
 		zip.OverwriteExisting = true
 		if err = zip.Unarchive(path, fmt.Sprintf("%s/clonedir", filepath.Dir(path))); err != nil {
-			gm.logger.Errorf("failed to unarchive file %v", err)
+			logger.Errorf("failed to unarchive file %v", err)
 			return err
 		}
 	}
@@ -125,7 +128,7 @@ func (gm *gitManager) copyAndExtractFile(ctx context.Context, respBody []byte, p
 		fmt.Sprintf("mv %s/clonedir/*/* %s", filepath.Dir(path), global.RepoDir),
 	}
 
-	err = gm.execManager.ExecuteInternalCommands(ctx, core.RenameCloneFile, commands, filepath.Dir(path), nil, nil)
+	_, err = gm.execManager.ExecuteInternalCommands(ctx, core.RenameCloneFile, commands, filepath.Dir(path), nil, nil)
 	if err != nil {
 		return err
 	}
@@ -134,6 +137,7 @@ func (gm *gitManager) copyAndExtractFile(ctx context.Context, respBody []byte, p
 }
 
 func (gm *gitManager) initGit(ctx context.Context, payload *core.Payload, oauth *core.Oauth) error {
+	logger := lumber.FromContext(ctx, gm.logger)
 	branch := payload.BranchName
 	repoLink := payload.RepoLink
 	if payload.GitProvider == core.Bitbucket && payload.ForkSlug != "" {
@@ -148,7 +152,7 @@ func (gm *gitManager) initGit(ctx context.Context, payload *core.Payload, oauth
 	if oauth.Type == core.Basic {
 		decodedToken, err := base64.StdEncoding.DecodeString(oauth.AccessToken)
 		if err != nil {
-			gm.logger.Errorf("Failed to decode basic oauth token for RepoID %s: %s", payload.RepoID, err)
+			logger.Errorf("Failed to decode basic oauth token for RepoID %s: %s", payload.RepoID, err)
 			return err
 		}
 
@@ -170,7 +174,7 @@ func (gm *gitManager) initGit(ctx context.Context, payload *core.Payload, oauth
 		fmt.Sprintf("git config --global --remove-section url.%s", urlWithToken),
 		fmt.Sprintf("git checkout --progress --force -B %s refs/remotes/origin/%s", branch, branch),
 	}
-	if err := gm.execManager.ExecuteInternalCommands(ctx, core.InitGit, commands, global.RepoDir, nil, nil); err != nil {
+	if _, err := gm.execManager.ExecuteInternalCommands(ctx, core.InitGit, commands, global.RepoDir, nil, nil); err != nil {
 		return err
 	}
 	return nil
@@ -178,6 +182,7 @@ func (gm *gitManager) initGit(ctx context.Context, payload *core.Payload, oauth
 
 func (gm *gitManager) DownloadFileByCommit(ctx context.Context, gitProvider, repoSlug,
 	commitID, filePath string, oauth *core.Oauth) (string, error) {
+	logger := lumber.FromContext(ctx, gm.logger)
 	downloadURL, err := urlmanager.GetFileDownloadURL(gitProvider, commitID, repoSlug, filePath)
 	if err != nil {
 		return "", err
@@ -193,12 +198,12 @@ func (gm *gitManager) DownloadFileByCommit(ctx context.Context, gitProvider, rep
 	path := utils.GenerateUUID() + ".yml"
 	out, err := os.Create(path)
 	if err != nil {
-		gm.logger.Errorf("failed to create file err %v", err)
+		logger.Errorf("failed to create file err %v", err)
 		return "", err
 	}
 	_, err = out.Write(respBody)
 	if err != nil {
-		gm.logger.Errorf("failed to copy file %v", err)
+		logger.Errorf("failed to copy file %v", err)
 		out.Close()
 		return "", err
 	}
@@ -54,6 +54,9 @@ func Test_downloadFile(t *testing.T) {
 		mock.AnythingOfType("string"),
 		mock.AnythingOfType("map[string]string"),
 		mock.AnythingOfType("map[string]string")).Return(
+		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) []*core.StepResult {
+			return nil
+		},
 		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) error {
 			return nil
 		},
@@ -88,6 +91,9 @@ func Test_copyAndExtractFile(t *testing.T) {
 		mock.AnythingOfType("string"),
 		mock.AnythingOfType("map[string]string"),
 		mock.AnythingOfType("map[string]string")).Return(
+		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) []*core.StepResult {
+			return nil
+		},
 		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) error {
 			return nil
 		},
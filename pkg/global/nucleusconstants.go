@@ -21,6 +21,7 @@ const (
 	SamplingTime               = 5 * time.Millisecond
 	RepoSecretPath             = "/vault/secrets/reposecrets"
 	OauthSecretPath            = "/vault/secrets/oauth"
+	SecretPolicyPath           = "/vault/secrets/secretpolicy"
 	NeuronRemoteHost           = "http://neuron-service.phoenix.svc.cluster.local"
 	BlockTestFileLocation      = "/tmp/blocktests.json"
 	SecretRegex                = `\${{\s*secrets\.(.*?)\s*}}` // nolint: gosec
@@ -31,6 +32,8 @@ const (
 	ModulePath                 = "MODULE_PATH"
 	PackageJSON                = "package.json"
 	SubModuleName              = "SUBMODULE_NAME"
+	MatrixNodeKey              = "node"
+	MaxMatrixVariants          = 64
 	ArgPattern                 = "--pattern"
 	ArgConfig                  = "--config"
 	ArgDiff                    = "--diff"
@@ -50,6 +53,13 @@ var FrameworkRunnerMap = map[string]string{
 	"junit":   "java",
 }
 
+// GitProviderHostMap is map of git provider with there host
+var GitProviderHostMap = map[string]string{
+	"github":    "github.com",
+	"gitlab":    "gitlab.com",
+	"bitbucket": "bitbucket.org",
+}
+
 // APIHostURLMap is map of git provider with there api url
 var APIHostURLMap = map[string]string{
 	"github":    "https://api.github.com/repos",
@@ -57,6 +67,14 @@ var APIHostURLMap = map[string]string{
 	"bitbucket": "https://api.bitbucket.org/2.0",
 }
 
+// InternalEnvVars are the env variables of nucleus holding credentials,
+// which are never passed to user commands
+var InternalEnvVars = []string{"TOKEN"}
+
+// InternalEnvPrefixes are the prefixes of env variables of nucleus and synapse holding credentials,
+// which are never passed to user commands
+var InternalEnvPrefixes = []string{"AZURE", "SYN_"}
+
 // InstallRunnerCmds  are list of command used to install custom runner
 var InstallRunnerCmds = []string{"tar -xzf /custom-runners/custom-runners.tgz"}
 
@@ -8,11 +8,13 @@ import (
 const (
 	GracefulTimeout       = 100 * time.Second
 	ProxyServerPort       = "8000"
+	MetricsServerPort     = "8001"
 	DirectoryPermissions  = 0755
 	FilePermissions       = 0755
 	VaultSecretDir        = "/vault/secrets"
 	GitConfigFileName     = "oauth"
 	RepoSecretsFileName   = "reposecrets"
+	SecretPolicyFileName  = "secretpolicy"
 	SynapseContainerURL   = "http://synapse:8000"
 	NetworkEnvName        = "NetworkName"
 	AutoRemoveEnv         = "AutoRemove"
@@ -23,8 +25,12 @@ const (
 	Local                 = true
 	MaxConnectionAttempts = 10
 	ExecutionLogsPath     = "/var/log/synapse"
+	LogSpoolPath          = ExecutionLogsPath + "/spool"
+	LogStreamPath         = "/internal/log-stream"
 	PingWait              = 30 * time.Second
 	MaxMessageSize        = 4096
+	SecretsKeyEnv         = "SYN_SECRETS_KEY"
+	SecretsKeyFileEnv     = "SYN_SECRETS_KEY_FILE"
 )
 
 // SocketURL lambdatest url for synapse socket
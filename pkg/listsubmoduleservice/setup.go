@@ -26,19 +26,20 @@ func New(request core.Requests, logger lumber.Logger) core.ListSubModuleService
 }
 
 func (s *subModuleListService) Send(ctx context.Context, buildID string, totalSubmodule int) error {
+	logger := lumber.FromContext(ctx, s.logger)
 	subModuleList := core.SubModuleList{
 		BuildID:        buildID,
 		TotalSubModule: totalSubmodule,
 	}
 	reqBody, err := json.Marshal(&subModuleList)
 	if err != nil {
-		s.logger.Errorf("error while json marshal %v", err)
+		logger.Errorf("error while json marshal %v", err)
 		return err
 	}
 	query, headers := utils.GetDefaultQueryAndHeaders()
 	if _, statusCode, err := s.requests.MakeAPIRequest(ctx, http.MethodPost, s.subModuleListEndpoint,
 		reqBody, query, headers); err != nil || statusCode != 200 {
-		s.logger.Errorf("error while making submodule-list api call status code %d, err %v", statusCode, err)
+		logger.Errorf("error while making submodule-list api call status code %d, err %v", statusCode, err)
 		return err
 	}
 	return nil
@@ -0,0 +1,229 @@
+package logstream
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sync"
+)
+
+const maxLineLength = 64 * 1024
+
+// detector masks the credentials matching re. If re has a capturing group only the group is masked,
+// keeping the key of a key value pair readable. Lines without any of the keywords are not matched
+// against re, which keeps the detectors cheap on large outputs.
+type detector struct {
+	keywords [][]byte
+	re       *regexp.Regexp
+}
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN[A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END[A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+
+	defaultDetectors = []*detector{
+		// GitHub personal access, oauth, user to server, server to server and refresh tokens
+		newDetector(`gh[pousr]_[A-Za-z0-9]{36,255}`, "gh"),
+		newDetector(`github_pat_[A-Za-z0-9_]{22,255}`, "github_pat_"),
+		// GitLab personal, project, group and runner tokens
+		newDetector(`gl(?:pat|ptt|rt|dt|cbt|soat)-[A-Za-z0-9_\-]{20,}`, "gl"),
+		// AWS access key IDs and secret access keys
+		newDetector(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`, "AKIA", "ASIA"),
+		newDetector(`(?i)aws_?secret_?access_?key["']?[ \t]*[:=][ \t]*["']?([A-Za-z0-9/+=]{40})`, "ecret", "ECRET"),
+		// npm access tokens and auth tokens in .npmrc
+		newDetector(`npm_[A-Za-z0-9]{36}`, "npm_"),
+		newDetector(`_auth(?:Token)?[ \t]*=[ \t]*["']?([^\s"']+)`, "_auth"),
+		// JSON web tokens
+		newDetector(`eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`, "eyJ"),
+	}
+
+	userDetectorsMu sync.RWMutex
+	userDetectors   []*detector
+)
+
+func newDetector(pattern string, keywords ...string) *detector {
+	d := &detector{re: regexp.MustCompile(pattern)}
+	for _, keyword := range keywords {
+		d.keywords = append(d.keywords, []byte(keyword))
+	}
+	return d
+}
+
+// AddPatterns registers user defined regex patterns, which are masked by the maskers created afterwards.
+func AddPatterns(patterns ...string) error {
+	detectors := make([]*detector, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		detectors = append(detectors, &detector{re: re})
+	}
+	userDetectorsMu.Lock()
+	defer userDetectorsMu.Unlock()
+	for _, d := range detectors {
+		if !hasDetector(userDetectors, d.re.String()) {
+			userDetectors = append(userDetectors, d)
+		}
+	}
+	return nil
+}
+
+func hasDetector(detectors []*detector, pattern string) bool {
+	for _, d := range detectors {
+		if d.re.String() == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func getDetectors() []*detector {
+	userDetectorsMu.RLock()
+	defer userDetectorsMu.RUnlock()
+	detectors := make([]*detector, 0, len(defaultDetectors)+len(userDetectors))
+	detectors = append(detectors, defaultDetectors...)
+	return append(detectors, userDetectors...)
+}
+
+// mask replaces the credentials in line matched by the detector.
+func (d *detector) mask(line []byte) []byte {
+	if len(d.keywords) > 0 && !containsAny(line, d.keywords) {
+		return line
+	}
+	matches := d.re.FindAllSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+	masked := make([]byte, 0, len(line))
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) > 2 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+		masked = append(masked, line[last:start]...)
+		masked = append(masked, maskedStr...)
+		last = end
+	}
+	return append(masked, line[last:]...)
+}
+
+func containsAny(line []byte, keywords [][]byte) bool {
+	for _, keyword := range keywords {
+		if bytes.Contains(line, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternMasker masks the credentials detected in the stream line by line. The lines of a
+// private key block are masked from its begin line up to its end line.
+type patternMasker struct {
+	w            io.Writer
+	detectors    []*detector
+	pending      []byte
+	inPrivateKey bool
+}
+
+func newPatternMasker(w io.Writer) *patternMasker {
+	return &patternMasker{w: w, detectors: getDetectors()}
+}
+
+// Write writes the complete lines in p to the base writer after masking them, the incomplete
+// last line is held back until the next write or Close.
+func (m *patternMasker) Write(p []byte) (n int, err error) {
+	buf := p
+	if len(m.pending) > 0 {
+		buf = append(m.pending, p...)
+	}
+	end := bytes.LastIndexAny(buf, "\n\r") + 1
+	if end == 0 && len(buf) >= maxLineLength {
+		end = len(buf)
+	}
+	if end > 0 {
+		if _, err = m.w.Write(m.maskLines(buf[:end])); err != nil {
+			return 0, err
+		}
+	}
+	m.pending = append(m.pending[:0], buf[end:]...)
+	return len(p), nil
+}
+
+// Close masks and writes the held back line.
+func (m *patternMasker) Close() error {
+	if len(m.pending) == 0 {
+		return nil
+	}
+	_, err := m.w.Write(m.maskLines(m.pending))
+	m.pending = m.pending[:0]
+	return err
+}
+
+func (m *patternMasker) maskLines(buf []byte) []byte {
+	// the detectors do not match across lines, so they can be applied on all the lines at once
+	if !m.inPrivateKey && !bytes.Contains(buf, []byte("PRIVATE KEY")) {
+		for _, d := range m.detectors {
+			buf = d.mask(buf)
+		}
+		return buf
+	}
+	masked := make([]byte, 0, len(buf))
+	for len(buf) > 0 {
+		lineEnd := bytes.IndexAny(buf, "\n\r")
+		if lineEnd < 0 {
+			lineEnd = len(buf)
+		}
+		masked = append(masked, m.maskLine(buf[:lineEnd])...)
+		if lineEnd < len(buf) {
+			masked = append(masked, buf[lineEnd])
+			lineEnd++
+		}
+		buf = buf[lineEnd:]
+	}
+	return masked
+}
+
+func (m *patternMasker) maskLine(line []byte) []byte {
+	if m.inPrivateKey {
+		if loc := privateKeyEnd.FindIndex(line); loc != nil {
+			m.inPrivateKey = false
+			return append([]byte(maskedStr), m.maskLine(line[loc[1]:])...)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			return line
+		}
+		return []byte(maskedStr)
+	}
+	if bytes.Contains(line, []byte("PRIVATE KEY")) {
+		if loc := privateKeyBegin.FindIndex(line); loc != nil {
+			masked := append(append([]byte{}, line[:loc[0]]...), maskedStr...)
+			// the key can also be on a single line, e.g. with escaped newlines in JSON
+			if endLoc := privateKeyEnd.FindIndex(line[loc[1]:]); endLoc != nil {
+				return append(masked, m.maskLine(line[loc[1]+endLoc[1]:])...)
+			}
+			m.inPrivateKey = true
+			return masked
+		}
+	}
+	for _, d := range m.detectors {
+		line = d.mask(line)
+	}
+	return line
+}
+
+// NewMaskedReader returns a reader of r with the credentials detected in it masked.
+// It must be closed if not read till the end.
+func NewMaskedReader(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		m := newPatternMasker(pw)
+		_, err := io.Copy(m, r)
+		if err == nil {
+			err = m.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
@@ -1,7 +1,11 @@
 package logstream
 
 import (
+	"bytes"
+	"encoding/base64"
 	"io"
+	"net/url"
+	"sort"
 	"strings"
 )
 
@@ -9,15 +13,44 @@ const (
 	maskedStr = "****************"
 )
 
-// masker wraps a stream writer with a masker
+// masker wraps a stream writer with a masker. Since a secret can be split across writes,
+// the trailing bytes which may be the start of a secret are held back until the next write
+// or Close. The masked output is passed through the pattern masker for detecting credentials.
 type masker struct {
-	w io.Writer
-	r *strings.Replacer
+	w *patternMasker
+	// patterns indexes the strings to be masked by their first byte, longest first
+	patterns [256][][]byte
+	maxLen   int
+	pending  []byte
+	out      []byte
 }
 
-// NewMasker returns a masker that wraps io.Writer w.
-func NewMasker(w io.Writer, secretData map[string]string) io.Writer {
-	var oldnew []string
+// NewMasker returns a masker that wraps io.Writer w. Close must be called once writing is done
+// to flush the held back bytes, it does not close w.
+func NewMasker(w io.Writer, secretData map[string]string) io.WriteCloser {
+	pm := newPatternMasker(w)
+	patterns := getPatterns(secretData)
+	if len(patterns) == 0 {
+		return pm
+	}
+	m := &masker{w: pm}
+	for _, pattern := range patterns {
+		m.patterns[pattern[0]] = append(m.patterns[pattern[0]], []byte(pattern))
+		if len(pattern) > m.maxLen {
+			m.maxLen = len(pattern)
+		}
+	}
+	for i := range m.patterns {
+		candidates := m.patterns[i]
+		sort.Slice(candidates, func(a, b int) bool { return len(candidates[a]) > len(candidates[b]) })
+	}
+	return m
+}
+
+// getPatterns returns the strings to be masked for the secrets along with their encoded forms.
+// Multiline secrets are masked line by line, and as a whole in their encoded forms.
+func getPatterns(secretData map[string]string) []string {
+	unique := map[string]bool{}
 	for _, secret := range secretData {
 		if secret == "" {
 			continue
@@ -28,21 +61,114 @@ func NewMasker(w io.Writer, secretData map[string]string) io.Writer {
 			if len(part) < 2 {
 				continue
 			}
-			oldnew = append(oldnew, part, maskedStr)
+			unique[part] = true
+			for _, encoded := range encodings(part) {
+				unique[encoded] = true
+			}
+		}
+		if secret = strings.TrimSpace(secret); strings.Contains(secret, "\n") {
+			for _, encoded := range encodings(secret) {
+				unique[encoded] = true
+			}
 		}
 	}
-	if len(oldnew) == 0 {
-		return w
+	patterns := make([]string, 0, len(unique))
+	for pattern := range unique {
+		patterns = append(patterns, pattern)
 	}
-	return &masker{
-		w: w,
-		r: strings.NewReplacer(oldnew...),
+	return patterns
+}
+
+// encodings returns the common derived encodings of value which can appear in logs.
+func encodings(value string) []string {
+	stdEncoded := base64.StdEncoding.EncodeToString([]byte(value))
+	return []string{
+		stdEncoded,
+		strings.TrimRight(stdEncoded, "="),
+		base64.URLEncoding.EncodeToString([]byte(value)),
+		base64.RawURLEncoding.EncodeToString([]byte(value)),
+		url.QueryEscape(value),
+		url.PathEscape(value),
 	}
 }
 
 // Write writes p to the base writer. The method scans for any
 // sensitive data in p and masks before writing.
 func (m *masker) Write(p []byte) (n int, err error) {
-	_, err = m.w.Write([]byte(m.r.Replace(string(p))))
-	return len(p), err
+	buf := p
+	if len(m.pending) > 0 {
+		buf = append(m.pending, p...)
+	}
+	masked, rest := m.mask(buf, false)
+	if len(masked) > 0 {
+		if _, err = m.w.Write(masked); err != nil {
+			return 0, err
+		}
+	}
+	m.pending = append(m.pending[:0], rest...)
+	return len(p), nil
+}
+
+// Close masks and writes the held back bytes.
+func (m *masker) Close() error {
+	if len(m.pending) > 0 {
+		masked, _ := m.mask(m.pending, true)
+		m.pending = m.pending[:0]
+		if _, err := m.w.Write(masked); err != nil {
+			return err
+		}
+	}
+	return m.w.Close()
+}
+
+// mask returns buf with the secrets masked, along with the trailing bytes held back as they
+// may be the start of a secret. Nothing is held back for the final chunk.
+func (m *masker) mask(buf []byte, final bool) (masked, rest []byte) {
+	m.out = m.out[:0]
+	start, end := 0, len(buf)
+	for i := 0; i < end; {
+		candidates := m.patterns[buf[i]]
+		if candidates == nil {
+			i++
+			continue
+		}
+		if !final && len(buf)-i < m.maxLen && isPartialMatch(buf[i:], candidates) {
+			end = i
+			break
+		}
+		n := matchLen(buf[i:], candidates)
+		if n == 0 {
+			i++
+			continue
+		}
+		m.out = append(m.out, buf[start:i]...)
+		m.out = append(m.out, maskedStr...)
+		i += n
+		start = i
+	}
+	if start == 0 {
+		return buf[:end], buf[end:]
+	}
+	m.out = append(m.out, buf[start:end]...)
+	return m.out, buf[end:]
+}
+
+// isPartialMatch reports whether b is a proper prefix of any of the candidates.
+func isPartialMatch(b []byte, candidates [][]byte) bool {
+	for _, candidate := range candidates {
+		if len(candidate) > len(b) && bytes.HasPrefix(candidate, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLen returns the length of the longest candidate b starts with.
+func matchLen(b []byte, candidates [][]byte) int {
+	for _, candidate := range candidates {
+		if bytes.HasPrefix(b, candidate) {
+			return len(candidate)
+		}
+	}
+	return 0
 }
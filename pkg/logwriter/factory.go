@@ -0,0 +1,85 @@
+package logwriter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+type factory struct {
+	cfg            config.LogWriterConfig
+	writers        map[string]bool
+	azureClient    core.AzureClient
+	streamEndpoint string
+	logger         lumber.Logger
+}
+
+// NewFactory returns a factory of the log writers selected in cfg. The logs are streamed live
+// to streamEndpoint, which is empty when synapse is not available.
+func NewFactory(cfg *config.LogWriterConfig,
+	azureClient core.AzureClient,
+	streamEndpoint string,
+	logger lumber.Logger) (core.LogWriterFactory, error) {
+	f := &factory{
+		cfg:            *cfg,
+		writers:        map[string]bool{},
+		azureClient:    azureClient,
+		streamEndpoint: streamEndpoint,
+		logger:         logger,
+	}
+	if strings.TrimSpace(cfg.Writers) == "" {
+		f.writers[config.AzureLogWriter] = true
+		f.writers[config.StreamLogWriter] = streamEndpoint != ""
+		return f, nil
+	}
+	for _, writer := range strings.Split(cfg.Writers, ",") {
+		writer = strings.ToLower(strings.TrimSpace(writer))
+		switch writer {
+		case config.AzureLogWriter, config.FileLogWriter:
+		case config.StreamLogWriter:
+			if streamEndpoint == "" {
+				return nil, fmt.Errorf("log writer %s is only available in local runner mode", writer)
+			}
+		default:
+			return nil, fmt.Errorf("unknown log writer %s, expected one of %s, %s and %s",
+				writer, config.AzureLogWriter, config.FileLogWriter, config.StreamLogWriter)
+		}
+		f.writers[writer] = true
+	}
+	return f, nil
+}
+
+func (f *factory) NewLogWriter(purpose core.SASURLPurpose, payload *core.Payload) core.LogWriterStrategy {
+	return f.WithLogStream(f.NewStoreLogWriter(purpose, payload), purpose, payload)
+}
+
+func (f *factory) NewStoreLogWriter(purpose core.SASURLPurpose, payload *core.Payload) core.LogWriterStrategy {
+	var writers []core.LogWriterStrategy
+	if f.writers[config.AzureLogWriter] {
+		writers = append(writers, NewAzureLogWriter(f.azureClient, purpose, f.logger))
+	}
+	if f.writers[config.FileLogWriter] {
+		writers = append(writers, NewFileLogWriter(f.cfg.Dir, purpose, f.cfg.MaxSize, f.cfg.MaxBackups, f.logger))
+	}
+	return newLogWriters(writers)
+}
+
+func (f *factory) WithLogStream(writer core.LogWriterStrategy,
+	purpose core.SASURLPurpose,
+	payload *core.Payload) core.LogWriterStrategy {
+	if !f.writers[config.StreamLogWriter] {
+		return writer
+	}
+	return NewTeeLogWriter(writer, NewStreamLogWriter(f.streamEndpoint, purpose, payload, f.logger))
+}
+
+// newLogWriters returns a single log writer for writers
+func newLogWriters(writers []core.LogWriterStrategy) core.LogWriterStrategy {
+	if len(writers) == 1 {
+		return writers[0]
+	}
+	return NewTeeLogWriter(writers...)
+}
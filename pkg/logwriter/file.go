@@ -0,0 +1,91 @@
+package logwriter
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/logstream"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+var (
+	logFilesMu sync.Mutex
+	logFiles   = map[string]*lumberjack.Logger{}
+)
+
+// FileLogWriter writes the logs of a stage to its own file, which is rotated once it exceeds
+// the max size. The rotated files are compressed with gzip.
+type FileLogWriter struct {
+	file    *lumberjack.Logger
+	purpose core.SASURLPurpose
+	logger  lumber.Logger
+}
+
+// NewFileLogWriter returns a log writer appending the logs of purpose to <dir>/<purpose>.log
+func NewFileLogWriter(dir string,
+	purpose core.SASURLPurpose,
+	maxSize, maxBackups int,
+	logger lumber.Logger) core.LogWriterStrategy {
+	return &FileLogWriter{
+		file:    getLogFile(filepath.Join(dir, string(purpose)+".log"), maxSize, maxBackups),
+		purpose: purpose,
+		logger:  logger,
+	}
+}
+
+// getLogFile returns the rotating file at path, which is shared by all the writers of the path
+// so that the rotation of the file is not raced.
+func getLogFile(path string, maxSize, maxBackups int) *lumberjack.Logger {
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	if file, ok := logFiles[path]; ok {
+		return file
+	}
+	file := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		Compress:   true,
+	}
+	logFiles[path] = file
+	return file
+}
+
+// Write appends the logs line by line, as the logs of the submodules of a stage are written concurrently
+func (f *FileLogWriter) Write(ctx context.Context, reader io.Reader) <-chan error {
+	logger := lumber.FromContext(ctx, f.logger)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(errChan)
+		maskedReader := logstream.NewMaskedReader(reader)
+		defer maskedReader.Close()
+		bufReader := bufio.NewReader(maskedReader)
+		for {
+			line, err := bufReader.ReadBytes('\n')
+			if len(line) > 0 {
+				if _, writeErr := f.file.Write(line); writeErr != nil {
+					logger.Errorf("failed to write logs to file %s, error: %v", f.file.Filename, writeErr)
+					// the reader is drained so that the command writing the logs is not blocked
+					_, _ = io.Copy(io.Discard, bufReader)
+					errChan <- writeErr
+					return
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				logger.Errorf("failed to read logs for file %s, error: %v", f.file.Filename, err)
+				errChan <- err
+				return
+			}
+		}
+		logger.Debugf("written logs %s to file %s", f.purpose, f.file.Filename)
+	}()
+	return errChan
+}
@@ -0,0 +1,111 @@
+package logwriter
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLogWriter(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	dir := t.TempDir()
+	line := strings.Repeat("x", 1023) + "\n"
+	// each stage is written to its own file, the first one exceeding the max size of 1MB
+	for _, purpose := range []core.SASURLPurpose{core.PurposePreRunLogs, core.PurposeExecutionLogs} {
+		writer := NewFileLogWriter(dir, purpose, 1, 2, logger)
+		logs := line
+		if purpose == core.PurposePreRunLogs {
+			logs = strings.Repeat(line, 1500)
+		}
+		if err := <-writer.Write(context.TODO(), strings.NewReader(logs)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	execution, err := os.ReadFile(filepath.Join(dir, "execution_logs.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, line, string(execution))
+
+	info, err := os.Stat(filepath.Join(dir, "pre_run_logs.log"))
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(1<<20))
+	// the rotated file is compressed in the background
+	assert.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(filepath.Join(dir, "pre_run_logs-*.log.gz"))
+		return len(matches) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestFileLogWriterDrainsOnError(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	// the log file can not be created under a regular file
+	parent := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(parent, nil, 0644))
+	writer := NewFileLogWriter(parent, core.PurposePostRunLogs, 1, 2, logger)
+
+	reader, pipeWriter := io.Pipe()
+	errChan := writer.Write(context.TODO(), reader)
+	written := make(chan error, 1)
+	go func() {
+		// the writer of the logs is not blocked once writing them to the file fails
+		_, writeErr := pipeWriter.Write([]byte(strings.Repeat("line\n", 1<<18)))
+		pipeWriter.Close()
+		written <- writeErr
+	}()
+	select {
+	case writeErr := <-written:
+		assert.NoError(t, writeErr)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("writing the logs blocked after writing them to the file failed")
+	}
+	assert.Error(t, <-errChan)
+}
+
+func TestNewFactory(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	tests := []struct {
+		name           string
+		writers        string
+		streamEndpoint string
+		want           map[string]bool
+		wantErr        string
+	}{
+		{"default", "", "", map[string]bool{config.AzureLogWriter: true, config.StreamLogWriter: false}, ""},
+		{"default in local runner mode", "", "http://synapse:8000",
+			map[string]bool{config.AzureLogWriter: true, config.StreamLogWriter: true}, ""},
+		{"selected writers", " File, stream", "http://synapse:8000",
+			map[string]bool{config.FileLogWriter: true, config.StreamLogWriter: true}, ""},
+		{"stream without synapse", "file,stream", "", nil, "log writer stream is only available in local runner mode"},
+		{"unknown writer", "azure,s3", "", nil, "unknown log writer s3, expected one of azure, file and stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.LogWriterConfig{Writers: tt.writers, Dir: t.TempDir()}
+			got, err := NewFactory(cfg, nil, tt.streamEndpoint, logger)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.(*factory).writers)
+		})
+	}
+}
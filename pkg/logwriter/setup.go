@@ -7,6 +7,7 @@ import (
 	"io"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/logstream"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 )
 
@@ -45,41 +46,47 @@ func NewBufferLogWriter(subModule string,
 }
 
 func (b *BufferLogWriter) Write(ctx context.Context, reader io.Reader) <-chan error {
+	logger := lumber.FromContext(ctx, b.logger)
 	errChan := make(chan error, 1)
 	go func() {
 		if _, err := fmt.Fprintf(b.buffer, "\n<------ PRE RUN for %s  ------> \n", b.subModule); err != nil {
-			b.logger.Debugf("Error writing the logs separator for submodule %s, error %v", b.subModule, err)
+			logger.Debugf("Error writing the logs separator for submodule %s, error %v", b.subModule, err)
 			errChan <- err
 			return
 		}
-		if _, err := b.buffer.ReadFrom(reader); err != nil {
-			b.logger.Debugf("Error writing the logs to buffer for submodule %s, error %v", b.subModule, err)
+		maskedReader := logstream.NewMaskedReader(reader)
+		defer maskedReader.Close()
+		if _, err := b.buffer.ReadFrom(maskedReader); err != nil {
+			logger.Debugf("Error writing the logs to buffer for submodule %s, error %v", b.subModule, err)
 			errChan <- err
 			return
 		}
 		close(errChan)
-		b.logger.Debugf("written logs for sub module %s to buffer", b.subModule)
+		logger.Debugf("written logs for sub module %s to buffer", b.subModule)
 	}()
 	return errChan
 }
 
 func (a *AzureLogWriter) Write(ctx context.Context, reader io.Reader) <-chan error {
+	logger := lumber.FromContext(ctx, a.logger)
 	errChan := make(chan error, 1)
 	go func() {
 		sasURL, err := a.azureClient.GetSASURL(ctx, a.purpose, nil)
 		if err != nil {
-			a.logger.Errorf("failed to genereate SAS URL for purpose %s, error: %v", a.purpose, err)
+			logger.Errorf("failed to genereate SAS URL for purpose %s, error: %v", a.purpose, err)
 			errChan <- err
 			return
 		}
-		blobPath, err := a.azureClient.CreateUsingSASURL(ctx, sasURL, reader, "text/plain")
+		maskedReader := logstream.NewMaskedReader(reader)
+		defer maskedReader.Close()
+		blobPath, err := a.azureClient.CreateUsingSASURL(ctx, sasURL, maskedReader, "text/plain")
 		if err != nil {
-			a.logger.Errorf("failed to create SAS URL for path %s, error: %v", blobPath, err)
+			logger.Errorf("failed to create SAS URL for path %s, error: %v", blobPath, err)
 			errChan <- err
 			return
 		}
 		close(errChan)
-		a.logger.Debugf("created blob path %s", blobPath)
+		logger.Debugf("created blob path %s", blobPath)
 	}()
 	return errChan
 }
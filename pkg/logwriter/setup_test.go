@@ -129,7 +129,7 @@ func mockUtil(azureClient *mocks.AzureClient, msgGet, msgCreate, errGet, errCrea
 		})
 
 	azureClient.On("CreateUsingSASURL", mock.AnythingOfType("*context.emptyCtx"),
-		mock.AnythingOfType("string"), mock.AnythingOfType("*strings.Reader"), "text/plain").Return(
+		mock.AnythingOfType("string"), mock.AnythingOfType("*io.PipeReader"), "text/plain").Return(
 		func(ctx context.Context, sasURL string, reader io.Reader, mimeType string) string {
 			return msgCreate
 		},
@@ -0,0 +1,203 @@
+package logwriter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/logstream"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	streamFlushInterval = 500 * time.Millisecond
+	streamFrameLines    = 200
+	// streamFrameBytes bounds the encoded lines of a frame, a frame holds a single line
+	// exceeding it, which is at most 6 times streamMaxLineLength once escaped
+	streamFrameBytes     = core.MaxLogChunkSize / 4
+	streamMaxLineLength  = 64 * 1024
+	streamQueueLines     = 10000
+	streamRequestTimeout = 10 * time.Second
+	streamMaxRetryTime   = 10 * time.Second
+)
+
+// errStreamThrottled is returned when synapse can not take any more frames
+var errStreamThrottled = errors.New("log stream throttled by synapse")
+
+// StreamLogWriter streams the logs live to synapse in frames of timestamped lines. Streaming is best effort,
+// the lines are dropped when synapse can not keep up, so the logs are never held back by the stream.
+type StreamLogWriter struct {
+	endpoint   string
+	purpose    core.SASURLPurpose
+	payload    *core.Payload
+	httpClient http.Client
+	logger     lumber.Logger
+}
+
+// NewStreamLogWriter returns a log writer streaming the logs of purpose to the log stream endpoint of synapse
+func NewStreamLogWriter(endpoint string,
+	purpose core.SASURLPurpose,
+	payload *core.Payload,
+	logger lumber.Logger) core.LogWriterStrategy {
+	return &StreamLogWriter{
+		endpoint:   endpoint,
+		purpose:    purpose,
+		payload:    payload,
+		httpClient: http.Client{Timeout: streamRequestTimeout},
+		logger:     logger,
+	}
+}
+
+func (s *StreamLogWriter) Write(ctx context.Context, reader io.Reader) <-chan error {
+	errChan := make(chan error, 1)
+	lines := make(chan core.LogLine, streamQueueLines)
+	dropped := make(chan int, 1)
+	go func() {
+		maskedReader := logstream.NewMaskedReader(reader)
+		defer maskedReader.Close()
+		dropped <- s.readLines(maskedReader, lines)
+	}()
+	go func() {
+		defer close(errChan)
+		s.sendFrames(ctx, lines, dropped)
+	}()
+	return errChan
+}
+
+// readLines queues the lines read from reader, the lines which do not fit in the queue are dropped
+func (s *StreamLogWriter) readLines(reader io.Reader, lines chan<- core.LogLine) int {
+	defer close(lines)
+	dropped := 0
+	bufReader := bufio.NewReaderSize(reader, streamMaxLineLength)
+	for {
+		text, err := bufReader.ReadString('\n')
+		if text != "" {
+			if len(text) > streamMaxLineLength {
+				text = text[:streamMaxLineLength]
+			}
+			select {
+			case lines <- core.LogLine{Time: time.Now(), Text: strings.TrimRight(text, "\r\n")}:
+			default:
+				dropped++
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				s.logger.Errorf("error reading logs for streaming %s, error: %v", s.purpose, err)
+				// the reader must be drained, as the other writers of the logs would block otherwise
+				_, _ = io.Copy(io.Discard, bufReader)
+			}
+			return dropped
+		}
+	}
+}
+
+// sendFrames sends the queued lines in frames, which are flushed when full or every streamFlushInterval
+func (s *StreamLogWriter) sendFrames(ctx context.Context, lines <-chan core.LogLine, dropped <-chan int) {
+	logger := lumber.FromContext(ctx, s.logger)
+	chunk := &core.LogChunk{
+		OrgID:    s.payload.OrgID,
+		BuildID:  s.payload.BuildID,
+		TaskID:   s.payload.TaskID,
+		StreamID: uuid.NewString(),
+		Purpose:  s.purpose,
+	}
+	ticker := time.NewTicker(streamFlushInterval)
+	defer ticker.Stop()
+	failed := false
+	frameBytes := 0
+	flush := func() {
+		if len(chunk.Lines) == 0 && chunk.Dropped == 0 {
+			return
+		}
+		// once synapse is unreachable the rest of the stream is dropped, so that each frame does not wait for it
+		throttled := 0
+		if !failed {
+			err := s.sendFrame(ctx, chunk)
+			switch {
+			case errors.Is(err, errStreamThrottled):
+				// synapse spools the frames it can not send right away, so it is only throttled with a full
+				// spool, the frame is dropped and the stream goes on in case it is drained
+				logger.Warnf("log stream %s throttled, dropping %d lines", s.purpose, len(chunk.Lines))
+				throttled = len(chunk.Lines)
+			case err != nil:
+				logger.Errorf("failed to stream logs %s, dropping the rest of the stream, error: %v", s.purpose, err)
+				failed = true
+			}
+		}
+		chunk.Seq++
+		chunk.Dropped = throttled
+		chunk.Lines = chunk.Lines[:0]
+		frameBytes = 0
+	}
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				chunk.Dropped += <-dropped
+				flush()
+				return
+			}
+			lineBytes, err := json.Marshal(line)
+			if err != nil {
+				chunk.Dropped++
+				continue
+			}
+			// the frame is flushed before the line would take it over streamFrameBytes once encoded
+			if frameBytes+len(lineBytes)+1 > streamFrameBytes {
+				flush()
+			}
+			chunk.Lines = append(chunk.Lines, line)
+			frameBytes += len(lineBytes) + 1
+			if len(chunk.Lines) >= streamFrameLines {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// sendFrame posts the frame to synapse, retrying while synapse asks to slow down or fails
+func (s *StreamLogWriter) sendFrame(ctx context.Context, chunk *core.LogChunk) error {
+	body, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	exponentialBackoff := backoff.NewExponentialBackOff()
+	exponentialBackoff.MaxElapsedTime = streamMaxRetryTime
+	operation := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return errStreamThrottled
+		case resp.StatusCode >= http.StatusInternalServerError:
+			return fmt.Errorf("status code %d received", resp.StatusCode)
+		default:
+			return backoff.Permanent(fmt.Errorf("status code %d received", resp.StatusCode))
+		}
+	}
+	return backoff.Retry(operation, backoff.WithContext(exponentialBackoff, ctx))
+}
@@ -0,0 +1,123 @@
+package logwriter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/mocks"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/LambdaTest/test-at-scale/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStreamLogWriter(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	var mu sync.Mutex
+	var chunks []core.LogChunk
+	throttled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		// the first frame is throttled to check that it is retried
+		if !throttled {
+			throttled = true
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		var chunk core.LogChunk
+		if err := json.NewDecoder(r.Body).Decode(&chunk); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		chunks = append(chunks, chunk)
+	}))
+	defer server.Close()
+
+	payload := &core.Payload{OrgID: "org", BuildID: "build", TaskID: "task"}
+	writer := NewStreamLogWriter(server.URL, core.PurposeExecutionLogs, payload, logger)
+	logs := "first line\nsecond line\r\naws_secret_access_key=abcdefghijklmnopqrstuvwxyz0123456789ABCD\nlast line"
+	if err := <-writer.Write(context.TODO(), strings.NewReader(logs)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var lines []string
+	for i, chunk := range chunks {
+		assert.Equal(t, i, chunk.Seq)
+		assert.Equal(t, "task", chunk.TaskID)
+		assert.Equal(t, "build", chunk.BuildID)
+		assert.Equal(t, core.PurposeExecutionLogs, chunk.Purpose)
+		assert.Equal(t, chunks[0].StreamID, chunk.StreamID)
+		for _, line := range chunk.Lines {
+			assert.False(t, line.Time.IsZero())
+			lines = append(lines, line.Text)
+		}
+	}
+	assert.Equal(t, []string{"first line", "second line", "aws_secret_access_key=****************", "last line"}, lines)
+}
+
+func TestStreamLogWriterFrameSize(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	var mu sync.Mutex
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var chunk core.LogChunk
+		// the frames must fit in the limit of synapse
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, core.MaxLogChunkSize)).Decode(&chunk); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received += len(chunk.Lines)
+	}))
+	defer server.Close()
+
+	payload := &core.Payload{OrgID: "org", BuildID: "build", TaskID: "task"}
+	writer := NewStreamLogWriter(server.URL, core.PurposeExecutionLogs, payload, logger)
+	// control characters take 6 bytes each once escaped
+	logs := strings.Repeat(strings.Repeat("\x01", streamMaxLineLength/2)+"\n", 50)
+	if err := <-writer.Write(context.TODO(), strings.NewReader(logs)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 50, received)
+}
+
+func TestTeeLogWriter(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	first, second := new(bytes.Buffer), new(bytes.Buffer)
+	// the azure writer fails without reading the logs, which must not block the other writers
+	azureClient := new(mocks.AzureClient)
+	azureClient.On("GetSASURL", mock.Anything, core.PurposeExecutionLogs, mock.Anything).
+		Return("", errs.New("sas url not available"))
+	writer := NewTeeLogWriter(
+		NewBufferLogWriter("first", first, logger),
+		&AzureLogWriter{azureClient: azureClient, purpose: core.PurposeExecutionLogs, logger: logger},
+		NewBufferLogWriter("second", second, logger),
+	)
+	logs := strings.Repeat("some log line\n", 10000)
+	err = <-writer.Write(context.TODO(), strings.NewReader(logs))
+	assert.EqualError(t, err, "sas url not available")
+	assert.True(t, strings.HasSuffix(first.String(), logs))
+	assert.True(t, strings.HasSuffix(second.String(), logs))
+}
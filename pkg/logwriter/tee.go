@@ -0,0 +1,80 @@
+package logwriter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+)
+
+var errWriterDone = errors.New("log writer is done")
+
+// TeeLogWriter writes the logs to several log writers at once
+type TeeLogWriter struct {
+	writers []core.LogWriterStrategy
+}
+
+// NewTeeLogWriter returns a log writer writing the logs to all the writers
+func NewTeeLogWriter(writers ...core.LogWriterStrategy) core.LogWriterStrategy {
+	return &TeeLogWriter{writers: writers}
+}
+
+// Write copies the reader to every writer, the error returned is the first one of the writers.
+// A writer which is done before the end of the logs is skipped, so that it does not block the others.
+func (t *TeeLogWriter) Write(ctx context.Context, reader io.Reader) <-chan error {
+	errChan := make(chan error, 1)
+	pipeWriters := make([]*io.PipeWriter, len(t.writers))
+	writerErrs := make([]error, len(t.writers))
+	var wg sync.WaitGroup
+	for i, writer := range t.writers {
+		pipeReader, pipeWriter := io.Pipe()
+		pipeWriters[i] = pipeWriter
+		writerErrChan := writer.Write(ctx, pipeReader)
+		wg.Add(1)
+		go func(i int, pipeReader *io.PipeReader) {
+			defer wg.Done()
+			writerErrs[i] = <-writerErrChan
+			pipeReader.CloseWithError(errWriterDone)
+		}(i, pipeReader)
+	}
+	go func() {
+		buf := make([]byte, 32*1024)
+		active := len(pipeWriters)
+		for {
+			if active == 0 {
+				// the reader is drained, as its writer would block otherwise
+				_, _ = io.Copy(io.Discard, reader)
+				break
+			}
+			n, err := reader.Read(buf)
+			for i, pipeWriter := range pipeWriters {
+				if n == 0 || pipeWriter == nil {
+					continue
+				}
+				if _, writeErr := pipeWriter.Write(buf[:n]); writeErr != nil {
+					pipeWriters[i] = nil
+					active--
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		for _, pipeWriter := range pipeWriters {
+			if pipeWriter != nil {
+				pipeWriter.Close()
+			}
+		}
+		wg.Wait()
+		for _, err := range writerErrs {
+			if err != nil {
+				errChan <- err
+				break
+			}
+		}
+		close(errChan)
+	}()
+	return errChan
+}
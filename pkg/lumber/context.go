@@ -0,0 +1,65 @@
+package lumber
+
+import "context"
+
+// Fields of the loggers scoped to a context
+const (
+	FieldOrgID     = "orgID"
+	FieldBuildID   = "buildID"
+	FieldTaskID    = "taskID"
+	FieldJobID     = "jobID"
+	FieldSubModule = "subModule"
+	FieldStage     = "stage"
+)
+
+type loggerKey struct{}
+
+// contextLogger is the logger carried by a context, along with its base logger and fields
+// so that a field set again in a child context replaces the one of the parent.
+type contextLogger struct {
+	base   Logger
+	fields Fields
+	logger Logger
+}
+
+// ContextWithFields returns a copy of ctx carrying the logger of ctx with fields added to it,
+// logger is used if ctx does not carry any. The logger carrying the fields is returned as well.
+func ContextWithFields(ctx context.Context, logger Logger, fields Fields) (context.Context, Logger) {
+	scoped := &contextLogger{base: logger, fields: Fields{}}
+	if parent, ok := ctx.Value(loggerKey{}).(*contextLogger); ok {
+		scoped.base = parent.base
+		for key, value := range parent.fields {
+			scoped.fields[key] = value
+		}
+	}
+	for key, value := range fields {
+		scoped.fields[key] = value
+	}
+	scoped.logger = scoped.base.WithFields(scoped.fields)
+	return context.WithValue(ctx, loggerKey{}, scoped), scoped.logger
+}
+
+// FromContext returns the logger carried by ctx, or logger if ctx does not carry any.
+func FromContext(ctx context.Context, logger Logger) Logger {
+	if ctx == nil {
+		return logger
+	}
+	if scoped, ok := ctx.Value(loggerKey{}).(*contextLogger); ok {
+		return scoped.logger
+	}
+	return logger
+}
+
+// FieldsFromContext returns a copy of the fields of the logger carried by ctx.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields := Fields{}
+	if ctx == nil {
+		return fields
+	}
+	if scoped, ok := ctx.Value(loggerKey{}).(*contextLogger); ok {
+		for key, value := range scoped.fields {
+			fields[key] = value
+		}
+	}
+	return fields
+}
@@ -0,0 +1,56 @@
+package lumber
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type fieldsLogger struct {
+	Logger
+	fields Fields
+}
+
+func (l *fieldsLogger) WithFields(fields Fields) Logger {
+	return &fieldsLogger{fields: fields}
+}
+
+func TestContextWithFields(t *testing.T) {
+	base := &fieldsLogger{}
+	if got := FromContext(context.Background(), base); got != base {
+		t.Errorf("FromContext() = %v, want the fallback logger", got)
+	}
+
+	ctx, _ := ContextWithFields(context.Background(), base, Fields{FieldTaskID: "task", FieldStage: "discovery"})
+	subModuleCtx, logger := ContextWithFields(ctx, nil, Fields{FieldSubModule: "web", FieldStage: "prerun"})
+
+	want := Fields{FieldTaskID: "task", FieldSubModule: "web", FieldStage: "prerun"}
+	if got := logger.(*fieldsLogger).fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("ContextWithFields() fields = %v, want %v", got, want)
+	}
+	if got := FromContext(subModuleCtx, base); got != logger {
+		t.Errorf("FromContext() = %v, want the logger of the context", got)
+	}
+	// the fields of the parent context are left as is
+	want = Fields{FieldTaskID: "task", FieldStage: "discovery"}
+	if got := FromContext(ctx, base).(*fieldsLogger).fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("parent context fields = %v, want %v", got, want)
+	}
+}
+
+func TestFieldsFromContext(t *testing.T) {
+	if got := FieldsFromContext(context.Background()); len(got) != 0 {
+		t.Errorf("FieldsFromContext() = %v, want no fields", got)
+	}
+	ctx, _ := ContextWithFields(context.Background(), &fieldsLogger{}, Fields{FieldTaskID: "task", FieldSubModule: "web"})
+	fields := FieldsFromContext(ctx)
+	want := Fields{FieldTaskID: "task", FieldSubModule: "web"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("FieldsFromContext() = %v, want %v", fields, want)
+	}
+	// the fields returned are a copy
+	fields[FieldStage] = "prerun"
+	if got := FieldsFromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldsFromContext() = %v, want %v", got, want)
+	}
+}
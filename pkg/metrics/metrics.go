@@ -0,0 +1,128 @@
+// Package metrics exposes the prometheus metrics of nucleus and synapse.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+)
+
+const namespace = "nucleus"
+
+// Stages of the task whose durations are recorded
+const (
+	StageClone         = "clone"
+	StageCacheDownload = "cache_download"
+	StageCacheUpload   = "cache_upload"
+	StagePreRun        = "prerun"
+	StageDiscovery     = "discovery"
+	StageExecution     = "execution"
+	StagePostRun       = "postrun"
+)
+
+// Results of the cache lookups
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	stageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of the stages of the task.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"stage"})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cache lookups by result.",
+	}, []string{"result"})
+
+	tests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tests_total",
+		Help:      "Number of executed tests by status.",
+	}, []string{"status"})
+
+	runnerExitCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runner_exit_codes_total",
+		Help:      "Number of test runner exits by stage and exit code.",
+	}, []string{"stage", "code"})
+)
+
+func init() {
+	registry.MustRegister(stageDuration, cacheRequests, tests, runnerExitCodes, newProcessCollector(os.Getpid()))
+}
+
+// ObserveStage records the duration of stage started at start, it is meant to be deferred
+func ObserveStage(stage string, start time.Time) {
+	stageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// RecordCacheResult counts a cache lookup as a hit or a miss
+func RecordCacheResult(hit bool) {
+	result := CacheMiss
+	if hit {
+		result = CacheHit
+	}
+	cacheRequests.WithLabelValues(result).Inc()
+}
+
+// RecordTest counts an executed test by its status
+func RecordTest(status string) {
+	tests.WithLabelValues(status).Inc()
+}
+
+// RecordRunnerExit counts the exit code of the test runner of stage, from the error returned on its completion
+func RecordRunnerExit(stage string, err error) {
+	code := 0
+	if err != nil {
+		code = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+	}
+	runnerExitCodes.WithLabelValues(stage, strconv.Itoa(code)).Inc()
+}
+
+// Handler returns the http handler serving the metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// WriteSnapshot writes the current metrics to path in the prometheus text format,
+// so that the metrics of short lived containers are not lost.
+func WriteSnapshot(path string) error {
+	families, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(file, family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
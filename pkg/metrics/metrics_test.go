@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	ObserveStage(StageClone, time.Now().Add(-2*time.Second))
+	RecordCacheResult(true)
+	RecordCacheResult(false)
+	RecordCacheResult(false)
+	RecordTest("passed")
+	RecordTest("failed")
+	RecordRunnerExit(StageExecution, nil)
+	RecordRunnerExit(StageExecution, exec.Command("/bin/sh", "-c", "exit 3").Run())
+	RecordRunnerExit(StageDiscovery, errors.New("runner not found"))
+
+	wantLines := []string{
+		`nucleus_stage_duration_seconds_count{stage="clone"} 1`,
+		`nucleus_cache_requests_total{result="hit"} 1`,
+		`nucleus_cache_requests_total{result="miss"} 2`,
+		`nucleus_tests_total{status="passed"} 1`,
+		`nucleus_tests_total{status="failed"} 1`,
+		`nucleus_runner_exit_codes_total{code="0",stage="execution"} 1`,
+		`nucleus_runner_exit_codes_total{code="3",stage="execution"} 1`,
+		`nucleus_runner_exit_codes_total{code="-1",stage="discovery"} 1`,
+		`nucleus_process_memory_bytes`,
+	}
+
+	resp := httptest.NewRecorder()
+	Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Handler() responseCode = %v, want = %v", resp.Code, http.StatusOK)
+	}
+	path := filepath.Join(t.TempDir(), "logs", "metrics.prom")
+	if err := WriteSnapshot(path); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	snapshot, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot, error: %v", err)
+	}
+
+	for name, body := range map[string]string{"Handler()": resp.Body.String(), "WriteSnapshot()": string(snapshot)} {
+		for _, line := range wantLines {
+			if !strings.Contains(body, line) {
+				t.Errorf("%s metrics do not contain %q, got:\n%s", name, line, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/LambdaTest/test-at-scale/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// processCollector collects the cpu and memory usage of a process from procfs on every scrape
+type processCollector struct {
+	pid         int32
+	mu          sync.Mutex
+	proc        *procfs.Proc
+	cpuPercent  *prometheus.Desc
+	memory      *prometheus.Desc
+	memSwapped  *prometheus.Desc
+	memPercent  *prometheus.Desc
+	collectTime *prometheus.Desc
+}
+
+func newProcessCollector(pid int) *processCollector {
+	return &processCollector{
+		pid: int32(pid),
+		cpuPercent: prometheus.NewDesc(prometheus.BuildFQName(namespace, "process", "cpu_percent"),
+			"CPU usage of the nucleus process in percent.", nil, nil),
+		memory: prometheus.NewDesc(prometheus.BuildFQName(namespace, "process", "memory_bytes"),
+			"Memory consumed by the nucleus process in bytes.", nil, nil),
+		memSwapped: prometheus.NewDesc(prometheus.BuildFQName(namespace, "process", "memory_swapped_bytes"),
+			"Memory of the nucleus process swapped out in bytes.", nil, nil),
+		memPercent: prometheus.NewDesc(prometheus.BuildFQName(namespace, "process", "memory_percent"),
+			"Memory consumed by the nucleus process in percent of the machine memory.", nil, nil),
+		collectTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "process", "stats_timestamp_seconds"),
+			"Time at which the process stats were recorded.", nil, nil),
+	}
+}
+
+func (p *processCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.cpuPercent
+	ch <- p.memory
+	ch <- p.memSwapped
+	ch <- p.memPercent
+	ch <- p.collectTime
+}
+
+// Collect skips the process stats when they can not be read, so that the other metrics are still served
+func (p *processCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := p.getStats()
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(p.cpuPercent, prometheus.GaugeValue, stats.CPUPercentage)
+	ch <- prometheus.MustNewConstMetric(p.memory, prometheus.GaugeValue, float64(stats.MemConsumed))
+	ch <- prometheus.MustNewConstMetric(p.memSwapped, prometheus.GaugeValue, float64(stats.MemSwapped))
+	ch <- prometheus.MustNewConstMetric(p.memPercent, prometheus.GaugeValue, stats.MemPercentage)
+	ch <- prometheus.MustNewConstMetric(p.collectTime, prometheus.GaugeValue, float64(stats.RecordTime.Unix()))
+}
+
+// getStats reads the process stats, the cpu percent is measured since the previous read,
+// so the same proc is reused across the scrapes
+func (p *processCollector) getStats() (*procfs.Stats, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.proc == nil {
+		proc, err := procfs.New(p.pid, 0, false)
+		if err != nil {
+			return nil, err
+		}
+		p.proc = proc
+	}
+	return p.proc.GetStats()
+}
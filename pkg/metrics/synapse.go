@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const synapseNamespace = "synapse"
+
+// Results of the image pulls and volume cleanups
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	synapseRegistry = prometheus.NewRegistry()
+
+	websocketConnected = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: synapseNamespace,
+		Name:      "websocket_connected",
+		Help:      "Whether the websocket connection to LT cloud is open.",
+	})
+
+	websocketReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: synapseNamespace,
+		Name:      "websocket_reconnects_total",
+		Help:      "Number of reconnections after the websocket connection broke.",
+	})
+
+	runningContainers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: synapseNamespace,
+		Name:      "running_containers",
+		Help:      "Number of containers running on synapse.",
+	})
+
+	jobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: synapseNamespace,
+		Name:      "jobs_total",
+		Help:      "Number of finished jobs by status.",
+	}, []string{"status"})
+
+	imagePullDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: synapseNamespace,
+		Name:      "image_pull_duration_seconds",
+		Help:      "Duration of the image pulls by result.",
+		Buckets:   []float64{0.5, 1, 5, 15, 30, 60, 120, 300, 600},
+	}, []string{"result"})
+
+	volumeCleanups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: synapseNamespace,
+		Name:      "volume_cleanups_total",
+		Help:      "Number of expired volumes removed by result.",
+	}, []string{"result"})
+
+	cpuAvailable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: synapseNamespace,
+		Name:      "cpu_available",
+		Help:      "Number of cpus available to the containers.",
+	})
+
+	cpuReserved = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: synapseNamespace,
+		Name:      "cpu_reserved",
+		Help:      "Number of cpus reserved by the running jobs.",
+	})
+
+	ramAvailable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: synapseNamespace,
+		Name:      "ram_available_mebibytes",
+		Help:      "Memory available to the containers in MiB.",
+	})
+
+	ramReserved = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: synapseNamespace,
+		Name:      "ram_reserved_mebibytes",
+		Help:      "Memory reserved by the running jobs in MiB.",
+	})
+
+	messageQueues = newQueueCollector(prometheus.BuildFQName(synapseNamespace, "", "queued_messages"),
+		"Number of messages queued to be written to the websocket by queue.")
+)
+
+func init() {
+	synapseRegistry.MustRegister(websocketConnected, websocketReconnects, runningContainers, jobs,
+		imagePullDuration, volumeCleanups, cpuAvailable, cpuReserved, ramAvailable, ramReserved, messageQueues)
+}
+
+// SetConnected records the state of the websocket connection
+func SetConnected(connected bool) {
+	if connected {
+		websocketConnected.Set(1)
+		return
+	}
+	websocketConnected.Set(0)
+}
+
+// RecordReconnect counts a reconnection of the websocket
+func RecordReconnect() {
+	websocketReconnects.Inc()
+}
+
+// SetRunningContainers records the number of running containers
+func SetRunningContainers(count int) {
+	runningContainers.Set(float64(count))
+}
+
+// RecordJob counts a finished job by its status
+func RecordJob(status string) {
+	jobs.WithLabelValues(status).Inc()
+}
+
+// ObserveImagePull records the duration of an image pull started at start, err is the error of the pull
+func ObserveImagePull(start time.Time, err error) {
+	imagePullDuration.WithLabelValues(result(err)).Observe(time.Since(start).Seconds())
+}
+
+// RecordVolumeCleanup counts the removal of an expired volume, err is the error of the removal
+func RecordVolumeCleanup(err error) {
+	volumeCleanups.WithLabelValues(result(err)).Inc()
+}
+
+// SetResourcesAvailable records the cpus and the memory in MiB available to the containers
+func SetResourcesAvailable(cpu float32, ram int64) {
+	cpuAvailable.Set(float64(cpu))
+	ramAvailable.Set(float64(ram))
+}
+
+// ReserveResources records the cpus and the memory in MiB reserved by a job
+func ReserveResources(cpu float32, ram int64) {
+	cpuReserved.Add(float64(cpu))
+	ramReserved.Add(float64(ram))
+}
+
+// ReleaseResources records the release of the resources reserved by a job
+func ReleaseResources(cpu float32, ram int64) {
+	cpuReserved.Sub(float64(cpu))
+	ramReserved.Sub(float64(ram))
+}
+
+// RegisterQueue reports the length of the message queue on every scrape
+func RegisterQueue(queue string, length func() int) {
+	messageQueues.register(queue, length)
+}
+
+// SynapseHandler returns the http handler serving the metrics of synapse
+func SynapseHandler() http.Handler {
+	return promhttp.HandlerFor(synapseRegistry, promhttp.HandlerOpts{})
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// queueCollector collects the length of the registered queues
+type queueCollector struct {
+	mu     sync.Mutex
+	desc   *prometheus.Desc
+	queues map[string]func() int
+}
+
+func newQueueCollector(name, help string) *queueCollector {
+	return &queueCollector{
+		desc:   prometheus.NewDesc(name, help, []string{"queue"}, nil),
+		queues: map[string]func() int{},
+	}
+}
+
+func (q *queueCollector) register(queue string, length func() int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queues[queue] = length
+}
+
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- q.desc
+}
+
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for queue, length := range q.queues {
+		ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, float64(length()), queue)
+	}
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSynapseMetrics(t *testing.T) {
+	queue := make(chan []byte, 4)
+	queue <- []byte("message")
+	RegisterQueue("message", func() int { return len(queue) })
+	SetConnected(true)
+	RecordReconnect()
+	SetRunningContainers(2)
+	RecordJob("complete")
+	ObserveImagePull(time.Now(), nil)
+	ObserveImagePull(time.Now(), errors.New("pull access denied"))
+	RecordVolumeCleanup(nil)
+	SetResourcesAvailable(8, 16384)
+	ReserveResources(2, 4096)
+	ReserveResources(1, 2048)
+	ReleaseResources(2, 4096)
+
+	wantLines := []string{
+		`synapse_queued_messages{queue="message"} 1`,
+		`synapse_websocket_connected 1`,
+		`synapse_websocket_reconnects_total 1`,
+		`synapse_running_containers 2`,
+		`synapse_jobs_total{status="complete"} 1`,
+		`synapse_image_pull_duration_seconds_count{result="success"} 1`,
+		`synapse_image_pull_duration_seconds_count{result="failure"} 1`,
+		`synapse_volume_cleanups_total{result="success"} 1`,
+		`synapse_cpu_available 8`,
+		`synapse_cpu_reserved 1`,
+		`synapse_ram_available_mebibytes 16384`,
+		`synapse_ram_reserved_mebibytes 2048`,
+	}
+
+	resp := httptest.NewRecorder()
+	SynapseHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("SynapseHandler() responseCode = %v, want = %v", resp.Code, http.StatusOK)
+	}
+	body := resp.Body.String()
+	for _, line := range wantLines {
+		if !strings.Contains(body, line) {
+			t.Errorf("SynapseHandler() metrics do not contain %q, got:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "nucleus_") {
+		t.Errorf("SynapseHandler() metrics contain the metrics of nucleus, got:\n%s", body)
+	}
+}
@@ -0,0 +1,94 @@
+// Package procutils runs the commands of nucleus in their own process groups, so that the processes they
+// spawn are terminated along with them.
+package procutils
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/LambdaTest/test-at-scale/pkg/cgroup"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+var (
+	// mu is held while a child is started or reaped, so that an orphan reaper never waits for a child of nucleus
+	mu sync.Mutex
+	// children are the processes started by nucleus that are yet to be waited for
+	children = make(map[int]struct{})
+)
+
+// Start starts cmd in a new process group. Once ctx is done the group is sent SIGTERM, and SIGKILL once the
+// grace period of ctx has passed, which terminates the processes spawned by cmd along with it.
+// The returned function waits for cmd to exit and must be called in place of cmd.Wait.
+func Start(ctx context.Context, cmd *exec.Cmd, logger lumber.Logger) (func() error, error) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	if group := cgroup.FromContext(ctx); group != nil {
+		if err := group.Wrap(cmd); err != nil {
+			return nil, err
+		}
+	}
+	mu.Lock()
+	if err := cmd.Start(); err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+	pid := cmd.Process.Pid
+	children[pid] = struct{}{}
+	mu.Unlock()
+
+	exited := terminateOnDone(ctx, pid, logger)
+	return func() error {
+		err := cmd.Wait()
+		exited()
+		mu.Lock()
+		delete(children, pid)
+		mu.Unlock()
+		return err
+	}, nil
+}
+
+// Run starts cmd in a new process group and waits for it to exit, see Start
+func Run(ctx context.Context, cmd *exec.Cmd, logger lumber.Logger) error {
+	wait, err := Start(ctx, cmd, logger)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+// terminateOnDone forwards the cancellation of ctx to the process group pgid as SIGTERM and kills the group
+// once the grace period of ctx has passed. The returned function must be called when the group leader exits.
+func terminateOnDone(ctx context.Context, pgid int, logger lumber.Logger) func() {
+	graceCtx, cancel := core.GraceContext(ctx)
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-exited:
+			return
+		case <-ctx.Done():
+		}
+		logger.Debugf("sending SIGTERM to process group %d", pgid)
+		if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+			logger.Errorf("failed to send SIGTERM to process group %d, error: %v", pgid, err)
+		}
+		select {
+		case <-exited:
+			return
+		case <-graceCtx.Done():
+		}
+		logger.Warnf("process group %d did not exit within the grace period, killing it", pgid)
+		if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil {
+			logger.Errorf("failed to kill process group %d, error: %v", pgid, err)
+		}
+	}()
+	return func() {
+		close(exited)
+		cancel()
+	}
+}
@@ -0,0 +1,103 @@
+package procutils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+)
+
+// These tests are meant to be run on a Linux machine
+
+func TestStart(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialise logger, error: %v", err)
+	}
+	tests := []struct {
+		name       string
+		script     string
+		wantSignal syscall.Signal
+	}{
+		// the runner flushes its results and exits on SIGTERM
+		{"group exits on SIGTERM", "trap 'exit 0' TERM; sleep 10 & wait", 0},
+		{"group ignoring SIGTERM is killed", "trap '' TERM; sleep 10", syscall.SIGKILL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(core.ContextWithGracePeriod(context.Background(), 200*time.Millisecond))
+			cmd := exec.Command("sh", "-c", tt.script)
+			wait, err := Start(ctx, cmd, logger)
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			// give the shell time to set up its trap
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			_ = wait()
+			status := cmd.ProcessState.Sys().(syscall.WaitStatus)
+			if tt.wantSignal == 0 && !status.Exited() {
+				t.Errorf("command exited with %v, want a clean exit", cmd.ProcessState)
+			}
+			if tt.wantSignal != 0 && (!status.Signaled() || status.Signal() != tt.wantSignal) {
+				t.Errorf("command exited with %v, want signal %v", cmd.ProcessState, tt.wantSignal)
+			}
+		})
+	}
+}
+
+func TestRunKillsProcessTree(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Errorf("Couldn't initialise logger, error: %v", err)
+	}
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	// the grandchild stands in for a dev server started by the tests
+	cmd := exec.Command("bash", "-c", "sleep 30 & echo $! > "+pidFile+"; wait")
+	if err := Run(ctx, cmd, logger); err == nil {
+		t.Fatalf("Run() error = nil, want the command to be terminated")
+	}
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("failed to read pid of grandchild, error: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("failed to parse pid of grandchild, error: %v", err)
+	}
+	// the grandchild is a zombie of the test process at most, as it is not reparented here
+	deadline := time.Now().Add(time.Second)
+	for {
+		state, _, statErr := readStat(pid)
+		if statErr != nil || state == "Z" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("grandchild %d is still running in state %s after the command was terminated", pid, state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReadStat(t *testing.T) {
+	state, ppid, err := readStat(os.Getpid())
+	if err != nil {
+		t.Fatalf("readStat() error = %v", err)
+	}
+	if state != "R" && state != "S" {
+		t.Errorf("readStat() state = %v, want the state of a running process", state)
+	}
+	if ppid != os.Getppid() {
+		t.Errorf("readStat() ppid = %v, want %v", ppid, os.Getppid())
+	}
+}
@@ -0,0 +1,105 @@
+package procutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+// ReapOrphans reaps the zombie orphans reparented to nucleus when it runs as PID 1 of the container,
+// e.g. the processes left behind by a killed process group. It returns when ctx is done and does nothing
+// when nucleus is not PID 1.
+func ReapOrphans(ctx context.Context, logger lumber.Logger) {
+	if os.Getpid() != 1 {
+		return
+	}
+	logger.Debugf("running as PID 1, reaping orphaned processes")
+	sigChld := make(chan os.Signal, 1)
+	signal.Notify(sigChld, syscall.SIGCHLD)
+	defer signal.Stop(sigChld)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigChld:
+			reap(logger)
+		}
+	}
+}
+
+// reap waits for the zombie children of nucleus which were not started by Start, the ones started by Start
+// are waited for by their callers
+func reap(logger lumber.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	pids, err := zombieChildren(os.Getpid())
+	if err != nil {
+		logger.Errorf("failed to list zombie processes, error: %v", err)
+		return
+	}
+	for _, pid := range pids {
+		if _, ok := children[pid]; ok {
+			continue
+		}
+		var status syscall.WaitStatus
+		if _, err := syscall.Wait4(pid, &status, syscall.WNOHANG, nil); err != nil {
+			logger.Errorf("failed to reap orphaned process %d, error: %v", pid, err)
+			continue
+		}
+		logger.Debugf("reaped orphaned process %d, exit status %d", pid, status.ExitStatus())
+	}
+}
+
+// zombieChildren returns the pids of the zombie children of ppid
+func zombieChildren(ppid int) ([]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		state, parent, err := readStat(pid)
+		if err != nil {
+			// the process exited and was reaped while listing
+			continue
+		}
+		if state == "Z" && parent == ppid {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// readStat returns the state and the parent pid of pid from /proc/<pid>/stat
+func readStat(pid int) (state string, ppid int, err error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return "", 0, err
+	}
+	// the command name may contain spaces, the fields following it are separated by spaces
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return "", 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return "", 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	ppid, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, err
+	}
+	return fields[0], ppid, nil
+}
@@ -0,0 +1,38 @@
+package proxyserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+)
+
+const logStreamRetryAge = "1"
+
+// HandlerLogStream handles the log chunks streamed by nucleus, which are sent to LT cloud over the websocket.
+// Too Many Requests is returned when the log spool of synapse is full, asking nucleus to retry later.
+func (ph *ProxyHandler) HandlerLogStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var chunk core.LogChunk
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, core.MaxLogChunkSize)).Decode(&chunk); err != nil {
+		ph.logger.Errorf("error decoding log chunk, error: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ph.synapse.SendLogChunk(&chunk); err != nil {
+		if errors.Is(err, errs.ErrLogQueueFull) {
+			w.Header().Set("Retry-After", logStreamRetryAge)
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		ph.logger.Errorf("error sending log chunk of task %s, error: %v", chunk.TaskID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
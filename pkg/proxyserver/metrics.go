@@ -0,0 +1,65 @@
+package proxyserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+)
+
+// healthStatus is the response of the health endpoint
+type healthStatus struct {
+	Connected bool `json:"connected"`
+}
+
+// HandlerHealth reports whether synapse is connected to LT cloud, Service Unavailable is returned
+// while the websocket connection is down
+func HandlerHealth(synapse core.SynapseManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := healthStatus{Connected: synapse.Connected()}
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Connected {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	}
+}
+
+// ListenAndServeMetrics starts the http server serving the metrics and the health of synapse,
+// which is kept apart from the proxy server so that it can be exposed to the operators alone
+func ListenAndServeMetrics(ctx context.Context,
+	synapse core.SynapseManager,
+	config *config.SynapseConfig,
+	logger lumber.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.SynapseHandler())
+	mux.HandleFunc("/healthz", HandlerHealth(synapse))
+	srv := &http.Server{
+		Addr:    ":" + config.MetricsPort,
+		Handler: mux,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		logger.Infof("Starting metrics server on port %s", config.MetricsPort)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("metrics server listen: %v", err)
+			errChan <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Infof("Caller has requested graceful shutdown. shutting down the metrics server")
+		if err := srv.Shutdown(context.Background()); err != nil {
+			logger.Errorf("metrics server shutdown, error: %v", err)
+		}
+		return nil
+	case err := <-errChan:
+		return err
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 
+	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 	"github.com/spf13/viper"
@@ -14,22 +15,24 @@ import (
 
 // ProxyHandler defines struct for proxy handler
 type ProxyHandler struct {
-	remote *url.URL
-	logger lumber.Logger
+	remote  *url.URL
+	synapse core.SynapseManager
+	logger  lumber.Logger
 }
 
 const synapseURL = "/synapse"
 
 // NewProxyHandler returns pointer of new instace of ProxyHandler
-func NewProxyHandler(logger lumber.Logger) (*ProxyHandler, error) {
+func NewProxyHandler(synapse core.SynapseManager, logger lumber.Logger) (*ProxyHandler, error) {
 	remote, err := url.Parse(global.TASCloudURL[viper.GetString("env")])
 	if err != nil {
 		return nil, err
 	}
 
 	return &ProxyHandler{
-		remote: remote,
-		logger: logger,
+		remote:  remote,
+		synapse: synapse,
+		logger:  logger,
 	}, nil
 }
 
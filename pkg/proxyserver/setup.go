@@ -17,10 +17,13 @@ func ListenAndServe(ctx context.Context, proxyHandler *ProxyHandler, config *con
 
 	errChan := make(chan error)
 
+	mux := http.NewServeMux()
+	mux.HandleFunc(global.LogStreamPath, proxyHandler.HandlerLogStream)
+	mux.HandleFunc("/", proxyHandler.HandlerProxy)
 	// HTTP server instance
 	srv := &http.Server{
 		Addr:    ":" + global.ProxyServerPort,
-		Handler: http.HandlerFunc(proxyHandler.HandlerProxy),
+		Handler: mux,
 	}
 	// channel to signal server process exit
 	done := make(chan struct{})
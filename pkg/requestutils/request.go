@@ -13,6 +13,7 @@ import (
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
 	"github.com/cenkalti/backoff/v4"
 )
 
@@ -37,9 +38,10 @@ func (r *requests) MakeAPIRequest(
 	query map[string]interface{},
 	headers map[string]string,
 ) (respBody []byte, statusCode int, err error) {
+	logger := lumber.FromContext(ctx, r.logger)
 	u, err := url.Parse(endpoint)
 	if err != nil {
-		r.logger.Errorf("error while parsing endpoint %s, %v", endpoint, err)
+		logger.Errorf("error while parsing endpoint %s, %v", endpoint, err)
 		return nil, 0, err
 	}
 	q := u.Query()
@@ -59,17 +61,19 @@ func (r *requests) MakeAPIRequest(
 	u.RawQuery = q.Encode()
 	req, err := http.NewRequestWithContext(ctx, httpMethod, u.String(), bytes.NewBuffer(body))
 	if err != nil {
-		r.logger.Errorf("error while creating http request %v", err)
+		logger.Errorf("error while creating http request %v", err)
 		return nil, 0, err
 	}
 	for id, val := range headers {
 		req.Header.Add(id, val)
 	}
+	// the trace of the task is carried on to the services it calls
+	tracing.InjectHeaders(ctx, req.Header)
 
 	operation := func() error {
 		resp, errD := r.client.Do(req)
 		if errD != nil {
-			r.logger.Errorf("error while sending http request %v", errD)
+			logger.Errorf("error while sending http request %v", errD)
 			return errD
 		}
 		defer resp.Body.Close()
@@ -79,17 +83,17 @@ func (r *requests) MakeAPIRequest(
 		}
 		respBody, err = io.ReadAll(resp.Body)
 		if err != nil {
-			r.logger.Errorf("error while reading http response body %v", err)
+			logger.Errorf("error while reading http response body %v", err)
 			return nil
 		}
 		return nil
 	}
 	if errR := backoff.Retry(operation, r.retryBackoff); errR != nil {
-		r.logger.Errorf("Retry limit exceeded. Error %+v", errR)
+		logger.Errorf("Retry limit exceeded. Error %+v", errR)
 		return respBody, statusCode, errors.New("retry limit exceeded")
 	}
 	if statusCode != http.StatusOK {
-		r.logger.Errorf("non 200 status code %s", statusCode)
+		logger.Errorf("non 200 status code %s", statusCode)
 		return respBody, statusCode, errors.New("non 200 status code")
 	}
 	return respBody, statusCode, err
@@ -18,6 +18,7 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
 	"github.com/LambdaTest/test-at-scale/pkg/synapse"
 	"github.com/LambdaTest/test-at-scale/pkg/utils"
 	"github.com/docker/docker/api/types"
@@ -127,24 +128,25 @@ func (d *docker) CopyFileToContainer(ctx context.Context, path, fileName, contai
 }
 
 func (d *docker) Create(ctx context.Context, r *core.RunnerOptions) core.ContainerStatus {
+	logger := lumber.FromContext(ctx, d.logger)
 	containerStatus := core.ContainerStatus{Done: true}
 	containerImageConfig, err := d.secretsManager.GetDockerSecrets(r)
 	if err != nil {
-		d.logger.Errorf("Something went wrong while seeking docker secrets %+v", err)
+		logger.Errorf("Something went wrong while seeking docker secrets %+v", err)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ERR_DOCKER_CRT(err.Error())
 		return containerStatus
 	}
 
 	if err = d.CreateVolume(ctx, r); err != nil {
-		d.logger.Errorf("Error in creating docker volume: %+v", err)
+		logger.Errorf("Error in creating docker volume: %+v", err)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ErrDockerVolCrt(err.Error())
 		return containerStatus
 	}
 
 	if errP := d.PullImage(&containerImageConfig, r); errP != nil {
-		d.logger.Errorf("Something went wrong while pulling container image %+v", errP)
+		logger.Errorf("Something went wrong while pulling container image %+v", errP)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ERR_DOCKER_CRT(errP.Error())
 		return containerStatus
@@ -153,7 +155,7 @@ func (d *docker) Create(ctx context.Context, r *core.RunnerOptions) core.Contain
 	hostConfig := d.getContainerHostConfiguration(r)
 	networkConfig, err := d.getContainerNetworkConfiguration()
 	if err != nil {
-		d.logger.Errorf("error retrieving network: %v", err)
+		logger.Errorf("error retrieving network: %v", err)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ERR_DOCKER_CRT(err.Error())
 		return containerStatus
@@ -162,17 +164,22 @@ func (d *docker) Create(ctx context.Context, r *core.RunnerOptions) core.Contain
 	resp, err := d.client.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, nil, containerName)
 	r.ContainerID = resp.ID
 	if err != nil {
-		d.logger.Errorf("error creating container: %v", err)
+		logger.Errorf("error creating container: %v", err)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ERR_DOCKER_CRT(err.Error())
 		return containerStatus
 	}
-	d.logger.Debugf("container created with name: %s, updating status %+v",
+	logger.Debugf("container created with name: %s, updating status %+v",
 		fmt.Sprintf("%s-%s", r.ContainerName, r.PodType), containerStatus)
 
-	gitSecretBytes, err := d.secretsManager.GetGitSecretBytes()
+	// the git provider and host labels are optional, the credential is then matched by the repo only
+	gitSecretBytes, err := d.secretsManager.GetGitSecretBytes(&core.GitRepo{
+		Provider: r.Label[synapse.GitProvider],
+		Host:     r.Label[synapse.GitHost],
+		Slug:     r.Label[synapse.Repo],
+	})
 	if err != nil {
-		d.logger.Errorf("Error in loading git secrets: %s", err.Error())
+		logger.Errorf("Error in loading git secrets: %s", err.Error())
 		containerStatus.Done = false
 		containerStatus.Error = errs.ErrSecretLoad(err.Error())
 		return containerStatus
@@ -190,9 +197,9 @@ func (d *docker) Create(ctx context.Context, r *core.RunnerOptions) core.Contain
 	}
 
 	// copies repo secrets to container
-	repoSecretBytes, err := d.secretsManager.GetRepoSecretBytes(r.Label["repo"])
+	repoSecretBytes, err := d.secretsManager.GetRepoSecretBytes(r.Label[synapse.Repo], synapse.GetBuild(r))
 	if err != nil {
-		d.logger.Debugf("Error in loading repo secrets: %s", err.Error())
+		logger.Debugf("Error in loading repo secrets: %s", err.Error())
 	} else {
 		if err := d.CopyFileToContainer(
 			ctx,
@@ -206,17 +213,38 @@ func (d *docker) Create(ctx context.Context, r *core.RunnerOptions) core.Contain
 			return containerStatus
 		}
 	}
+
+	// copies the policy of repo secrets to container
+	secretPolicyBytes, err := d.secretsManager.GetSecretPolicyBytes()
+	if err != nil {
+		containerStatus.Done = false
+		containerStatus.Error = errs.ErrDockerCP(err.Error())
+		return containerStatus
+	}
+	if err := d.CopyFileToContainer(
+		ctx,
+		global.VaultSecretDir,
+		global.SecretPolicyFileName,
+		r.ContainerID,
+		secretPolicyBytes,
+	); err != nil {
+		containerStatus.Done = false
+		containerStatus.Error = errs.ErrDockerCP(err.Error())
+		return containerStatus
+	}
 	return containerStatus
 }
 
 func (d *docker) Destroy(ctx context.Context, r *core.RunnerOptions) error {
+	logger := lumber.FromContext(ctx, d.logger)
+	defer d.stopServices(ctx, r)
 	if err := d.client.ContainerStop(ctx, r.ContainerID, &gracefulyContainerStopDuration); err != nil {
-		d.logger.Errorf("error stopping container %v", err)
+		logger.Errorf("error stopping container %v", err)
 		return err
 	}
 	autoRemove, err := strconv.ParseBool(os.Getenv(global.AutoRemoveEnv))
 	if err != nil {
-		d.logger.Errorf("Error reading AutoRemove os env error: %v", err)
+		logger.Errorf("Error reading AutoRemove os env error: %v", err)
 		return errors.New("error reading AutoRemove os env error")
 	}
 	if autoRemove {
@@ -228,25 +256,27 @@ func (d *docker) Destroy(ctx context.Context, r *core.RunnerOptions) error {
 		Force:         true,
 	})
 	if err != nil {
-		d.logger.Errorf("error removing container %v", err)
+		logger.Errorf("error removing container %v", err)
 		return err
 	}
 	return nil
 }
 
 func (d *docker) Run(ctx context.Context, r *core.RunnerOptions) core.ContainerStatus {
+	logger := lumber.FromContext(ctx, d.logger)
 	containerStatus := core.ContainerStatus{Done: true}
-	d.logger.Debugf("running container %s", r.ContainerID)
+	logger.Debugf("running container %s", r.ContainerID)
 	if err := d.client.ContainerStart(ctx, r.ContainerID, types.ContainerStartOptions{}); err != nil {
-		d.logger.Errorf("error starting the container: %s", err)
+		logger.Errorf("error starting the container: %s", err)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ERR_DOCKER_STRT(err.Error())
 		return containerStatus
 	}
 	d.RunningContainers = append(d.RunningContainers, r)
+	metrics.SetRunningContainers(len(d.RunningContainers))
 
 	if err := d.writeLogs(ctx, r); err != nil {
-		d.logger.Errorf("error writing logs to stdout: %+v", err)
+		logger.Errorf("error writing logs to stdout: %+v", err)
 	}
 
 	return containerStatus
@@ -274,17 +304,18 @@ func removeContainerID(slice []*core.RunnerOptions, r *core.RunnerOptions) []*co
 }
 
 func (d *docker) WaitForCompletion(ctx context.Context, r *core.RunnerOptions) error {
-	d.logger.Infof("waiting for  container %s compeletion", r.ContainerID)
+	logger := lumber.FromContext(ctx, d.logger)
+	logger.Infof("waiting for  container %s compeletion", r.ContainerID)
 	statusCh, errCh := d.client.ContainerWait(ctx, r.ContainerID, container.WaitConditionRemoved)
 
 	select {
 	case err := <-errCh:
 		if err != nil {
-			d.logger.Debugf("%s container terminated with exit code: %d, reason %s", r.ContainerID, err)
+			logger.Debugf("%s container terminated with exit code: %d, reason %s", r.ContainerID, err)
 			return err
 		}
 	case status := <-statusCh:
-		d.logger.Debugf("status code: %d", status.StatusCode)
+		logger.Debugf("status code: %d", status.StatusCode)
 		if status.StatusCode != 0 {
 			msg := fmt.Sprintf("Received non zero status code %v", status.StatusCode)
 			return errs.ERR_DOCKER_RUN(msg)
@@ -299,17 +330,33 @@ func (d *docker) GetInfo(ctx context.Context) (cpu float32, ram int64) {
 }
 
 func (d *docker) Initiate(ctx context.Context, r *core.RunnerOptions, statusChan chan core.ContainerStatus) {
+	logger := lumber.FromContext(ctx, d.logger)
 	// creating the docker contaienr
 	r.ContainerArgs = append(r.ContainerArgs, "--local", os.Getenv(global.LocalEnv), "--synapsehost", os.Getenv(global.SynapseHostEnv))
+	if len(r.Services) > 0 {
+		defer d.stopServices(context.Background(), r)
+		if status := d.startServices(ctx, r); !status.Done {
+			logger.Errorf("error starting services: %v", status.Error)
+			statusChan <- status
+			return
+		}
+	}
 	if status := d.Create(ctx, r); !status.Done {
-		d.logger.Errorf("error creating container: %v", status.Error)
-		d.logger.Infof("Update error status after creation")
+		logger.Errorf("error creating container: %v", status.Error)
+		logger.Infof("Update error status after creation")
 		statusChan <- status
 		return
 	}
+	if r.ServiceNetworkID != "" {
+		if err := d.client.NetworkConnect(ctx, r.ServiceNetworkID, r.ContainerID, nil); err != nil {
+			logger.Errorf("error connecting container to services network: %v", err)
+			statusChan <- core.ContainerStatus{Done: false, Error: errs.ErrDockerService(err.Error())}
+			return
+		}
+	}
 	if status := d.Run(ctx, r); !status.Done {
-		d.logger.Errorf("error running container: %v", status.Error)
-		d.logger.Infof("Update error status after running")
+		logger.Errorf("error running container: %v", status.Error)
+		logger.Infof("Update error status after running")
 
 		statusChan <- status
 		return
@@ -317,23 +364,26 @@ func (d *docker) Initiate(ctx context.Context, r *core.RunnerOptions, statusChan
 	containerStatus := core.ContainerStatus{Done: true}
 
 	if err := d.WaitForCompletion(ctx, r); err != nil {
-		d.logger.Errorf("error while waiting for the completion of container: %v", err)
+		logger.Errorf("error while waiting for the completion of container: %v", err)
 		containerStatus.Done = false
 		containerStatus.Error = errs.ERR_DOCKER_RUN(err.Error())
 		d.RunningContainers = removeContainerID(d.RunningContainers, r)
+		metrics.SetRunningContainers(len(d.RunningContainers))
 		statusChan <- containerStatus
 		return
 	}
 	d.RunningContainers = removeContainerID(d.RunningContainers, r)
-	d.logger.Infof("container %+s execution successful", r.ContainerID)
+	metrics.SetRunningContainers(len(d.RunningContainers))
+	logger.Infof("container %+s execution successful", r.ContainerID)
 	statusChan <- containerStatus
 }
 
 func (d *docker) KillRunningDocker(ctx context.Context) {
+	logger := lumber.FromContext(ctx, d.logger)
 	for _, r := range d.RunningContainers {
-		d.logger.Infof("Destroying container %s", r.ContainerID)
+		logger.Infof("Destroying container %s", r.ContainerID)
 		if err := d.Destroy(ctx, r); err != nil {
-			d.logger.Errorf("Error occur while destroying container ID %s , err %+v", r.ContainerID, err)
+			logger.Errorf("Error occur while destroying container ID %s , err %+v", r.ContainerID, err)
 		}
 	}
 }
@@ -360,6 +410,7 @@ func (d *docker) PullImage(containerImageConfig *core.ContainerImageConfig, r *c
 	dockerImage := containerImageConfig.Image
 
 	d.logger.Infof("Pulling image : %s", dockerImage)
+	pullStart := time.Now()
 	ImagePullOptions := types.ImagePullOptions{}
 	ImagePullOptions.RegistryAuth = containerImageConfig.AuthRegistry
 	reader, err := d.client.ImagePull(context.TODO(), dockerImage, ImagePullOptions)
@@ -374,12 +425,13 @@ func (d *docker) PullImage(containerImageConfig *core.ContainerImageConfig, r *c
 	}()
 
 	if err != nil {
+		metrics.ObserveImagePull(pullStart, err)
 		return err
 	}
-	if _, err := io.Copy(os.Stdout, reader); err != nil {
-		return err
-	}
-	return nil
+	_, err = io.Copy(os.Stdout, reader)
+	// the pull completes once its progress is read till the end
+	metrics.ObserveImagePull(pullStart, err)
+	return err
 }
 
 // writeLogs writes container logs to a file
@@ -437,9 +489,10 @@ func (d *docker) RemoveVolume(ctx context.Context, volumeName string) error {
 }
 
 func (d *docker) RemoveOldVolumes(ctx context.Context) {
+	logger := lumber.FromContext(ctx, d.logger)
 	volumes, err := d.client.VolumeList(context.Background(), filters.NewArgs())
 	if err != nil {
-		d.logger.Errorf("error fetching volume lists: %v", err.Error())
+		logger.Errorf("error fetching volume lists: %v", err.Error())
 	}
 	for _, v := range volumes.Volumes {
 		if strings.HasPrefix(v.Name, volumePrefix) {
@@ -448,20 +501,22 @@ func (d *docker) RemoveOldVolumes(ctx context.Context) {
 				var volumeDetails core.VolumeDetails
 				err = json.Unmarshal(data, &volumeDetails)
 				if err != nil {
-					d.logger.Errorf("error in unmarshaling volume details: %v", err.Error())
+					logger.Errorf("error in unmarshaling volume details: %v", err.Error())
 					continue
 				}
 
 				now := time.Now()
 				diff := now.Sub(volumeDetails.CreatedAt)
 				if diff > buildCacheExpiry {
-					d.logger.Debugf("Deleting volume: %s", v.Name)
-					if err = d.RemoveVolume(ctx, v.Name); err != nil {
-						d.logger.Errorf("Error deleting volume: %v", err.Error())
+					logger.Debugf("Deleting volume: %s", v.Name)
+					err = d.RemoveVolume(ctx, v.Name)
+					metrics.RecordVolumeCleanup(err)
+					if err != nil {
+						logger.Errorf("Error deleting volume: %v", err.Error())
 					}
 				}
 			} else {
-				d.logger.Errorf("error in fetching volume details: %v", err.Error())
+				logger.Errorf("error in fetching volume details: %v", err.Error())
 			}
 		}
 	}
@@ -178,7 +178,7 @@ func TestDockerVolumes(t *testing.T) {
 	}
 
 	expectedFileContent := `{"access_token":"dummytoken","expiry":"0001-01-01T00:00:00Z","refresh_token":"","token_type":"Bearer"}`
-	secretBytes, err := secretsManager.GetGitSecretBytes()
+	secretBytes, err := secretsManager.GetGitSecretBytes(&core.GitRepo{})
 	if err != nil {
 		t.Errorf("error retrieving secrets: %v", err)
 	}
@@ -0,0 +1,198 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/synapse"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+)
+
+const (
+	servicesNetworkPrefix     = "tas-services"
+	serviceStartTimeout       = 5 * time.Minute
+	serviceHealthPollInterval = time.Second
+)
+
+var nonEnvNameChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// startServices starts the service containers on a network of their own and waits until they are healthy.
+// The network is created per runner, so that services of concurrent tasks of a build do not collide,
+// and the hostnames of the services are added to the runner env.
+func (d *docker) startServices(ctx context.Context, r *core.RunnerOptions) core.ContainerStatus {
+	logger := lumber.FromContext(ctx, d.logger)
+	containerStatus := core.ContainerStatus{Done: true}
+	networkName := fmt.Sprintf("%s-%s", servicesNetworkPrefix, r.ContainerName)
+	resp, err := d.client.NetworkCreate(ctx, networkName, types.NetworkCreate{
+		CheckDuplicate: true,
+		Labels:         map[string]string{synapse.BuildID: r.Label[synapse.BuildID]},
+	})
+	if err != nil {
+		logger.Errorf("error creating network %s for services: %v", networkName, err)
+		containerStatus.Done = false
+		containerStatus.Error = errs.ErrDockerService(err.Error())
+		return containerStatus
+	}
+	r.ServiceNetworkID = resp.ID
+
+	for i := range r.Services {
+		service := &r.Services[i]
+		if err := d.startService(ctx, r, service, networkName); err != nil {
+			logger.Errorf("error starting service %s: %v", service.Name, err)
+			containerStatus.Done = false
+			containerStatus.Error = errs.ErrDockerService(fmt.Sprintf("service %s: %s", service.Name, err.Error()))
+			return containerStatus
+		}
+		r.Env = append(r.Env, getServiceEnv(service)...)
+	}
+	return containerStatus
+}
+
+func (d *docker) startService(ctx context.Context, r *core.RunnerOptions, service *core.Service, networkName string) error {
+	logger := lumber.FromContext(ctx, d.logger)
+	authRegistry, err := d.secretsManager.GetRegistryAuth(service.Image)
+	if err != nil {
+		return err
+	}
+	if err := d.PullImage(&core.ContainerImageConfig{Image: service.Image, AuthRegistry: authRegistry}, r); err != nil {
+		return err
+	}
+	containerName := fmt.Sprintf("%s-%s", r.ContainerName, service.Name)
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networkName: {NetworkID: r.ServiceNetworkID, Aliases: []string{service.Name}},
+		},
+	}
+	resp, err := d.client.ContainerCreate(ctx, getServiceContainerConfiguration(r, service),
+		&container.HostConfig{Resources: d.getServiceResources(service)}, networkConfig, nil, containerName)
+	if err != nil {
+		return err
+	}
+	r.ServiceContainerIDs = append(r.ServiceContainerIDs, resp.ID)
+	logger.Debugf("starting service %s in container %s", service.Name, resp.ID)
+	if err := d.client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+	return d.waitForService(ctx, resp.ID, service.Name)
+}
+
+// getServiceResources limits the service to the resources of its spec, or to the configured defaults.
+func (d *docker) getServiceResources(service *core.Service) container.Resources {
+	cpu, ram := service.CPU, service.RAM
+	if cpu == 0 {
+		cpu = d.cfg.ServiceResources.CPU
+	}
+	if ram == 0 {
+		ram = d.cfg.ServiceResources.RAM
+	}
+	return container.Resources{Memory: ram * units.MiB, NanoCPUs: int64(cpu * nanoCPUUnit)}
+}
+
+// waitForService waits until the service container is healthy, or just running if it has no health check.
+func (d *docker) waitForService(ctx context.Context, containerID, name string) error {
+	logger := lumber.FromContext(ctx, d.logger)
+	ctx, cancel := context.WithTimeout(ctx, serviceStartTimeout)
+	defer cancel()
+	ticker := time.NewTicker(serviceHealthPollInterval)
+	defer ticker.Stop()
+	for {
+		info, err := d.client.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return err
+		}
+		if !info.State.Running {
+			return fmt.Errorf("container exited with code %d", info.State.ExitCode)
+		}
+		if info.State.Health == nil || info.State.Health.Status == types.Healthy {
+			logger.Infof("service %s is ready", name)
+			return nil
+		}
+		if info.State.Health.Status == types.Unhealthy {
+			return fmt.Errorf("health check failed")
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for health check to pass")
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopServices removes the service containers along with their network, the services which are already removed
+// are skipped as it may be called more than once for a runner.
+func (d *docker) stopServices(ctx context.Context, r *core.RunnerOptions) {
+	logger := lumber.FromContext(ctx, d.logger)
+	r.ServicesMu.Lock()
+	defer r.ServicesMu.Unlock()
+	for _, containerID := range r.ServiceContainerIDs {
+		if err := d.client.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		}); err != nil && !client.IsErrNotFound(err) {
+			logger.Errorf("error removing service container %s: %v", containerID, err)
+		}
+	}
+	r.ServiceContainerIDs = nil
+	if r.ServiceNetworkID == "" {
+		return
+	}
+	if r.ContainerID != "" {
+		if err := d.client.NetworkDisconnect(ctx, r.ServiceNetworkID, r.ContainerID, true); err != nil &&
+			!client.IsErrNotFound(err) {
+			logger.Debugf("error disconnecting container %s from services network: %v", r.ContainerID, err)
+		}
+	}
+	if err := d.client.NetworkRemove(ctx, r.ServiceNetworkID); err != nil && !client.IsErrNotFound(err) {
+		logger.Errorf("error removing services network %s: %v", r.ServiceNetworkID, err)
+	}
+	r.ServiceNetworkID = ""
+}
+
+func getServiceContainerConfiguration(r *core.RunnerOptions, service *core.Service) *container.Config {
+	env := make([]string, 0, len(service.Env))
+	for k, v := range service.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	exposedPorts := nat.PortSet{}
+	for _, port := range service.Ports {
+		exposedPorts[nat.Port(fmt.Sprintf("%d/tcp", port))] = struct{}{}
+	}
+	containerConfig := &container.Config{
+		Image:        service.Image,
+		Env:          env,
+		ExposedPorts: exposedPorts,
+		Labels:       map[string]string{synapse.BuildID: r.Label[synapse.BuildID]},
+	}
+	if service.HealthCheck != nil {
+		containerConfig.Healthcheck = &container.HealthConfig{
+			Test:     []string{"CMD-SHELL", service.HealthCheck.Command},
+			Interval: service.HealthCheck.Interval,
+			Timeout:  service.HealthCheck.Timeout,
+			Retries:  service.HealthCheck.Retries,
+		}
+	}
+	return containerConfig
+}
+
+// getServiceEnv returns the env vars through which tests reach the service,
+// e.g. POSTGRES_HOST and POSTGRES_PORT for a service named postgres.
+func getServiceEnv(service *core.Service) []string {
+	prefix := nonEnvNameChars.ReplaceAllString(strings.ToUpper(service.Name), "_")
+	env := []string{fmt.Sprintf("%s_HOST=%s", prefix, service.Name)}
+	if len(service.Ports) > 0 {
+		env = append(env, fmt.Sprintf("%s_PORT=%s", prefix, strconv.Itoa(service.Ports[0])))
+	}
+	return env
+}
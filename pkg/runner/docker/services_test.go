@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/synapse"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestGetServiceEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		service *core.Service
+		want    []string
+	}{
+		{"Test service without ports", &core.Service{Name: "redis"}, []string{"REDIS_HOST=redis"}},
+		{
+			"Test service with ports",
+			&core.Service{Name: "my-postgres", Ports: []int{5432, 5433}},
+			[]string{"MY_POSTGRES_HOST=my-postgres", "MY_POSTGRES_PORT=5432"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getServiceEnv(tt.service); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getServiceEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetServiceContainerConfiguration(t *testing.T) {
+	r := &core.RunnerOptions{Label: map[string]string{synapse.BuildID: "build-1"}}
+	service := &core.Service{
+		Name:  "postgres",
+		Image: "postgres:14",
+		Env:   map[string]string{"POSTGRES_PASSWORD": "postgres"},
+		Ports: []int{5432},
+		HealthCheck: &core.HealthCheck{
+			Command:  "pg_isready",
+			Interval: time.Second,
+			Retries:  5,
+		},
+	}
+	got := getServiceContainerConfiguration(r, service)
+	if got.Image != service.Image || !reflect.DeepEqual(got.Env, []string{"POSTGRES_PASSWORD=postgres"}) {
+		t.Errorf("getServiceContainerConfiguration() image = %s, env = %v", got.Image, got.Env)
+	}
+	if _, ok := got.ExposedPorts["5432/tcp"]; !ok {
+		t.Errorf("getServiceContainerConfiguration() exposed ports = %v, want 5432/tcp", got.ExposedPorts)
+	}
+	wantHealthcheck := &container.HealthConfig{Test: []string{"CMD-SHELL", "pg_isready"}, Interval: time.Second, Retries: 5}
+	if !reflect.DeepEqual(got.Healthcheck, wantHealthcheck) {
+		t.Errorf("getServiceContainerConfiguration() healthcheck = %+v, want %+v", got.Healthcheck, wantHealthcheck)
+	}
+	if got.Labels[synapse.BuildID] != "build-1" {
+		t.Errorf("getServiceContainerConfiguration() labels = %v", got.Labels)
+	}
+}
+
+func TestGetServiceResources(t *testing.T) {
+	d := &docker{cfg: &config.SynapseConfig{ServiceResources: config.ServiceResourcesConfig{CPU: 1, RAM: 1024}}}
+	tests := []struct {
+		name    string
+		service *core.Service
+		want    container.Resources
+	}{
+		{"Test service without limits", &core.Service{Name: "redis"}, container.Resources{Memory: 1024 << 20, NanoCPUs: 1e9}},
+		{
+			"Test service with limits",
+			&core.Service{Name: "postgres", CPU: 0.5, RAM: 512},
+			container.Resources{Memory: 512 << 20, NanoCPUs: 5e8},
+		},
+		{"Test service with only ram", &core.Service{Name: "mysql", RAM: 2048}, container.Resources{Memory: 2048 << 20, NanoCPUs: 1e9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.getServiceResources(tt.service); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getServiceResources() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
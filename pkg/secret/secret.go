@@ -2,21 +2,28 @@ package secret
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 type secretParser struct {
 	logger      lumber.Logger
 	secretRegex *regexp.Regexp
+	mu          sync.RWMutex
+	// withheld holds the reason for every secret withheld by the secret policies
+	withheld map[string]string
 }
 
 // New return new secret parser
@@ -24,6 +31,7 @@ func New(logger lumber.Logger) core.SecretParser {
 	return &secretParser{
 		logger:      logger,
 		secretRegex: regexp.MustCompile(global.SecretRegex),
+		withheld:    map[string]string{},
 	}
 }
 
@@ -48,6 +56,92 @@ func (s *secretParser) GetRepoSecret(path string) (map[string]string, error) {
 	return secretData, nil
 }
 
+// GetSecretPolicy reads the policy of repo secrets from given path,
+// the default policy is returned if the path does not exist.
+func (s *secretParser) GetSecretPolicy(path string) (*config.SecretPolicy, error) {
+	policy := new(config.SecretPolicy)
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		s.logger.Debugf("failed to find secret policy in path %s, using the default policy", path)
+		return policy, nil
+	}
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(body, policy); err != nil {
+		s.logger.Errorf("failed to unmarshal secret policy, error %v", err)
+		return nil, errs.ErrUnMarshalJSON
+	}
+	return policy, nil
+}
+
+// ApplySecretPolicy returns the secrets made available to the build by all the policies.
+// A nil policy does not restrict any secret.
+func (s *secretParser) ApplySecretPolicy(secretData map[string]string,
+	payload *core.Payload,
+	policies ...*config.SecretPolicy) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	available := make(map[string]string, len(secretData))
+	for name, value := range secretData {
+		reason := ""
+		for _, policy := range policies {
+			if reason = getWithheldReason(name, payload, policy); reason != "" {
+				break
+			}
+		}
+		if reason != "" {
+			s.logger.Infof("withholding secret %s from the build, as it is %s", name, reason)
+			s.withheld[name] = reason
+			continue
+		}
+		available[name] = value
+	}
+	return available
+}
+
+// getWithheldReason returns why the secret is withheld from the build by policy, or an empty string if it is not.
+func getWithheldReason(name string, payload *core.Payload, policy *config.SecretPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	if payload.EventType == core.EventPullRequest && payload.ForkSlug != "" && !policy.AllowForkPullRequests {
+		return "not available to pull requests from forks"
+	}
+	if len(policy.Branches) > 0 && !matchesAny(policy.Branches, payload.BranchName) {
+		return fmt.Sprintf("not available to builds of branch %s", payload.BranchName)
+	}
+	scope, ok := policy.Secrets[name]
+	if !ok {
+		return ""
+	}
+	if len(scope.EventTypes) > 0 && !contains(scope.EventTypes, string(payload.EventType)) {
+		return fmt.Sprintf("not available to %s events", payload.EventType)
+	}
+	if len(scope.Branches) > 0 && !matchesAny(scope.Branches, payload.BranchName) {
+		return fmt.Sprintf("not available to builds of branch %s", payload.BranchName)
+	}
+	return ""
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // GetOauthSecret parses the oauth secret
 func (s *secretParser) GetOauthSecret(path string) (*core.Oauth, error) {
 	o := &core.Oauth{
@@ -88,6 +182,12 @@ func (s *secretParser) SubstituteSecret(command string, secretData map[string]st
 		if len(match) < 2 {
 			return "", errs.ErrSecretRegexMatch
 		}
+		s.mu.RLock()
+		reason, withheld := s.withheld[match[1]]
+		s.mu.RUnlock()
+		if withheld {
+			return "", &errs.StatusFailed{Remark: fmt.Sprintf("secret `%s` is withheld from the build, as it is %s", match[1], reason)}
+		}
 		// validating secret key exists or not
 		if _, ok := secretData[match[1]]; !ok {
 			s.logger.Warnf("secret with name %s not found in map", match[0])
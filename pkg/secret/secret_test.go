@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
@@ -267,3 +268,126 @@ func TestExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSecretPolicy(t *testing.T) {
+	logger, err := lumber.NewLogger(lumber.LoggingConfig{EnableConsole: true}, true, lumber.InstanceZapLogger)
+	if err != nil {
+		log.Fatalf("could not instantiate logger %s", err.Error())
+	}
+	secretParser := New(logger)
+
+	tests := []struct {
+		name      string
+		path      string
+		want      *config.SecretPolicy
+		errorType error
+	}{
+		{
+			"Test for correct file",
+			"../../testutils/testdata/secretTestData/secretpolicy.json",
+			&config.SecretPolicy{
+				Branches: []string{"main", "release/*"},
+				Secrets:  map[string]config.SecretScope{"NPM_TOKEN": {EventTypes: []string{"push"}}},
+			},
+			nil,
+		},
+		{"Test for invalid file", "../../testutils/testdata/secretTestData/invalidsecretpolicy.json", nil, errs.ErrUnMarshalJSON},
+		{"Test for missing file", "../../testutils/testdata/secretTestData/missing.json", &config.SecretPolicy{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretParser.GetSecretPolicy(tt.path)
+			if err != nil {
+				if !errors.Is(err, tt.errorType) {
+					t.Error(err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected: %+v, got: %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplySecretPolicy(t *testing.T) {
+	logger, err := lumber.NewLogger(lumber.LoggingConfig{EnableConsole: true}, true, lumber.InstanceZapLogger)
+	if err != nil {
+		log.Fatalf("could not instantiate logger %s", err.Error())
+	}
+	secretData := map[string]string{"NPM_TOKEN": "npm", "DB_PASSWORD": "db"}
+	policy := &config.SecretPolicy{
+		Branches: []string{"main", "release/*"},
+		Secrets:  map[string]config.SecretScope{"NPM_TOKEN": {EventTypes: []string{"push"}}},
+	}
+	tests := []struct {
+		name     string
+		payload  *core.Payload
+		policies []*config.SecretPolicy
+		want     map[string]string
+		withheld string
+	}{
+		{
+			"Test without policy",
+			&core.Payload{EventType: core.EventPullRequest, BranchName: "feature", ForkSlug: "fork/repo"},
+			nil,
+			secretData,
+			"",
+		},
+		{
+			"Test default policy for forks",
+			&core.Payload{EventType: core.EventPullRequest, BranchName: "main", ForkSlug: "fork/repo"},
+			[]*config.SecretPolicy{{}},
+			map[string]string{},
+			"secret `NPM_TOKEN` is withheld from the build, as it is not available to pull requests from forks",
+		},
+		{
+			"Test fork allowed",
+			&core.Payload{EventType: core.EventPullRequest, BranchName: "main", ForkSlug: "fork/repo"},
+			[]*config.SecretPolicy{{AllowForkPullRequests: true}},
+			secretData,
+			"",
+		},
+		{
+			"Test branch not allowed",
+			&core.Payload{EventType: core.EventPush, BranchName: "feature"},
+			[]*config.SecretPolicy{policy},
+			map[string]string{},
+			"secret `NPM_TOKEN` is withheld from the build, as it is not available to builds of branch feature",
+		},
+		{
+			"Test secret scoped to event",
+			&core.Payload{EventType: core.EventPullRequest, BranchName: "release/v1"},
+			[]*config.SecretPolicy{policy},
+			map[string]string{"DB_PASSWORD": "db"},
+			"secret `NPM_TOKEN` is withheld from the build, as it is not available to pull-request events",
+		},
+		{
+			"Test all policies apply",
+			&core.Payload{EventType: core.EventPush, BranchName: "release/v1"},
+			[]*config.SecretPolicy{policy, {Branches: []string{"main"}}},
+			map[string]string{},
+			"secret `NPM_TOKEN` is withheld from the build, as it is not available to builds of branch release/v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secretParser := New(logger)
+			got := secretParser.ApplySecretPolicy(secretData, tt.payload, tt.policies...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected: %v, got: %v", tt.want, got)
+			}
+			_, err := secretParser.SubstituteSecret("npm publish --token ${{ secrets.NPM_TOKEN }}", got)
+			if tt.withheld == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var statusFailed *errs.StatusFailed
+			if !errors.As(err, &statusFailed) || err.Error() != tt.withheld {
+				t.Errorf("expected error: %s, got: %v", tt.withheld, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/config"
+)
+
+const defaultEnvPrefix = "TAS_SECRET_"
+
+// envProvider serves the secrets in the env variables of synapse. The git token is read from
+// <prefix>GIT_TOKEN and <prefix>GIT_TOKEN_TYPE, and the secrets of a repo from
+// <prefix>REPO_<REPO>__<NAME>, e.g. TAS_SECRET_REPO_MY_REPO__NPM_TOKEN.
+type envProvider struct {
+	prefix string
+}
+
+func newEnvProvider(prefix string) *envProvider {
+	if prefix == "" {
+		prefix = defaultEnvPrefix
+	}
+	return &envProvider{prefix: prefix}
+}
+
+func (p *envProvider) Name() string {
+	return string(config.EnvSecretProvider)
+}
+
+func (p *envProvider) GetRepoSecrets(ctx context.Context, repo string) (map[string]string, error) {
+	repoPrefix := p.prefix + "REPO_" + getEnvName(repo) + "__"
+	var secrets map[string]string
+	for _, env := range os.Environ() {
+		name, value, ok := cut(env, "=")
+		if !ok || !strings.HasPrefix(name, repoPrefix) || len(name) == len(repoPrefix) {
+			continue
+		}
+		if secrets == nil {
+			secrets = map[string]string{}
+		}
+		secrets[strings.TrimPrefix(name, repoPrefix)] = value
+	}
+	return secrets, nil
+}
+
+func (p *envProvider) GetGitSecrets(ctx context.Context) ([]config.GitConfig, error) {
+	token := os.Getenv(p.prefix + "GIT_TOKEN")
+	if token == "" {
+		return nil, nil
+	}
+	return []config.GitConfig{{Token: token, TokenType: os.Getenv(p.prefix + "GIT_TOKEN_TYPE")}}, nil
+}
+
+// cut slices s around the first instance of sep
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/config"
+)
+
+// filesProvider serves the secrets in a directory of files, like the secrets mounted by docker or kubernetes.
+// The git token is read from <dir>/git/token and <dir>/git/token_type, and every file in <dir>/repos/<repo>
+// holds the secret of the repo named after the file.
+type filesProvider struct {
+	dir string
+}
+
+func (p *filesProvider) Name() string {
+	return string(config.FilesSecretProvider)
+}
+
+func (p *filesProvider) GetRepoSecrets(ctx context.Context, repo string) (map[string]string, error) {
+	repoDir := filepath.Join(p.dir, repoSecretsName, filepath.Clean("/"+repo))
+	entries, err := os.ReadDir(repoDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var secrets map[string]string
+	for _, entry := range entries {
+		// skip directories and hidden files, like the ..data links of kubernetes secret volumes
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		value, err := readSecretFile(filepath.Join(repoDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if secrets == nil {
+			secrets = map[string]string{}
+		}
+		secrets[entry.Name()] = value
+	}
+	return secrets, nil
+}
+
+func (p *filesProvider) GetGitSecrets(ctx context.Context) ([]config.GitConfig, error) {
+	token, err := readSecretFile(filepath.Join(p.dir, gitSecretName, gitTokenKey))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	tokenType, err := readSecretFile(filepath.Join(p.dir, gitSecretName, gitTokenTypeKey))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return []config.GitConfig{{Token: token, TokenType: tokenType}}, nil
+}
+
+// readSecretFile reads the secret in path, without the trailing newline editors add to files
+func readSecretFile(path string) (string, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(body), "\r\n"), nil
+}
@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	gitSecretName   = "git"
+	repoSecretsName = "repos"
+	gitTokenKey     = "token"
+	gitTokenTypeKey = "token_type"
+)
+
+var nonEnvNameChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// newProviders returns the secret providers in the configured order,
+// the secrets in the configuration file are used if none is configured.
+func newProviders(cfg *config.SynapseConfig, logger lumber.Logger) []core.SecretProvider {
+	if len(cfg.SecretProviders) == 0 {
+		return []core.SecretProvider{&configProvider{cfg: cfg}}
+	}
+	providers := make([]core.SecretProvider, 0, len(cfg.SecretProviders))
+	for i := range cfg.SecretProviders {
+		providerCfg := &cfg.SecretProviders[i]
+		switch providerCfg.Type {
+		case config.ConfigSecretProvider:
+			providers = append(providers, &configProvider{cfg: cfg})
+		case config.EnvSecretProvider:
+			providers = append(providers, newEnvProvider(providerCfg.Prefix))
+		case config.FilesSecretProvider:
+			providers = append(providers, &filesProvider{dir: providerCfg.Dir})
+		case config.VaultSecretProvider:
+			providers = append(providers, newVaultProvider(&providerCfg.Vault, logger))
+		default:
+			// provider types are validated while loading the config
+			logger.Errorf("ignoring secret provider of unknown type %s", providerCfg.Type)
+		}
+	}
+	return providers
+}
+
+// configProvider serves the secrets in the configuration file
+type configProvider struct {
+	cfg *config.SynapseConfig
+}
+
+func (p *configProvider) Name() string {
+	return string(config.ConfigSecretProvider)
+}
+
+func (p *configProvider) GetRepoSecrets(ctx context.Context, repo string) (map[string]string, error) {
+	return p.cfg.RepoSecrets[repo], nil
+}
+
+// GetGitSecrets returns the scoped git credentials followed by the git token
+func (p *configProvider) GetGitSecrets(ctx context.Context) ([]config.GitConfig, error) {
+	gitSecrets := p.cfg.GitCredentials
+	if p.cfg.Git.Token != "" {
+		gitSecrets = append(append([]config.GitConfig{}, gitSecrets...), p.cfg.Git)
+	}
+	return gitSecrets, nil
+}
+
+// getRepoSecrets merges the secrets of repo from all the providers,
+// a secret found in more than one provider is taken from the first one.
+func getRepoSecrets(ctx context.Context, providers []core.SecretProvider, repo string) (map[string]string, error) {
+	var secrets map[string]string
+	for _, provider := range providers {
+		providerSecrets, err := provider.GetRepoSecrets(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range providerSecrets {
+			if secrets == nil {
+				secrets = map[string]string{}
+			}
+			if _, ok := secrets[name]; !ok {
+				secrets[name] = value
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// getGitSecret returns the first git credential of the providers matching repo
+func getGitSecret(ctx context.Context, providers []core.SecretProvider, repo *core.GitRepo) (*config.GitConfig, error) {
+	for _, provider := range providers {
+		gitSecrets, err := provider.GetGitSecrets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range gitSecrets {
+			if matchesGitRepo(&gitSecrets[i], repo) {
+				return &gitSecrets[i], nil
+			}
+		}
+	}
+	return &config.GitConfig{}, nil
+}
+
+// matchesGitRepo reports whether the git credential can be used for repo, a credential scoped to
+// a provider, host or repo does not match a repo of which that attribute is not known.
+func matchesGitRepo(gitSecret *config.GitConfig, repo *core.GitRepo) bool {
+	if gitSecret.Token == "" {
+		return false
+	}
+	if repo == nil {
+		repo = &core.GitRepo{}
+	}
+	if gitSecret.Provider != "" && !strings.EqualFold(gitSecret.Provider, repo.Provider) {
+		return false
+	}
+	host := repo.Host
+	if host == "" {
+		host = global.GitProviderHostMap[strings.ToLower(repo.Provider)]
+	}
+	if gitSecret.Host != "" && !strings.EqualFold(gitSecret.Host, host) {
+		return false
+	}
+	if gitSecret.Repo != "" {
+		// patterns are validated while loading the config, so the error can be ignored
+		if ok, _ := doublestar.Match(gitSecret.Repo, repo.Slug); !ok || repo.Slug == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// getEnvName converts name to the form used in env variable names, e.g. my-repo to MY_REPO
+func getEnvName(name string) string {
+	return nonEnvNameChars.ReplaceAllString(strings.ToUpper(name), "_")
+}
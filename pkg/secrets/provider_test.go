@@ -0,0 +1,154 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("TAS_SECRET_REPO_MY_REPO__NPM_TOKEN", "npm")
+	t.Setenv("TAS_SECRET_REPO_MY_REPO__db_password", "db")
+	t.Setenv("TAS_SECRET_REPO_OTHER__NPM_TOKEN", "other")
+	t.Setenv("TAS_SECRET_GIT_TOKEN", "git-token")
+	t.Setenv("TAS_SECRET_GIT_TOKEN_TYPE", "Bearer")
+	p := newEnvProvider("")
+
+	got, err := p.GetRepoSecrets(context.TODO(), "my-repo")
+	if err != nil {
+		t.Fatalf("envProvider.GetRepoSecrets() error = %v", err)
+	}
+	want := map[string]string{"NPM_TOKEN": "npm", "db_password": "db"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("envProvider.GetRepoSecrets() = %v, want %v", got, want)
+	}
+	if got, _ = p.GetRepoSecrets(context.TODO(), "unknown"); got != nil {
+		t.Errorf("envProvider.GetRepoSecrets() = %v, want nil", got)
+	}
+	gitSecrets, err := p.GetGitSecrets(context.TODO())
+	if err != nil || !reflect.DeepEqual(gitSecrets, []config.GitConfig{{Token: "git-token", TokenType: "Bearer"}}) {
+		t.Errorf("envProvider.GetGitSecrets() = %v, %v", gitSecrets, err)
+	}
+}
+
+func TestFilesProvider(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"repos/my-repo/NPM_TOKEN":   "npm\n",
+		"repos/my-repo/.hidden":     "hidden",
+		"repos/my-repo/nested/file": "nested",
+		"git/token":                 "git-token\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &filesProvider{dir: dir}
+
+	got, err := p.GetRepoSecrets(context.TODO(), "my-repo")
+	if err != nil {
+		t.Fatalf("filesProvider.GetRepoSecrets() error = %v", err)
+	}
+	if want := map[string]string{"NPM_TOKEN": "npm"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filesProvider.GetRepoSecrets() = %v, want %v", got, want)
+	}
+	if got, err = p.GetRepoSecrets(context.TODO(), "../git"); err != nil || got != nil {
+		t.Errorf("filesProvider.GetRepoSecrets() = %v, %v, want no secrets outside repos dir", got, err)
+	}
+	gitSecrets, err := p.GetGitSecrets(context.TODO())
+	if err != nil || !reflect.DeepEqual(gitSecrets, []config.GitConfig{{Token: "git-token"}}) {
+		t.Errorf("filesProvider.GetGitSecrets() = %v, %v", gitSecrets, err)
+	}
+}
+
+func TestSecretProviderChain(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	t.Setenv("CHAIN_REPO_MY_REPO__NPM_TOKEN", "env-npm")
+	cfg := &config.SynapseConfig{
+		Git:         config.GitConfig{Token: "config-token", TokenType: "Bearer"},
+		RepoSecrets: map[string]map[string]string{"my-repo": {"NPM_TOKEN": "config-npm", "DB_PASSWORD": "config-db"}},
+	}
+	tests := []struct {
+		name        string
+		providers   []config.SecretProviderConfig
+		wantSecrets map[string]string
+		wantToken   string
+	}{
+		{"Test default provider", nil, map[string]string{"NPM_TOKEN": "config-npm", "DB_PASSWORD": "config-db"}, "config-token"},
+		{
+			"Test first provider takes precedence",
+			[]config.SecretProviderConfig{{Type: config.EnvSecretProvider, Prefix: "CHAIN_"}, {Type: config.ConfigSecretProvider}},
+			map[string]string{"NPM_TOKEN": "env-npm", "DB_PASSWORD": "config-db"},
+			"config-token",
+		},
+		{
+			"Test without config provider",
+			[]config.SecretProviderConfig{{Type: config.EnvSecretProvider, Prefix: "CHAIN_"}},
+			map[string]string{"NPM_TOKEN": "env-npm"},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.SecretProviders = tt.providers
+			providers := newProviders(cfg, logger)
+			got, err := getRepoSecrets(context.TODO(), providers, "my-repo")
+			if err != nil || !reflect.DeepEqual(got, tt.wantSecrets) {
+				t.Errorf("getRepoSecrets() = %v, %v, want %v", got, err, tt.wantSecrets)
+			}
+			gitSecret, err := getGitSecret(context.TODO(), providers, nil)
+			if err != nil || gitSecret.Token != tt.wantToken {
+				t.Errorf("getGitSecret() = %v, %v, want token %s", gitSecret, err, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestGetGitSecretMatching(t *testing.T) {
+	cfg := &config.SynapseConfig{
+		Git: config.GitConfig{Token: "default-token"},
+		GitCredentials: []config.GitConfig{
+			{Token: "gitlab-token", Provider: "gitlab"},
+			{Token: "org-token", Provider: "github", Repo: "myorg/*"},
+			{Token: "enterprise-token", Host: "github.mycorp.com"},
+		},
+	}
+	providers := []core.SecretProvider{&configProvider{cfg: cfg}}
+	tests := []struct {
+		name string
+		repo *core.GitRepo
+		want string
+	}{
+		{"Test provider", &core.GitRepo{Provider: "gitlab", Slug: "myorg/repo"}, "gitlab-token"},
+		{"Test provider and repo", &core.GitRepo{Provider: "github", Slug: "myorg/repo"}, "org-token"},
+		{"Test repo not matching", &core.GitRepo{Provider: "github", Slug: "otherorg/repo"}, "default-token"},
+		{"Test host", &core.GitRepo{Provider: "github", Host: "github.mycorp.com", Slug: "team/repo"}, "enterprise-token"},
+		{"Test default host of provider", &core.GitRepo{Provider: "bitbucket", Slug: "team/repo"}, "default-token"},
+		{"Test unknown provider", &core.GitRepo{Slug: "myorg/repo"}, "default-token"},
+		{"Test unknown repo", &core.GitRepo{Provider: "github"}, "default-token"},
+		{"Test unknown repo of host", &core.GitRepo{Provider: "github", Host: "github.mycorp.com"}, "enterprise-token"},
+		{"Test unknown repo and provider", nil, "default-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getGitSecret(context.TODO(), providers, tt.repo)
+			if err != nil || got.Token != tt.want {
+				t.Errorf("getGitSecret() = %v, %v, want token %s", got, err, tt.want)
+			}
+		})
+	}
+}
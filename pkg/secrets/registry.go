@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/docker/distribution/reference"
+)
+
+const dockerHubHost = "docker.io"
+
+// dockerConfig is the part of the docker config.json holding the credentials of the registries
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+}
+
+// registryAuth is the auth of a registry as expected by the docker engine API
+type registryAuth struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	ServerAddress string `json:"serveraddress,omitempty"`
+}
+
+// getRegistryHost returns the host of the registry from which image is pulled, docker.io for docker hub images
+func getRegistryHost(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %s: %w", image, err)
+	}
+	return reference.Domain(named), nil
+}
+
+// normalizeRegistryHost returns the host of a registry given as a host or url, e.g. https://index.docker.io/v1/
+func normalizeRegistryHost(registry string) string {
+	host := strings.ToLower(registry)
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host = strings.SplitN(host, "/", 2)[0]
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubHost
+	}
+	return host
+}
+
+// getRegistryAuth returns the auth of the registry hosting image from the configured registries,
+// and then from the docker config file. It returns nil if no credential is found for the registry.
+func getRegistryAuth(cfg *config.ContainerRegistryConfig, image string) (*registryAuth, error) {
+	host, err := getRegistryHost(image)
+	if err != nil {
+		return nil, err
+	}
+	for registry, credential := range cfg.Registries {
+		if normalizeRegistryHost(registry) == host {
+			return &registryAuth{Username: credential.Username, Password: credential.Password, ServerAddress: host}, nil
+		}
+	}
+	if cfg.DockerConfigFile == "" {
+		return nil, nil
+	}
+	return getDockerConfigAuth(cfg.DockerConfigFile, host)
+}
+
+// getLegacyRegistryAuth returns the auth of the Username and Password of the private mode,
+// which are only sent to their own registry
+func getLegacyRegistryAuth(cfg *config.ContainerRegistryConfig, image string) (*registryAuth, error) {
+	host, err := getRegistryHost(image)
+	if err != nil {
+		return nil, err
+	}
+	registry := dockerHubHost
+	if cfg.Registry != "" {
+		registry = normalizeRegistryHost(cfg.Registry)
+	}
+	if host != registry || cfg.Username == "" || cfg.Password == "" {
+		return nil, errs.CR_AUTH_NF
+	}
+	return &registryAuth{Username: cfg.Username, Password: cfg.Password, ServerAddress: host}, nil
+}
+
+// getDockerConfigAuth returns the auth of the registry host in the docker config file,
+// which is read on every pull as the credentials in it can be refreshed, e.g. by docker login.
+func getDockerConfigAuth(path, host string) (*registryAuth, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var dockerCfg dockerConfig
+	if err := json.Unmarshal(body, &dockerCfg); err != nil {
+		return nil, fmt.Errorf("error parsing docker config file %s: %w", path, err)
+	}
+	for registry, entry := range dockerCfg.Auths {
+		if normalizeRegistryHost(registry) != host {
+			continue
+		}
+		auth := &registryAuth{
+			Username:      entry.Username,
+			Password:      entry.Password,
+			IdentityToken: entry.IdentityToken,
+			ServerAddress: host,
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding auth of %s in docker config file %s: %w", registry, path, err)
+			}
+			auth.Username, auth.Password, _ = cut(string(decoded), ":")
+		}
+		return auth, nil
+	}
+	return nil, nil
+}
+
+// encode returns the auth encoded for the X-Registry-Auth header of the docker engine API
+func (a *registryAuth) encode() (string, error) {
+	jsonBytes, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(jsonBytes), nil
+}
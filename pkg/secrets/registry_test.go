@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/LambdaTest/test-at-scale/testutils"
+)
+
+func TestGetRegistryHost(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"lambdatest/nucleus:latest", "docker.io"},
+		{"node", "docker.io"},
+		{"ghcr.io/myorg/nucleus:v1", "ghcr.io"},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/nucleus@sha256:" +
+			"0123456789012345678901234567890123456789012345678901234567890123", "123456789012.dkr.ecr.us-east-1.amazonaws.com"},
+		{"harbor.mycorp.com:8443/ci/nucleus", "harbor.mycorp.com:8443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := getRegistryHost(tt.image)
+			if err != nil || got != tt.want {
+				t.Errorf("getRegistryHost() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func decodeAuth(t *testing.T, encoded string) registryAuth {
+	var auth registryAuth
+	if encoded == "" {
+		return auth
+	}
+	body, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode auth %s, error: %v", encoded, err)
+	}
+	if err := json.Unmarshal(body, &auth); err != nil {
+		t.Fatalf("failed to unmarshal auth %s, error: %v", body, err)
+	}
+	return auth
+}
+
+func TestGetDockerSecrets(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	dockerConfigFile := filepath.Join(t.TempDir(), "config.json")
+	dockerCfg := `{"auths": {
+		"https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("hub-user:hub-pass")) + `"},
+		"harbor.mycorp.com": {"username": "robot", "password": "harbor-pass"}
+	}}`
+	if err := os.WriteFile(dockerConfigFile, []byte(dockerCfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	registries := map[string]config.RegistryCredential{"ghcr.io": {Username: "bot", Password: "ghcr-pass"}}
+
+	tests := []struct {
+		name     string
+		registry config.ContainerRegistryConfig
+		runner   core.RunnerOptions
+		want     registryAuth
+		wantErr  error
+	}{
+		{
+			"Test registry of image host",
+			config.ContainerRegistryConfig{Mode: config.PublicMode, Registries: registries},
+			core.RunnerOptions{DockerImage: "ghcr.io/myorg/nucleus:v1", PodType: core.NucleusPod},
+			registryAuth{Username: "bot", Password: "ghcr-pass", ServerAddress: "ghcr.io"},
+			nil,
+		},
+		{
+			"Test coverage pod uses public image",
+			config.ContainerRegistryConfig{Mode: config.PrivateMode, Registries: registries},
+			core.RunnerOptions{DockerImage: "ghcr.io/myorg/coverage:v1", PodType: core.CoveragePod},
+			registryAuth{},
+			nil,
+		},
+		{
+			"Test docker config auth",
+			config.ContainerRegistryConfig{Mode: config.PublicMode, Registries: registries, DockerConfigFile: dockerConfigFile},
+			core.RunnerOptions{DockerImage: "lambdatest/nucleus:latest", PodType: core.NucleusPod},
+			registryAuth{Username: "hub-user", Password: "hub-pass", ServerAddress: "docker.io"},
+			nil,
+		},
+		{
+			"Test docker config credentials",
+			config.ContainerRegistryConfig{Mode: config.PublicMode, DockerConfigFile: dockerConfigFile},
+			core.RunnerOptions{DockerImage: "harbor.mycorp.com/ci/nucleus", PodType: core.NucleusPod},
+			registryAuth{Username: "robot", Password: "harbor-pass", ServerAddress: "harbor.mycorp.com"},
+			nil,
+		},
+		{
+			"Test public mode without credential",
+			config.ContainerRegistryConfig{Mode: config.PublicMode, Registries: registries},
+			core.RunnerOptions{DockerImage: "quay.io/myorg/nucleus", PodType: core.NucleusPod},
+			registryAuth{},
+			nil,
+		},
+		{
+			"Test private mode fallback",
+			config.ContainerRegistryConfig{Mode: config.PrivateMode, Username: "user", Password: "pass", Registries: registries},
+			core.RunnerOptions{DockerImage: "lambdatest/nucleus", PodType: core.NucleusPod},
+			registryAuth{Username: "user", Password: "pass", ServerAddress: "docker.io"},
+			nil,
+		},
+		{
+			"Test private mode fallback of configured registry",
+			config.ContainerRegistryConfig{Mode: config.PrivateMode, Username: "user", Password: "pass", Registry: "https://quay.io"},
+			core.RunnerOptions{DockerImage: "quay.io/myorg/nucleus", PodType: core.NucleusPod},
+			registryAuth{Username: "user", Password: "pass", ServerAddress: "quay.io"},
+			nil,
+		},
+		{
+			"Test private mode fallback not sent to other registry",
+			config.ContainerRegistryConfig{Mode: config.PrivateMode, Username: "user", Password: "pass", Registries: registries},
+			core.RunnerOptions{DockerImage: "quay.io/myorg/nucleus", PodType: core.NucleusPod},
+			registryAuth{},
+			errs.CR_AUTH_NF,
+		},
+		{
+			"Test private mode without credential",
+			config.ContainerRegistryConfig{Mode: config.PrivateMode},
+			core.RunnerOptions{DockerImage: "quay.io/myorg/nucleus", PodType: core.NucleusPod},
+			registryAuth{},
+			errs.CR_AUTH_NF,
+		},
+		{
+			"Test pull policy never",
+			config.ContainerRegistryConfig{Mode: config.PrivateMode, PullPolicy: config.PullNever},
+			core.RunnerOptions{DockerImage: "quay.io/myorg/nucleus", PodType: core.NucleusPod},
+			registryAuth{},
+			nil,
+		},
+	}
+	for i := range tests {
+		tt := &tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			manager := New(&config.SynapseConfig{ContainerRegistry: tt.registry}, logger)
+			got, err := manager.GetDockerSecrets(&tt.runner)
+			if err != tt.wantErr {
+				t.Fatalf("GetDockerSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if auth := decodeAuth(t, got.AuthRegistry); auth != tt.want {
+				t.Errorf("GetDockerSecrets() auth = %+v, want %+v", auth, tt.want)
+			}
+			if got.Image != tt.runner.DockerImage {
+				t.Errorf("GetDockerSecrets() image = %s, want %s", got.Image, tt.runner.DockerImage)
+			}
+		})
+	}
+}
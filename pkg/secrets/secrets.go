@@ -1,7 +1,7 @@
 package secrets
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -9,18 +9,21 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	errs "github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/secret"
 )
 
 type secertManager struct {
-	logger lumber.Logger
-	cfg    *config.SynapseConfig
+	logger    lumber.Logger
+	cfg       *config.SynapseConfig
+	providers []core.SecretProvider
 }
 
-// New returns new secretManager
+// New returns new secretManager, which resolves the repo and git secrets through the configured providers
 func New(cfg *config.SynapseConfig, logger lumber.Logger) core.SecretsManager {
 	return &secertManager{
-		logger: logger,
-		cfg:    cfg,
+		logger:    logger,
+		cfg:       cfg,
+		providers: newProviders(cfg, logger),
 	}
 }
 
@@ -33,12 +36,16 @@ func (s *secertManager) GetSynapseName() string {
 	return s.cfg.Name
 }
 
-func (s *secertManager) GetGitSecretBytes() ([]byte, error) {
+func (s *secertManager) GetGitSecretBytes(repo *core.GitRepo) ([]byte, error) {
+	gitSecret, err := getGitSecret(context.TODO(), s.providers, repo)
+	if err != nil {
+		return []byte{}, err
+	}
 	gitSecrets := core.Secret{
-		"access_token":  s.cfg.Git.Token,
+		"access_token":  gitSecret.Token,
 		"expiry":        "0001-01-01T00:00:00Z",
 		"refresh_token": "",
-		"token_type":    s.cfg.Git.TokenType,
+		"token_type":    gitSecret.TokenType,
 	}
 	gitSecretsJSON, err := json.Marshal(gitSecrets)
 	if err != nil {
@@ -47,10 +54,20 @@ func (s *secertManager) GetGitSecretBytes() ([]byte, error) {
 	return gitSecretsJSON, nil
 }
 
-func (s *secertManager) GetRepoSecretBytes(repo string) ([]byte, error) {
-	val, ok := s.cfg.RepoSecrets[repo]
-	if !ok {
-		return []byte{}, errors.New("no secrets found in configuration file")
+// GetRepoSecretBytes returns the secrets of repo which the secret policy makes available to build,
+// the withheld secrets never reach the container of the build. The policy is left to nucleus if build is nil.
+func (s *secertManager) GetRepoSecretBytes(repo string, build *core.Payload) ([]byte, error) {
+	val, err := getRepoSecrets(context.TODO(), s.providers, repo)
+	if err != nil {
+		return []byte{}, err
+	}
+	if val == nil {
+		return []byte{}, errors.New("no secrets found in secret providers")
+	}
+	if build == nil {
+		s.logger.Debugf("build of repo %s not known, the secret policy is enforced by nucleus", repo)
+	} else {
+		val = secret.New(s.logger).ApplySecretPolicy(val, build, &s.cfg.SecretPolicy)
 	}
 
 	repoSecretsJSON, err := json.Marshal(val)
@@ -60,6 +77,19 @@ func (s *secertManager) GetRepoSecretBytes(repo string) ([]byte, error) {
 	return repoSecretsJSON, nil
 }
 
+// GetSecretPolicyBytes returns the policy of repo secrets, which is enforced again by nucleus
+// as the event and branch of the build are known there for certain.
+func (s *secertManager) GetSecretPolicyBytes() ([]byte, error) {
+	secretPolicyJSON, err := json.Marshal(s.cfg.SecretPolicy)
+	if err != nil {
+		return []byte{}, errs.ERR_JSON_MAR(err.Error())
+	}
+	return secretPolicyJSON, nil
+}
+
+// GetDockerSecrets returns the image config of the runner along with the auth of the registry hosting the image.
+// The credential of the registry host is used if configured, otherwise the Username and Password in private mode
+// if the image is hosted by their registry.
 func (s *secertManager) GetDockerSecrets(r *core.RunnerOptions) (core.ContainerImageConfig, error) {
 	containerImageConfig := core.ContainerImageConfig{}
 	containerImageConfig.Mode = s.cfg.ContainerRegistry.Mode
@@ -72,27 +102,47 @@ func (s *secertManager) GetDockerSecrets(r *core.RunnerOptions) (core.ContainerI
 		return containerImageConfig, nil
 	}
 	/*
-			1. if mode is public then no need to build AuthRegistry
-		 	2. PullPolicy is set to never, then we assume docker image is being pulled manually by user
+		PullPolicy is set to never, then we assume nucleus image is being pulled manually by user
 	*/
-	if s.cfg.ContainerRegistry.Mode == config.PublicMode || s.cfg.ContainerRegistry.PullPolicy == config.PullNever {
+	if s.cfg.ContainerRegistry.PullPolicy == config.PullNever {
 		return containerImageConfig, nil
 	}
-	// for private repo check whether creds are empty
-	if s.cfg.ContainerRegistry.Username == "" || s.cfg.ContainerRegistry.Password == "" {
-		return containerImageConfig, errs.CR_AUTH_NF
+	auth, err := getRegistryAuth(&s.cfg.ContainerRegistry, r.DockerImage)
+	if err != nil {
+		return containerImageConfig, err
+	}
+	if auth == nil {
+		// if mode is public then no need to build AuthRegistry
+		if s.cfg.ContainerRegistry.Mode == config.PublicMode {
+			return containerImageConfig, nil
+		}
+		if auth, err = getLegacyRegistryAuth(&s.cfg.ContainerRegistry, r.DockerImage); err != nil {
+			return containerImageConfig, err
+		}
+	}
+	if containerImageConfig.AuthRegistry, err = auth.encode(); err != nil {
+		return containerImageConfig, errs.ERR_JSON_MAR(err.Error())
 	}
-	jsonBytes, _ := json.Marshal(map[string]string{
-		"username": s.cfg.ContainerRegistry.Username,
-		"password": s.cfg.ContainerRegistry.Password,
-	})
-	containerImageConfig.AuthRegistry = base64.StdEncoding.EncodeToString(jsonBytes)
 	return containerImageConfig, nil
 }
 
-func (s *secertManager) GetOauthToken() *core.Oauth {
+// GetRegistryAuth returns the auth of the registry hosting image, empty if no credential is configured for it
+func (s *secertManager) GetRegistryAuth(image string) (string, error) {
+	auth, err := getRegistryAuth(&s.cfg.ContainerRegistry, image)
+	if err != nil || auth == nil {
+		return "", err
+	}
+	return auth.encode()
+}
+
+func (s *secertManager) GetOauthToken(repo *core.GitRepo) *core.Oauth {
+	gitSecret, err := getGitSecret(context.TODO(), s.providers, repo)
+	if err != nil {
+		s.logger.Errorf("error in fetching git secret: %v", err)
+		return &core.Oauth{}
+	}
 	return &core.Oauth{
-		AccessToken: s.cfg.Git.Token,
-		Type:        core.TokenType(s.cfg.Git.TokenType),
+		AccessToken: gitSecret.Token,
+		Type:        core.TokenType(gitSecret.TokenType),
 	}
 }
@@ -1,10 +1,15 @@
 package secrets
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
 
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/tests"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,3 +24,39 @@ func TestGetLambdatestSecrets(t *testing.T) {
 	lambdatestSecrets := secretsManager.GetLambdatestSecrets()
 	assert.Equal(t, "dummysecretkey", lambdatestSecrets.SecretKey)
 }
+
+func TestGetRepoSecretBytes(t *testing.T) {
+	logger, err := lumber.NewLogger(lumber.LoggingConfig{ConsoleLevel: lumber.Debug}, true, lumber.InstanceZapLogger)
+	if err != nil {
+		t.Fatalf("failed to create logger, error: %v", err)
+	}
+	policyCfg := tests.MockConfig()
+	policyCfg.RepoSecrets = map[string]map[string]string{"repo": {"NPM_TOKEN": "npm", "DEPLOY_KEY": "deploy"}}
+	policyCfg.SecretPolicy = config.SecretPolicy{
+		Secrets: map[string]config.SecretScope{"DEPLOY_KEY": {Branches: []string{"main"}}},
+	}
+	manager := New(policyCfg, logger)
+	tests := []struct {
+		name  string
+		build *core.Payload
+		want  map[string]string
+	}{
+		{"push to main", &core.Payload{EventType: core.EventPush, BranchName: "main"}, map[string]string{"NPM_TOKEN": "npm", "DEPLOY_KEY": "deploy"}},
+		{"push to branch scoped out", &core.Payload{EventType: core.EventPush, BranchName: "dev"}, map[string]string{"NPM_TOKEN": "npm"}},
+		{"pull request from fork", &core.Payload{EventType: core.EventPullRequest, BranchName: "main", ForkSlug: "fork/repo"}, map[string]string{}},
+		{"unknown build", nil, map[string]string{"NPM_TOKEN": "npm", "DEPLOY_KEY": "deploy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.GetRepoSecretBytes("repo", tt.build)
+			if err != nil {
+				t.Fatalf("GetRepoSecretBytes() error = %v", err)
+			}
+			var secrets map[string]string
+			if err := json.Unmarshal(got, &secrets); err != nil {
+				t.Fatalf("failed to unmarshal secrets, error: %v", err)
+			}
+			assert.Equal(t, tt.want, secrets)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+)
+
+const (
+	vaultRequestTimeout = 30 * time.Second
+	vaultTokenEnv       = "VAULT_TOKEN"
+	defaultVaultMount   = "secret"
+	defaultVaultPath    = "tas"
+	defaultKVVersion    = 2
+)
+
+// vaultProvider serves the secrets in the HashiCorp Vault KV secrets engine. The git token is read from
+// the token and token_type keys of <path>/git, and the secrets of a repo from the keys of <path>/repos/<repo>.
+type vaultProvider struct {
+	cfg        config.VaultConfig
+	httpClient http.Client
+	logger     lumber.Logger
+}
+
+func newVaultProvider(cfg *config.VaultConfig, logger lumber.Logger) *vaultProvider {
+	p := &vaultProvider{
+		cfg:        *cfg,
+		httpClient: http.Client{Timeout: vaultRequestTimeout},
+		logger:     logger,
+	}
+	if p.cfg.MountPath == "" {
+		p.cfg.MountPath = defaultVaultMount
+	}
+	if p.cfg.Path == "" {
+		p.cfg.Path = defaultVaultPath
+	}
+	if p.cfg.KVVersion == 0 {
+		p.cfg.KVVersion = defaultKVVersion
+	}
+	return p
+}
+
+func (p *vaultProvider) Name() string {
+	return string(config.VaultSecretProvider)
+}
+
+func (p *vaultProvider) GetRepoSecrets(ctx context.Context, repo string) (map[string]string, error) {
+	return p.read(ctx, path.Join(p.cfg.Path, repoSecretsName, path.Clean("/"+repo)))
+}
+
+func (p *vaultProvider) GetGitSecrets(ctx context.Context) ([]config.GitConfig, error) {
+	secrets, err := p.read(ctx, path.Join(p.cfg.Path, gitSecretName))
+	if err != nil || secrets[gitTokenKey] == "" {
+		return nil, err
+	}
+	return []config.GitConfig{{Token: secrets[gitTokenKey], TokenType: secrets[gitTokenTypeKey]}}, nil
+}
+
+// read returns the keys of the secret at secretPath, nil if the secret does not exist
+func (p *vaultProvider) read(ctx context.Context, secretPath string) (map[string]string, error) {
+	token, err := p.getToken()
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := url.Parse(p.cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	if p.cfg.KVVersion == 1 {
+		endpoint.Path = path.Join(endpoint.Path, "v1", p.cfg.MountPath, secretPath)
+	} else {
+		endpoint.Path = path.Join(endpoint.Path, "v1", p.cfg.MountPath, "data", secretPath)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		p.logger.Debugf("secret %s not found in vault", secretPath)
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error reading secret %s from vault, status code %d", secretPath, resp.StatusCode)
+	}
+	return parseVaultSecret(body, p.cfg.KVVersion)
+}
+
+// getToken returns the vault token, the token file is read on every request as it can be renewed by a vault agent
+func (p *vaultProvider) getToken() (string, error) {
+	if p.cfg.Token != "" {
+		return p.cfg.Token, nil
+	}
+	if p.cfg.TokenFile != "" {
+		return readSecretFile(p.cfg.TokenFile)
+	}
+	if token := os.Getenv(vaultTokenEnv); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("error finding vault token, set Token, TokenFile or the %s env variable", vaultTokenEnv)
+}
+
+// parseVaultSecret returns the keys of the secret in a vault read response, the values
+// which are not strings are returned as JSON.
+func parseVaultSecret(body []byte, kvVersion int) (map[string]string, error) {
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	data := resp.Data
+	if kvVersion != 1 {
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &versioned); err != nil {
+			return nil, err
+		}
+		data = versioned.Data
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	// a deleted version of a KV v2 secret has null data
+	if len(values) == 0 {
+		return nil, nil
+	}
+	secrets := make(map[string]string, len(values))
+	for key, value := range values {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = strings.TrimSpace(string(value))
+		}
+		secrets[key] = s
+	}
+	return secrets, nil
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/testutils"
+)
+
+// newVaultServer returns a stand-in of the vault dev server serving the secrets by their request path
+func newVaultServer(t *testing.T, secrets map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultProvider(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	server := newVaultServer(t, map[string]string{
+		"/v1/secret/data/tas/repos/my-repo": `{"data":{"data":{"NPM_TOKEN":"npm","PORT":5432},"metadata":{"version":1}}}`,
+		"/v1/secret/data/tas/git":           `{"data":{"data":{"token":"git-token","token_type":"Bearer"}}}`,
+		"/v1/kv/ci/repos/my-repo":           `{"data":{"NPM_TOKEN":"npm-v1"}}`,
+	})
+
+	tests := []struct {
+		name        string
+		cfg         config.VaultConfig
+		repo        string
+		wantSecrets map[string]string
+		wantGit     []config.GitConfig
+		wantErr     bool
+	}{
+		{
+			"Test KV v2",
+			config.VaultConfig{Address: server.URL, Token: "root"},
+			"my-repo",
+			map[string]string{"NPM_TOKEN": "npm", "PORT": "5432"},
+			[]config.GitConfig{{Token: "git-token", TokenType: "Bearer"}},
+			false,
+		},
+		{
+			"Test KV v1",
+			config.VaultConfig{Address: server.URL, Token: "root", MountPath: "kv", Path: "ci", KVVersion: 1},
+			"my-repo",
+			map[string]string{"NPM_TOKEN": "npm-v1"},
+			nil,
+			false,
+		},
+		{
+			"Test missing secret",
+			config.VaultConfig{Address: server.URL, Token: "root"},
+			"other-repo",
+			nil,
+			[]config.GitConfig{{Token: "git-token", TokenType: "Bearer"}},
+			false,
+		},
+		{
+			"Test invalid token",
+			config.VaultConfig{Address: server.URL, Token: "invalid"},
+			"my-repo",
+			nil,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newVaultProvider(&tt.cfg, logger)
+			got, err := p.GetRepoSecrets(context.TODO(), tt.repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("vaultProvider.GetRepoSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.wantSecrets) {
+				t.Errorf("vaultProvider.GetRepoSecrets() = %v, want %v", got, tt.wantSecrets)
+			}
+			gitSecrets, err := p.GetGitSecrets(context.TODO())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("vaultProvider.GetGitSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gitSecrets, tt.wantGit) {
+				t.Errorf("vaultProvider.GetGitSecrets() = %v, want %v", gitSecrets, tt.wantGit)
+			}
+		})
+	}
+}
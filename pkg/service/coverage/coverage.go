@@ -71,8 +71,9 @@ func New(execManager core.ExecutionManager,
 
 // mergeCodeCoverageFiles merge all the coverage.json into single entity
 func (c *codeCoverageService) mergeCodeCoverageFiles(ctx context.Context, commitDir, coverageManifestPath string, threshold bool) error {
+	logger := lumber.FromContext(ctx, c.logger)
 	if _, err := os.Lstat(commitDir); os.IsNotExist(err) {
-		c.logger.Errorf("coverage files not found, skipping merge")
+		logger.Errorf("coverage files not found, skipping merge")
 		return nil
 	}
 
@@ -97,11 +98,13 @@ func (c *codeCoverageService) mergeCodeCoverageFiles(ctx context.Context, commit
 		command = fmt.Sprintf("%s --coverageManifest %s", command, coverageManifestPath)
 	}
 	commands := []string{command}
-	return c.execManager.ExecuteInternalCommands(ctx, core.CoverageMerge, commands, "", nil, nil)
+	_, err := c.execManager.ExecuteInternalCommands(ctx, core.CoverageMerge, commands, "", nil, nil)
+	return err
 }
 
 // MergeAndUpload compress the file and upload in azure blob
 func (c *codeCoverageService) MergeAndUpload(ctx context.Context, payload *core.Payload) error {
+	logger := lumber.FromContext(ctx, c.logger)
 	var parentCommitDir, repoDir string
 	var g errgroup.Group
 	// change variable name
@@ -123,23 +126,23 @@ func (c *codeCoverageService) MergeAndUpload(ctx context.Context, payload *core.
 
 	for _, commit := range payload.Commits {
 		commitDir := filepath.Join(repoDir, commit.Sha)
-		c.logger.Debugf("commit directory %s", commitDir)
+		logger.Debugf("commit directory %s", commitDir)
 
 		if _, err := os.Lstat(commitDir); os.IsNotExist(err) {
-			c.logger.Errorf("code coverage directory not found commit id %s", commit.Sha)
+			logger.Errorf("code coverage directory not found commit id %s", commit.Sha)
 			return err
 		}
 		coverageManifestPath := filepath.Join(commitDir, manifestJSONFileName)
 
 		manifestPayload, err := c.parseManifestFile(coverageManifestPath)
 		if err != nil {
-			c.logger.Errorf("failed to parse manifest file: %s, error :%v", commitDir, err)
+			logger.Errorf("failed to parse manifest file: %s, error :%v", commitDir, err)
 			return err
 		}
 		//skip copy of parent directory if all test files executed
 		if !manifestPayload.AllFilesExecuted {
 			if err := c.copyFromParentCommitDir(parentCommitDir, commitDir, manifestPayload.Removedfiles...); err != nil {
-				c.logger.Errorf("failed to copy coverage files from %s to %s, error :%v", parentCommitDir, commitDir, err)
+				logger.Errorf("failed to copy coverage files from %s to %s, error :%v", parentCommitDir, commitDir, err)
 				return err
 			}
 		}
@@ -148,14 +151,14 @@ func (c *codeCoverageService) MergeAndUpload(ctx context.Context, payload *core.
 			thresholdEnabled = true
 		}
 		if err := c.mergeCodeCoverageFiles(ctx, commitDir, coverageManifestPath, thresholdEnabled); err != nil {
-			c.logger.Errorf("failed to merge coverage files %v", err)
+			logger.Errorf("failed to merge coverage files %v", err)
 			return err
 		}
-		c.logger.Debugf("compressed file name %v", compressedFileName)
+		logger.Debugf("compressed file name %v", compressedFileName)
 
 		g.Go(func() error {
 			if err := c.zstd.Compress(ctx, compressedFileName, false, repoDir, commit.Sha); err != nil {
-				c.logger.Errorf("failed to compress coverage files %v", err)
+				logger.Errorf("failed to compress coverage files %v", err)
 				return err
 			}
 			_, err := c.uploadFile(ctx, repoBlobPath, compressedFileName, commit.Sha)
@@ -174,7 +177,7 @@ func (c *codeCoverageService) MergeAndUpload(ctx context.Context, payload *core.
 			return err
 		})
 		if err = g.Wait(); err != nil {
-			c.logger.Errorf("failed to upload files to azure blob %v", err)
+			logger.Errorf("failed to upload files to azure blob %v", err)
 			return err
 		}
 		blobURL = strings.TrimSuffix(blobURL, fmt.Sprintf("/%s", mergedcoverageJSON))
@@ -216,9 +219,10 @@ func (c *codeCoverageService) parseManifestFile(filepath string) (core.CoverageM
 }
 
 func (c *codeCoverageService) downloadAndDecompressParentCommitDir(ctx context.Context, coverage parentCommitCoverage, repoDir string) error {
+	logger := lumber.FromContext(ctx, c.logger)
 	u, err := url.Parse(coverage.Bloblink)
 	if err != nil {
-		c.logger.Errorf("failed to parse blob link %s, error :%v", coverage.Bloblink, err)
+		logger.Errorf("failed to parse blob link %s, error :%v", coverage.Bloblink, err)
 		return err
 	}
 	u.Path = path.Join(u.Path, compressedFileName)
@@ -229,7 +233,7 @@ func (c *codeCoverageService) downloadAndDecompressParentCommitDir(ctx context.C
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		c.logger.Errorf("error while making http request %v", err)
+		logger.Errorf("error while making http request %v", err)
 		return err
 	}
 	defer resp.Body.Close()
@@ -239,7 +243,7 @@ func (c *codeCoverageService) downloadAndDecompressParentCommitDir(ctx context.C
 	}
 
 	parentCommitFilePath := filepath.Join(repoDir, coverage.ParentCommit+".tzst")
-	c.logger.Debugf("parent commit file path %s", parentCommitFilePath)
+	logger.Debugf("parent commit file path %s", parentCommitFilePath)
 	out, err := os.Create(parentCommitFilePath)
 	if err != nil {
 		return err
@@ -252,7 +256,7 @@ func (c *codeCoverageService) downloadAndDecompressParentCommitDir(ctx context.C
 
 	// decompress the file in temp directory as we cannot decompress inside azure file volume
 	if err := c.zstd.Decompress(ctx, parentCommitFilePath, false, os.TempDir()); err != nil {
-		c.logger.Errorf("failed to decompress parent commit directory %v", err)
+		logger.Errorf("failed to decompress parent commit directory %v", err)
 		return err
 	}
 
@@ -261,7 +265,7 @@ func (c *codeCoverageService) downloadAndDecompressParentCommitDir(ctx context.C
 	// copy the coverage directories to shared volume,
 	// chmod is not allowed inside azure file volume so that is skipped Ref: https://stackoverflow.com/questions/58301985/permissions-on-azure-file
 	if err := fileutils.CopyDir(srcPath, destPath, false); err != nil {
-		c.logger.Errorf("failed to copy directory from src %s to dest %s, error %v", srcPath, destPath, err)
+		logger.Errorf("failed to copy directory from src %s to dest %s, error %v", srcPath, destPath, err)
 		return err
 	}
 	return nil
@@ -33,11 +33,15 @@ func Test_codeCoverageService_mergeCodeCoverageFiles(t *testing.T) {
 		mock.AnythingOfType("map[string]string"),
 		mock.AnythingOfType("map[string]string")).Return(
 		func(ctx context.Context, commType core.CommandType, comm []string,
-			cwd string, envMap, secretData map[string]string) error {
+			cwd string, envMap, secretData map[string]string) []*core.StepResult {
 			commandType = commType
 			commands = comm
 			return nil
 		},
+		func(ctx context.Context, commType core.CommandType, comm []string,
+			cwd string, envMap, secretData map[string]string) error {
+			return nil
+		},
 	)
 	coverageFiles := "../../../testutils/testdata/coverage/coverage-final.json ../../../testutils/testdata/coverage/sample/coverage-final.json"
 	commitDir := "../../../testutils/testdata"
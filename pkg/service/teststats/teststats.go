@@ -1,6 +1,7 @@
 package teststats
 
 import (
+	"context"
 	"sort"
 	"sync"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
 	"github.com/LambdaTest/test-at-scale/pkg/procfs"
 )
 
@@ -30,8 +32,9 @@ func New(cfg *config.NucleusConfig, logger lumber.Logger) (*ProcStats, error) {
 
 }
 
-// CaptureTestStats combines the ps stats for each test
-func (s *ProcStats) CaptureTestStats(pid int32, collectStats bool) error {
+// CaptureTestStats combines the ps stats for each test. The results posted by the runner are counted in the
+// progress of the task of ctx as they arrive, and passed on once the runner exits.
+func (s *ProcStats) CaptureTestStats(ctx context.Context, pid int32, collectStats bool) error {
 	ps, err := procfs.New(pid, global.SamplingTime, false)
 	if err != nil {
 		s.logger.Errorf("failed to find process stats with pid %d %v", pid, err)
@@ -41,34 +44,72 @@ func (s *ProcStats) CaptureTestStats(pid int32, collectStats bool) error {
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-		processStats := ps.GetStatsInInterval()
+		exited := make(chan []*procfs.Stats, 1)
+		go func() {
+			exited <- ps.GetStatsInInterval()
+		}()
+		var executionResults *core.ExecutionResults
+		var processStats []*procfs.Stats
+		for running := true; running; {
+			select {
+			case results := <-s.ExecutionResultInputChannel:
+				executionResults = addResults(ctx, executionResults, &results)
+			case processStats = <-exited:
+				running = false
+			}
+		}
+		// the results posted right before the runner exited
+		for received := true; received; {
+			select {
+			case results := <-s.ExecutionResultInputChannel:
+				executionResults = addResults(ctx, executionResults, &results)
+			default:
+				received = false
+			}
+		}
 		if len(processStats) == 0 {
 			s.logger.Errorf("no process stats found with pid %d", pid)
 		}
-		select {
-		case executionResults := <-s.ExecutionResultInputChannel:
-			if collectStats {
-				for ind := range executionResults.Results {
-					// Refactor the impl of below 2 functions using generics when Go 1.18 arrives
-					// https://www.freecodecamp.org/news/generics-in-golang/
-					s.appendStatsToTests(executionResults.Results[ind].TestPayload, processStats)
-					s.appendStatsToTestSuites(executionResults.Results[ind].TestSuitePayload, processStats)
-				}
-			}
-			s.ExecutionResultOutputChannel <- &executionResults
-		default:
+		if executionResults == nil {
 			// Can reach here in 2 cases (ie `/results` API wasn't called):
 			// 1. runner process exited with zero exit exitCode but no testFiles were run (changes in Readme.md etc)
 			// 2. runner process exited with non-zero exitCode
 			s.logger.Warnf("No test results found, pid %d", pid)
 			s.ExecutionResultOutputChannel <- nil
+			return
+		}
+		if collectStats {
+			for ind := range executionResults.Results {
+				// Refactor the impl of below 2 functions using generics when Go 1.18 arrives
+				// https://www.freecodecamp.org/news/generics-in-golang/
+				s.appendStatsToTests(executionResults.Results[ind].TestPayload, processStats)
+				s.appendStatsToTestSuites(executionResults.Results[ind].TestSuitePayload, processStats)
+			}
 		}
+		s.ExecutionResultOutputChannel <- executionResults
 	}()
 
 	return nil
 }
 
-// processStats is RecordTime sorted
+// addResults adds the results posted by the runner to the results received so far,
+// counting their tests by status and in the progress of the task of ctx
+func addResults(ctx context.Context, received, results *core.ExecutionResults) *core.ExecutionResults {
+	completed := 0
+	for i := range results.Results {
+		for j := range results.Results[i].TestPayload {
+			metrics.RecordTest(results.Results[i].TestPayload[j].Status)
+		}
+		completed += len(results.Results[i].TestPayload)
+	}
+	core.AddTestsCompleted(ctx, completed)
+	if received == nil {
+		return results
+	}
+	received.Results = append(received.Results, results.Results...)
+	return received
+}
+
 func (s *ProcStats) getProcsForInterval(start, end time.Time, processStats []*procfs.Stats) []*procfs.Stats {
 	n := len(processStats)
 	left := sort.Search(n, func(i int) bool { return !processStats[i].RecordTime.Before(start) })
@@ -1,13 +1,16 @@
 package teststats
 
 import (
+	"context"
 	"fmt"
+	"os/exec"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 	"github.com/LambdaTest/test-at-scale/pkg/procfs"
 	"github.com/LambdaTest/test-at-scale/testutils"
@@ -312,3 +315,36 @@ func TestProcStats_appendStatsToTestSuites(t *testing.T) {
 		})
 	}
 }
+
+func TestProcStats_CaptureTestStats(t *testing.T) {
+	cfg, _ := testutils.GetConfig()
+	logger, _ := testutils.GetLogger()
+	s, _ := New(cfg, logger)
+	ctx := core.ContextWithProgress(context.Background())
+
+	cmd := exec.Command("sleep", "0.5")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start process, error: %v", err)
+	}
+	if err := s.CaptureTestStats(ctx, int32(cmd.Process.Pid), false); err != nil {
+		t.Fatalf("CaptureTestStats() error = %v", err)
+	}
+	for _, testID := range []string{"test-1", "test-2"} {
+		s.ExecutionResultInputChannel <- core.ExecutionResults{
+			Results: []core.ExecutionResult{{TestPayload: []core.TestPayload{{TestID: testID, Status: "passed"}}}},
+		}
+	}
+	// the tests are counted while the runner is still running
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for core.ProgressFromContext(ctx).TestsCompleted != 2 && time.Now().Before(deadline) {
+		time.Sleep(global.SamplingTime)
+	}
+	if got := core.ProgressFromContext(ctx).TestsCompleted; got != 2 {
+		t.Errorf("tests completed while running = %d, want 2", got)
+	}
+	_ = cmd.Wait()
+	result := <-s.ExecutionResultOutputChannel
+	if result == nil || len(result.Results) != 2 {
+		t.Fatalf("CaptureTestStats() results = %v, want the results of both posts", result)
+	}
+}
@@ -0,0 +1,181 @@
+package synapse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+)
+
+const (
+	logQueueSize       = 256
+	logSpoolFileName   = "logchunks.jsonl"
+	maxLogSpoolSize    = 512 << 20
+	maxLogMessageSize  = 4 << 20
+	logReplayQueueWait = time.Second
+)
+
+// SendLogChunk queues the log chunk to be sent over the websocket. The chunks are spooled to
+// disk while disconnected or when the queue is full, errs.ErrLogQueueFull is returned when
+// the spool is full as well, so that the sender retries later.
+func (s *synapse) SendLogChunk(chunk *core.LogChunk) error {
+	message := createLogChunkMessage(chunk)
+	messageJSON, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if !s.Connected() {
+		return s.spoolLogMessage(messageJSON)
+	}
+	select {
+	case s.LogChan <- messageJSON:
+		return nil
+	default:
+		if err := s.spoolLogMessage(messageJSON); err != nil {
+			return err
+		}
+		// the spool is replayed as the queue is drained
+		go s.replayLogSpool()
+		return nil
+	}
+}
+
+// Connected returns whether the websocket connection to LT cloud is open
+func (s *synapse) Connected() bool {
+	return atomic.LoadInt32(&s.connected) == 1
+}
+
+func (s *synapse) setConnected(connected bool) {
+	metrics.SetConnected(connected)
+	if connected {
+		atomic.StoreInt32(&s.connected, 1)
+		return
+	}
+	atomic.StoreInt32(&s.connected, 0)
+}
+
+// spoolLogMessage appends the log message to the spool file, which is replayed once connected
+func (s *synapse) spoolLogMessage(messageJSON []byte) error {
+	s.spoolMu.Lock()
+	defer s.spoolMu.Unlock()
+	if err := os.MkdirAll(s.spoolDir, global.DirectoryPermissions); err != nil {
+		return err
+	}
+	spoolFile := filepath.Join(s.spoolDir, logSpoolFileName)
+	if info, err := os.Stat(spoolFile); err == nil && info.Size()+int64(len(messageJSON)) > maxLogSpoolSize {
+		return fmt.Errorf("%w, log spool %s exceeds %d bytes", errs.ErrLogQueueFull, spoolFile, maxLogSpoolSize)
+	}
+	f, err := os.OpenFile(spoolFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(messageJSON, '\n'))
+	return err
+}
+
+// replayLogSpool sends the log messages spooled while disconnected or the queue was full. The messages which could not
+// be sent before disconnecting again are kept for the next replay.
+func (s *synapse) replayLogSpool() {
+	if !atomic.CompareAndSwapInt32(&s.replaying, 0, 1) {
+		return
+	}
+	// the messages spooled during the replay are replayed as well
+	for {
+		s.spoolMu.Lock()
+		if !s.spooled() {
+			// released under the lock, so that the replay of a message spooled next is not skipped
+			atomic.StoreInt32(&s.replaying, 0)
+			s.spoolMu.Unlock()
+			return
+		}
+		s.spoolMu.Unlock()
+		if !s.replaySpoolFile() {
+			atomic.StoreInt32(&s.replaying, 0)
+			return
+		}
+	}
+}
+
+// spooled returns whether there are log messages to replay
+func (s *synapse) spooled() bool {
+	for _, name := range []string{logSpoolFileName, logSpoolFileName + ".replay"} {
+		if _, err := os.Stat(filepath.Join(s.spoolDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// replaySpoolFile replays the spool file, it returns true if the whole spool was sent.
+func (s *synapse) replaySpoolFile() bool {
+	replayFile := filepath.Join(s.spoolDir, logSpoolFileName+".replay")
+	s.spoolMu.Lock()
+	// a replay file left by an interrupted replay is sent before the newer spool
+	if _, err := os.Stat(replayFile); os.IsNotExist(err) {
+		err = os.Rename(filepath.Join(s.spoolDir, logSpoolFileName), replayFile)
+		if err != nil {
+			s.spoolMu.Unlock()
+			if !os.IsNotExist(err) {
+				s.logger.Errorf("error in preparing log spool for replay, error %v", err)
+			}
+			return false
+		}
+	}
+	s.spoolMu.Unlock()
+
+	f, err := os.Open(replayFile)
+	if err != nil {
+		s.logger.Errorf("error in opening log spool %s, error %v", replayFile, err)
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogMessageSize)
+	var remaining []byte
+	sent := 0
+	for scanner.Scan() {
+		messageJSON := append([]byte{}, scanner.Bytes()...)
+		if remaining != nil || !s.queueSpooledLogMessage(messageJSON) {
+			remaining = append(append(remaining, messageJSON...), '\n')
+			continue
+		}
+		sent++
+	}
+	if err := scanner.Err(); err != nil {
+		s.logger.Errorf("error in reading log spool %s, error %v", replayFile, err)
+	}
+	s.logger.Debugf("replayed %d spooled log chunks", sent)
+	if remaining != nil {
+		if err := os.WriteFile(replayFile, remaining, 0600); err != nil {
+			s.logger.Errorf("error in writing back log spool %s, error %v", replayFile, err)
+		}
+		return false
+	}
+	if err := os.Remove(replayFile); err != nil {
+		s.logger.Errorf("error in removing log spool %s, error %v", replayFile, err)
+		return false
+	}
+	return true
+}
+
+// queueSpooledLogMessage waits for the log queue to have room for the message,
+// it returns false if disconnected meanwhile.
+func (s *synapse) queueSpooledLogMessage(messageJSON []byte) bool {
+	for s.Connected() {
+		select {
+		case s.LogChan <- messageJSON:
+			return true
+		case <-time.After(logReplayQueueWait):
+		}
+	}
+	return false
+}
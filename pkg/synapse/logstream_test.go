@@ -0,0 +1,91 @@
+package synapse
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendLogChunk(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	s := &synapse{
+		logger:   logger,
+		LogChan:  make(chan []byte, 2),
+		spoolDir: t.TempDir(),
+	}
+	chunk := func(seq int) *core.LogChunk {
+		return &core.LogChunk{TaskID: "task", Seq: seq, Lines: []core.LogLine{{Text: "line"}}}
+	}
+
+	// chunks are spooled while disconnected
+	for seq := 0; seq < 3; seq++ {
+		assert.NoError(t, s.SendLogChunk(chunk(seq)))
+	}
+	assert.Len(t, s.LogChan, 0)
+
+	// and queued once connected, until the queue is full
+	s.setConnected(true)
+	assert.NoError(t, s.SendLogChunk(chunk(3)))
+	assert.NoError(t, s.SendLogChunk(chunk(4)))
+	// which spools the chunks again
+	assert.NoError(t, s.SendLogChunk(chunk(5)))
+
+	// the spooled chunks are replayed as the queue is drained
+	var seqs []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for len(seqs) < 6 {
+			var message core.Message
+			assert.NoError(t, json.Unmarshal(<-s.LogChan, &message))
+			assert.Equal(t, core.MsgLogChunk, message.Type)
+			var received core.LogChunk
+			assert.NoError(t, json.Unmarshal(message.Content, &received))
+			seqs = append(seqs, received.Seq)
+		}
+	}()
+	<-done
+	assert.Equal(t, []int{3, 4, 0, 1, 2, 5}, seqs)
+	assert.Eventually(t, func() bool {
+		files, err := os.ReadDir(s.spoolDir)
+		return err == nil && len(files) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReplayLogSpoolDisconnected(t *testing.T) {
+	logger, err := testutils.GetLogger()
+	if err != nil {
+		t.Fatalf("Couldn't initialize logger, error: %v", err)
+	}
+	s := &synapse{
+		logger:   logger,
+		LogChan:  make(chan []byte, 1),
+		spoolDir: t.TempDir(),
+	}
+	for seq := 0; seq < 3; seq++ {
+		assert.NoError(t, s.SendLogChunk(&core.LogChunk{Seq: seq}))
+	}
+	// the chunks not queued before disconnecting are kept for the next replay
+	s.setConnected(true)
+	go func() {
+		<-s.LogChan
+		s.setConnected(false)
+	}()
+	s.replayLogSpool()
+	remaining, err := os.ReadFile(filepath.Join(s.spoolDir, logSpoolFileName+".replay"))
+	assert.NoError(t, err)
+	var message core.Message
+	assert.NoError(t, json.Unmarshal(remaining, &message))
+	var chunk core.LogChunk
+	assert.NoError(t, json.Unmarshal(message.Content, &chunk))
+	assert.Equal(t, 2, chunk.Seq)
+}
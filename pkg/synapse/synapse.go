@@ -10,6 +10,7 @@ import (
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
 	"github.com/LambdaTest/test-at-scale/pkg/tasconfigdownloader"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/denisbrodbeck/machineid"
@@ -20,6 +21,11 @@ import (
 // All constant related to synapse
 const (
 	Repo                             = "repo"
+	GitProvider                      = "git-provider"
+	GitHost                          = "git-host"
+	EventType                        = "event-type"
+	Branch                           = "branch"
+	Fork                             = "fork"
 	BuildID                          = "build-id"
 	JobID                            = "job-id"
 	Mode                             = "mode"
@@ -27,6 +33,8 @@ const (
 	DuplicateConnectionErr           = "Synapse already has an open connection"
 	AuthenticationFailed             = "Synapse authentication failed"
 	duplicateConnectionSleepDuration = 15 * time.Second
+	messageQueue                     = "message"
+	logQueue                         = "log"
 )
 
 var buildAbortMap = make(map[string]bool)
@@ -38,10 +46,15 @@ type synapse struct {
 	logger                   lumber.Logger
 	MsgErrChan               chan struct{}
 	MsgChan                  chan []byte
+	LogChan                  chan []byte
 	ConnectionAborted        chan struct{}
 	InvalidConnectionRequest chan struct{}
 	LogoutRequired           bool
 	tasConfigDownloader      *tasconfigdownloader.TASConfigDownloader
+	connected                int32
+	replaying                int32
+	spoolMu                  sync.Mutex
+	spoolDir                 string
 }
 
 // New returns new instance of synapse
@@ -51,17 +64,22 @@ func New(
 	secretsManager core.SecretsManager,
 	tasConfigDownloader *tasconfigdownloader.TASConfigDownloader,
 ) core.SynapseManager {
-	return &synapse{
+	s := &synapse{
 		runner:                   runner,
 		logger:                   logger,
 		secretsManager:           secretsManager,
 		MsgErrChan:               make(chan struct{}),
 		InvalidConnectionRequest: make(chan struct{}),
 		MsgChan:                  make(chan []byte, 1024),
+		LogChan:                  make(chan []byte, logQueueSize),
 		ConnectionAborted:        make(chan struct{}, 10),
 		LogoutRequired:           true,
 		tasConfigDownloader:      tasConfigDownloader,
+		spoolDir:                 global.LogSpoolPath,
 	}
+	metrics.RegisterQueue(messageQueue, func() int { return len(s.MsgChan) })
+	metrics.RegisterQueue(logQueue, func() int { return len(s.LogChan) })
+	return s
 }
 
 func (s *synapse) InitiateConnection(
@@ -103,10 +121,13 @@ func (s *synapse) openAndMaintainConnection(ctx context.Context, connectionFaile
 			s.conn = conn
 			s.logger.Debugf("synapse connected to TAS server")
 			s.login()
+			s.setConnected(true)
+			go s.replayLogSpool()
 			if !s.connectionHandler(ctx, conn, connectionFailed) {
 				return nil
 			}
 			s.MsgErrChan = make(chan struct{})
+			metrics.RecordReconnect()
 			// re-listen for any connection breaks
 			go s.openAndMaintainConnection(ctx, connectionFailed)
 			return nil
@@ -127,6 +148,7 @@ func (s *synapse) connectionHandler(ctx context.Context, conn *websocket.Conn, c
 	normalCloser := make(chan struct{})
 	ctxDone := false
 	defer func() {
+		s.setConnected(false)
 		// if gracefully terminated, wait for logout message to be sent
 		if !ctxDone {
 			conn.Close()
@@ -261,33 +283,42 @@ func (s *synapse) processTask(message core.Message) {
 	if err != nil {
 		s.logger.Errorf("error unmarshaling core.task")
 	}
+	setGitLabels(&runnerOpts)
 
+	ctx, logger := lumber.ContextWithFields(context.TODO(), s.logger, lumber.Fields{
+		lumber.FieldJobID:   runnerOpts.Label[JobID],
+		lumber.FieldBuildID: runnerOpts.Label[BuildID],
+	})
 	// sending job started updates
 	if runnerOpts.PodType == core.NucleusPod {
 		jobInfo := CreateJobInfo(core.JobStarted, &runnerOpts, "")
-		s.logger.Infof("Sending update to neuron %+v", jobInfo)
+		logger.Infof("Sending update to neuron %+v", jobInfo)
 		resourceStatsMessage := CreateJobUpdateMessage(jobInfo)
 		s.writeMessageToBuffer(&resourceStatsMessage)
 	}
 	// mounting secrets to container
 	runnerOpts.HostVolumePath = fmt.Sprintf("/tmp/synapse/data/%s", runnerOpts.ContainerName)
 
-	s.runAndUpdateJobStatus(&runnerOpts)
+	s.runAndUpdateJobStatus(ctx, &runnerOpts)
 }
 
 // runAndUpdateJobStatus intiate and sends jobs status
-func (s *synapse) runAndUpdateJobStatus(runnerOpts *core.RunnerOptions) {
+func (s *synapse) runAndUpdateJobStatus(ctx context.Context, runnerOpts *core.RunnerOptions) {
+	logger := lumber.FromContext(ctx, s.logger)
 	// starting container
 	statusChan := make(chan core.ContainerStatus)
 	defer close(statusChan)
-	s.logger.Debugf("starting container %s for build %s...", runnerOpts.ContainerName, runnerOpts.Label[BuildID])
-	go s.runner.Initiate(context.TODO(), runnerOpts, statusChan)
+	logger.Debugf("starting container %s for build %s...", runnerOpts.ContainerName, runnerOpts.Label[BuildID])
+	specs := GetResources(runnerOpts.Tier)
+	metrics.ReserveResources(specs.CPU, specs.RAM)
+	go s.runner.Initiate(ctx, runnerOpts, statusChan)
 
 	status := <-statusChan
 	// post job completion steps
-	s.logger.Debugf("jobID %s, buildID %s  status  %+v", runnerOpts.Label[JobID], runnerOpts.Label[BuildID], status)
+	logger.Debugf("jobID %s, buildID %s  status  %+v", runnerOpts.Label[JobID], runnerOpts.Label[BuildID], status)
 
 	s.sendResourceUpdates(core.ResourceRelease, runnerOpts, runnerOpts.Label[JobID], runnerOpts.Label[BuildID])
+	metrics.ReleaseResources(specs.CPU, specs.RAM)
 	jobStatus := core.JobFailed
 	if status.Done {
 		jobStatus = core.JobCompleted
@@ -295,8 +326,9 @@ func (s *synapse) runAndUpdateJobStatus(runnerOpts *core.RunnerOptions) {
 	if buildAbortMap[runnerOpts.Label[BuildID]] {
 		jobStatus = core.JobAborted
 	}
+	metrics.RecordJob(string(jobStatus))
 	jobInfo := CreateJobInfo(jobStatus, runnerOpts, status.Error.Message)
-	s.logger.Infof("Sending update to neuron %+v", jobInfo)
+	logger.Infof("Sending update to neuron %+v", jobInfo)
 	resourceStatsMessage := CreateJobUpdateMessage(jobInfo)
 	s.writeMessageToBuffer(&resourceStatsMessage)
 }
@@ -304,6 +336,7 @@ func (s *synapse) runAndUpdateJobStatus(runnerOpts *core.RunnerOptions) {
 // login write login message to lambdatest server
 func (s *synapse) login() {
 	cpu, ram := s.runner.GetInfo(context.TODO())
+	metrics.SetResourcesAvailable(cpu, ram)
 	id, err := machineid.ProtectedID("synapaseMeta")
 	if err != nil {
 		s.logger.Fatalf("Error while generating unique id")
@@ -380,6 +413,16 @@ func (s *synapse) messageWriter(conn *websocket.Conn) {
 				close(s.MsgErrChan)
 				return
 			}
+		case messageJSON := <-s.LogChan:
+			if err := conn.WriteMessage(websocket.TextMessage, messageJSON); err != nil {
+				s.logger.Errorf("error sending log chunk to the server error %v", err)
+				if spoolErr := s.spoolLogMessage(messageJSON); spoolErr != nil {
+					s.logger.Errorf("error spooling log chunk, error %v", spoolErr)
+				}
+				s.MsgErrChan <- struct{}{}
+				close(s.MsgErrChan)
+				return
+			}
 		}
 	}
 }
@@ -398,7 +441,10 @@ func (s *synapse) processYMLParsingRequest(message core.Message) {
 		})
 		return
 	}
-	oauth := s.secretsManager.GetOauthToken()
+	oauth := s.secretsManager.GetOauthToken(&core.GitRepo{
+		Provider: parsingReqMsg.GitProvider,
+		Slug:     parsingReqMsg.RepoSlug,
+	})
 
 	tasOutput, err := s.tasConfigDownloader.GetTASConfig(context.TODO(), parsingReqMsg.GitProvider,
 		parsingReqMsg.CommitID,
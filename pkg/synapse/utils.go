@@ -2,8 +2,11 @@ package synapse
 
 import (
 	"encoding/json"
+	"strconv"
+	"strings"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
 )
 
 // CreateLoginMessage creates message of type login
@@ -41,6 +44,49 @@ func CreateJobInfo(status core.StatusType, runnerOpts *core.RunnerOptions, messa
 	return jobInfo
 }
 
+// GetBuild returns the event, branch and fork of the build of the job from its labels, to which the secret policy
+// is applied. It returns nil if the server does not send the labels, the policy is then only enforced by nucleus
+// which knows the build from its payload.
+func GetBuild(runnerOpts *core.RunnerOptions) *core.Payload {
+	eventType, branch := runnerOpts.Label[EventType], runnerOpts.Label[Branch]
+	fork, err := strconv.ParseBool(runnerOpts.Label[Fork])
+	if eventType == "" || branch == "" || err != nil {
+		return nil
+	}
+	build := &core.Payload{EventType: core.EventType(eventType), BranchName: branch}
+	if fork {
+		// the fork slug is not known to synapse, it only marks the build as coming from a fork
+		build.ForkSlug = runnerOpts.Label[Repo]
+	}
+	return build
+}
+
+// setGitLabels normalizes the git provider and host labels of the job, which scope the git credential
+// of the job, deriving each from the other when it is not set
+func setGitLabels(runnerOpts *core.RunnerOptions) {
+	if runnerOpts.Label == nil {
+		runnerOpts.Label = make(map[string]string)
+	}
+	provider := strings.ToLower(runnerOpts.Label[GitProvider])
+	host := strings.ToLower(runnerOpts.Label[GitHost])
+	if provider == "" {
+		for p, h := range global.GitProviderHostMap {
+			if h == host {
+				provider = p
+			}
+		}
+	}
+	if host == "" {
+		host = global.GitProviderHostMap[provider]
+	}
+	if provider != "" {
+		runnerOpts.Label[GitProvider] = provider
+	}
+	if host != "" {
+		runnerOpts.Label[GitHost] = host
+	}
+}
+
 // CreateJobUpdateMessage creates message of type job updates
 func CreateJobUpdateMessage(jobInfo core.JobInfo) core.Message {
 
@@ -88,3 +134,16 @@ func createYMlParsingResultMessage(ymlParsingOutput core.YMLParsingResultMessage
 		Success: true,
 	}
 }
+
+// createLogChunkMessage creates message for a chunk of streamed logs
+func createLogChunkMessage(chunk *core.LogChunk) core.Message {
+	chunkJSON, err := json.Marshal(chunk)
+	if err != nil {
+		return core.Message{}
+	}
+	return core.Message{
+		Type:    core.MsgLogChunk,
+		Content: chunkJSON,
+		Success: true,
+	}
+}
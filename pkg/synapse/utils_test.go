@@ -60,3 +60,53 @@ func TestCreateResourceStatsMessage(t *testing.T) {
 	assert.Equal(t, resourceStatsJSON, resourceStatsMessage.Content)
 	assert.Equal(t, core.MsgResourceStats, resourceStatsMessage.Type)
 }
+
+func TestGetBuild(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   *core.Payload
+	}{
+		{
+			"push",
+			map[string]string{Repo: "org/repo", EventType: "push", Branch: "main", Fork: "false"},
+			&core.Payload{EventType: core.EventPush, BranchName: "main"},
+		},
+		{
+			"pull request from fork",
+			map[string]string{Repo: "org/repo", EventType: "pull-request", Branch: "feature", Fork: "true"},
+			&core.Payload{EventType: core.EventPullRequest, BranchName: "feature", ForkSlug: "org/repo"},
+		},
+		{"unknown build", map[string]string{Repo: "org/repo"}, nil},
+		{"unknown fork", map[string]string{Repo: "org/repo", EventType: "push", Branch: "main"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetBuild(&core.RunnerOptions{Label: tt.labels}))
+		})
+	}
+}
+
+func TestSetGitLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   map[string]string
+	}{
+		{"provider", map[string]string{GitProvider: "GitHub"}, map[string]string{GitProvider: "github", GitHost: "github.com"}},
+		{"host", map[string]string{GitHost: "gitlab.com"}, map[string]string{GitProvider: "gitlab", GitHost: "gitlab.com"}},
+		{
+			"self hosted",
+			map[string]string{GitProvider: "github", GitHost: "github.mycorp.com"},
+			map[string]string{GitProvider: "github", GitHost: "github.mycorp.com"},
+		},
+		{"unknown", nil, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runnerOpts := &core.RunnerOptions{Label: tt.labels}
+			setGitLabels(runnerOpts)
+			assert.Equal(t, tt.want, runnerOpts.Label)
+		})
+	}
+}
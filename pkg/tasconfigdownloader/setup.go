@@ -31,15 +31,16 @@ func New(logger lumber.Logger) *TASConfigDownloader {
 
 func (t *TASConfigDownloader) GetTASConfig(ctx context.Context, gitProvider, commitID, repoSlug,
 	filePath string, oauth *core.Oauth, eventType core.EventType, licenseTier core.Tier) (*core.TASConfigDownloaderOutput, error) {
+	logger := lumber.FromContext(ctx, t.logger)
 	ymlPath, err := t.gitmanager.DownloadFileByCommit(ctx, gitProvider, repoSlug, commitID, filePath, oauth)
 	if err != nil {
-		t.logger.Errorf("error occurred while downloading file %s from %s for commitID %s, error %v", filePath, repoSlug, commitID, err)
+		logger.Errorf("error occurred while downloading file %s from %s for commitID %s, error %v", filePath, repoSlug, commitID, err)
 		return nil, err
 	}
 
 	version, err := t.tasconfigmanager.GetVersion(ymlPath)
 	if err != nil {
-		t.logger.Errorf("error reading version for tas config file %s, error %v", ymlPath, err)
+		logger.Errorf("error reading version for tas config file %s, error %v", ymlPath, err)
 		return nil, err
 	}
 
@@ -48,14 +49,14 @@ func (t *TASConfigDownloader) GetTASConfig(ctx context.Context, gitProvider, com
 		if supportedVersion := t.checkYmlValidityForOtherVersion(ctx, version, ymlPath, eventType,
 			licenseTier, filePath); supportedVersion != -1 {
 			errMsg := fmt.Sprintf(ymlVersionMismtachRemarks, global.TASYmlConfigurationDocLink)
-			t.logger.Errorf("error while parsing yml for commitID %s, error: %s", commitID, errMsg)
+			logger.Errorf("error while parsing yml for commitID %s, error: %s", commitID, errMsg)
 			return nil, errors.New(errMsg)
 		}
-		t.logger.Errorf("error while parsing yml for commitID %s error %v", commitID, err)
+		logger.Errorf("error while parsing yml for commitID %s error %v", commitID, err)
 		return nil, err
 	}
 	if err := os.Remove(ymlPath); err != nil {
-		t.logger.Errorf("failed to delete file %s , error %v", ymlPath, err)
+		logger.Errorf("failed to delete file %s , error %v", ymlPath, err)
 		return nil, err
 	}
 	return &core.TASConfigDownloaderOutput{Version: version, TASConfig: tasConfig}, nil
@@ -0,0 +1,114 @@
+package tasconfigmanager
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+	"github.com/LambdaTest/test-at-scale/pkg/errs"
+	"github.com/LambdaTest/test-at-scale/pkg/global"
+)
+
+var (
+	matrixKeyRegex   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	nodeVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+)
+
+// expandMatrix replaces every submodule of merge having a matrix with one variant per combination
+// of the matrix values, e.g. `api[node=18.17.0]`. The submodule matrix extends the matrix of merge,
+// overriding the values of the keys present in both.
+func expandMatrix(filePath string, merge *core.MergeV2) error {
+	subModules := make([]core.SubModule, 0, len(merge.SubModules))
+	for i := range merge.SubModules {
+		matrix := mergeMatrix(merge.Matrix, merge.SubModules[i].Matrix)
+		if err := validateMatrix(filePath, merge.SubModules[i].Name, matrix); err != nil {
+			return err
+		}
+		if len(matrix) == 0 {
+			subModules = append(subModules, merge.SubModules[i])
+			continue
+		}
+		for _, values := range getMatrixCombinations(matrix) {
+			variant := merge.SubModules[i]
+			variant.Name = getVariantName(variant.Name, values)
+			variant.MatrixValues = values
+			subModules = append(subModules, variant)
+		}
+	}
+	if len(subModules) > global.MaxMatrixVariants {
+		return errs.New(fmt.Sprintf("`matrix` expands to %d subModules, which is more than the maximum of %d in tas configuration file %s",
+			len(subModules), global.MaxMatrixVariants, filePath))
+	}
+	merge.SubModules = subModules
+	return nil
+}
+
+func mergeMatrix(base, override map[string][]string) map[string][]string {
+	matrix := make(map[string][]string, len(base)+len(override))
+	for k, v := range base {
+		matrix[k] = v
+	}
+	for k, v := range override {
+		matrix[k] = v
+	}
+	return matrix
+}
+
+// validateMatrix validates that matrix keys can be used as env names and node versions are exact versions
+func validateMatrix(filePath, subModuleName string, matrix map[string][]string) error {
+	for key, values := range matrix {
+		if !matrixKeyRegex.MatchString(key) {
+			return errs.New(fmt.Sprintf("invalid key `%s` in `matrix` of subModule %s in tas configuration file %s",
+				key, subModuleName, filePath))
+		}
+		seen := map[string]bool{}
+		for _, value := range values {
+			if key == global.MatrixNodeKey && !nodeVersionRegex.MatchString(value) {
+				return errs.New(fmt.Sprintf("invalid node version `%s` in `matrix` of subModule %s in tas configuration file %s",
+					value, subModuleName, filePath))
+			}
+			if seen[value] {
+				return errs.New(fmt.Sprintf("duplicate value `%s` for key `%s` in `matrix` of subModule %s in tas configuration file %s",
+					value, key, subModuleName, filePath))
+			}
+			seen[value] = true
+		}
+	}
+	return nil
+}
+
+// getMatrixCombinations returns the cartesian product of the matrix values
+func getMatrixCombinations(matrix map[string][]string) []map[string]string {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		next := make([]map[string]string, 0, len(combinations)*len(matrix[key]))
+		for _, combination := range combinations {
+			for _, value := range matrix[key] {
+				values := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					values[k] = v
+				}
+				values[key] = value
+				next = append(next, values)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func getVariantName(name string, values map[string]string) string {
+	coordinates := make([]string, 0, len(values))
+	for key, value := range values {
+		coordinates = append(coordinates, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(coordinates)
+	return fmt.Sprintf("%s[%s]", name, strings.Join(coordinates, ","))
+}
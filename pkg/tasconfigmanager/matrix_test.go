@@ -0,0 +1,95 @@
+package tasconfigmanager
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/pkg/core"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	filePath := "tas.yml"
+	tests := []struct {
+		name      string
+		merge     *core.MergeV2
+		wantNames []string
+		wantErr   error
+	}{
+		{
+			"Test without matrix",
+			&core.MergeV2{SubModules: []core.SubModule{{Name: "api"}, {Name: "web"}}},
+			[]string{"api", "web"},
+			nil,
+		},
+		{
+			"Test merge matrix",
+			&core.MergeV2{
+				Matrix:     map[string][]string{"node": {"16.20.0", "18.17.0"}},
+				SubModules: []core.SubModule{{Name: "api"}, {Name: "web"}},
+			},
+			[]string{"api[node=16.20.0]", "api[node=18.17.0]", "web[node=16.20.0]", "web[node=18.17.0]"},
+			nil,
+		},
+		{
+			"Test submodule matrix overrides merge matrix",
+			&core.MergeV2{
+				Matrix: map[string][]string{"node": {"16.20.0", "18.17.0"}},
+				SubModules: []core.SubModule{
+					{Name: "api", Matrix: map[string][]string{"node": {"18.17.0"}, "DB": {"mysql", "postgres"}}},
+				},
+			},
+			[]string{"api[DB=mysql,node=18.17.0]", "api[DB=postgres,node=18.17.0]"},
+			nil,
+		},
+		{
+			"Test invalid node version",
+			&core.MergeV2{SubModules: []core.SubModule{{Name: "api", Matrix: map[string][]string{"node": {"18"}}}}},
+			nil,
+			fmt.Errorf("invalid node version `18` in `matrix` of subModule api in tas configuration file %s", filePath),
+		},
+		{
+			"Test invalid key",
+			&core.MergeV2{SubModules: []core.SubModule{{Name: "api", Matrix: map[string][]string{"db-client": {"pg"}}}}},
+			nil,
+			fmt.Errorf("invalid key `db-client` in `matrix` of subModule api in tas configuration file %s", filePath),
+		},
+		{
+			"Test duplicate value",
+			&core.MergeV2{SubModules: []core.SubModule{{Name: "api", Matrix: map[string][]string{"DB": {"pg", "pg"}}}}},
+			nil,
+			fmt.Errorf("duplicate value `pg` for key `DB` in `matrix` of subModule api in tas configuration file %s", filePath),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expandMatrix(filePath, tt.merge)
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Fatalf("expandMatrix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			gotNames := make([]string, 0, len(tt.merge.SubModules))
+			for i := range tt.merge.SubModules {
+				gotNames = append(gotNames, tt.merge.SubModules[i].Name)
+			}
+			if !reflect.DeepEqual(gotNames, tt.wantNames) {
+				t.Errorf("expandMatrix() subModules = %v, want %v", gotNames, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestExpandMatrixValues(t *testing.T) {
+	merge := &core.MergeV2{
+		SubModules: []core.SubModule{{Name: "api", Matrix: map[string][]string{"node": {"18.17.0"}, "DB": {"mysql"}}}},
+	}
+	if err := expandMatrix("tas.yml", merge); err != nil {
+		t.Fatalf("expandMatrix() error = %v", err)
+	}
+	want := map[string]string{"node": "18.17.0", "DB": "mysql"}
+	if !reflect.DeepEqual(merge.SubModules[0].MatrixValues, want) {
+		t.Errorf("expandMatrix() matrix values = %v, want %v", merge.SubModules[0].MatrixValues, want)
+	}
+}
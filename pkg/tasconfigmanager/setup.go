@@ -6,13 +6,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"strings"
 
+	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/errs"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
 	"github.com/LambdaTest/test-at-scale/pkg/utils"
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 const packageJSON = "package.json"
@@ -40,12 +45,13 @@ func (tc *tasConfigManager) LoadAndValidate(ctx context.Context,
 	path string,
 	eventType core.EventType,
 	licenseTier core.Tier, tasFilePathInRepo string) (interface{}, error) {
+	logger := lumber.FromContext(ctx, tc.logger)
 	yamlFile, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, errs.New(fmt.Sprintf("Configuration file not found at path: %s", tasFilePathInRepo))
 		}
-		tc.logger.Errorf("Error while reading file, error %v", err)
+		logger.Errorf("Error while reading file, error %v", err)
 		return nil, errs.New(fmt.Sprintf("Error while reading configuration file at path: %s", tasFilePathInRepo))
 	}
 	if version < global.NewTASVersion {
@@ -59,6 +65,7 @@ func (tc *tasConfigManager) validateYMLV1(ctx context.Context,
 	eventType core.EventType,
 	licenseTier core.Tier,
 	filePath string) (*core.TASConfig, error) {
+	logger := lumber.FromContext(ctx, tc.logger)
 	tasConfig, err := utils.ValidateStructTASYmlV1(ctx, yamlFile, filePath)
 	if err != nil {
 		return nil, err
@@ -67,6 +74,24 @@ func (tc *tasConfigManager) validateYMLV1(ctx context.Context,
 	if tasConfig.CoverageThreshold == nil {
 		tasConfig.CoverageThreshold = new(core.CoverageThreshold)
 	}
+	if err := validateRunConditions(filePath, tasConfig.Prerun, tasConfig.Postrun); err != nil {
+		return nil, err
+	}
+	if err := validateArtifacts(filePath, tasConfig.Artifacts); err != nil {
+		return nil, err
+	}
+	if err := validateServices(filePath, tasConfig.Services); err != nil {
+		return nil, err
+	}
+	if err := validateMaskPatterns(filePath, tasConfig.MaskPatterns); err != nil {
+		return nil, err
+	}
+	if err := validateInheritEnv(filePath, tasConfig.InheritEnv); err != nil {
+		return nil, err
+	}
+	if err := validateSecretPolicy(filePath, tasConfig.SecretPolicy); err != nil {
+		return nil, err
+	}
 
 	switch eventType {
 	case core.EventPullRequest:
@@ -79,7 +104,7 @@ func (tc *tasConfigManager) validateYMLV1(ctx context.Context,
 		}
 	}
 	if err := isValidLicenseTier(tasConfig.Tier, licenseTier); err != nil {
-		tc.logger.Errorf("LicenseTier validation failed. error: %v", err)
+		logger.Errorf("LicenseTier validation failed. error: %v", err)
 		return nil, err
 	}
 	return tasConfig, nil
@@ -100,6 +125,7 @@ func (tc *tasConfigManager) validateYMLV2(ctx context.Context,
 	eventType core.EventType,
 	licenseTier core.Tier,
 	yamlFilePath string) (*core.TASConfigV2, error) {
+	logger := lumber.FromContext(ctx, tc.logger)
 	tasConfig, err := utils.ValidateStructTASYmlV2(ctx, yamlFile, yamlFilePath)
 	if err != nil {
 		return nil, err
@@ -108,47 +134,183 @@ func (tc *tasConfigManager) validateYMLV2(ctx context.Context,
 	if tasConfig.CoverageThreshold == nil {
 		tasConfig.CoverageThreshold = new(core.CoverageThreshold)
 	}
+	if err := validateArtifacts(yamlFilePath, tasConfig.Artifacts); err != nil {
+		return nil, err
+	}
+	if err := validateServices(yamlFilePath, tasConfig.Services); err != nil {
+		return nil, err
+	}
+	if err := validateMaskPatterns(yamlFilePath, tasConfig.MaskPatterns); err != nil {
+		return nil, err
+	}
+	if err := validateInheritEnv(yamlFilePath, tasConfig.InheritEnv); err != nil {
+		return nil, err
+	}
+	if err := validateSecretPolicy(yamlFilePath, tasConfig.SecretPolicy); err != nil {
+		return nil, err
+	}
 
 	switch eventType {
 	case core.EventPullRequest:
 		if tasConfig.PreMerge == nil {
 			return nil, fmt.Errorf("`preMerge` is missing in tas configuration file %s", yamlFilePath)
 		}
+		if err := validateRunConditions(yamlFilePath, tasConfig.PreMerge.PreRun); err != nil {
+			return nil, err
+		}
 		subModuleMap := map[string]bool{}
 		for i := 0; i < len(tasConfig.PreMerge.SubModules); i++ {
 			if err := utils.ValidateSubModule(&tasConfig.PreMerge.SubModules[i]); err != nil {
 				return nil, err
 			}
+			if err := validateSubModuleConditions(yamlFilePath, &tasConfig.PreMerge.SubModules[i]); err != nil {
+				return nil, err
+			}
+			if err := validateArtifacts(yamlFilePath, tasConfig.PreMerge.SubModules[i].Artifacts); err != nil {
+				return nil, err
+			}
 			if _, ok := subModuleMap[tasConfig.PreMerge.SubModules[i].Name]; ok {
 				return nil, fmt.Errorf("duplicate subModule name found in `preMerge` in tas configuration file %s", yamlFilePath)
 			}
 			subModuleMap[tasConfig.PreMerge.SubModules[i].Name] = true
 		}
+		if err := expandMatrix(yamlFilePath, tasConfig.PreMerge); err != nil {
+			return nil, err
+		}
 
 	case core.EventPush:
 		if tasConfig.PostMerge == nil {
 			return nil, fmt.Errorf("`postMerge` is missing in tas configuration file %s", yamlFilePath)
 		}
+		if err := validateRunConditions(yamlFilePath, tasConfig.PostMerge.PreRun); err != nil {
+			return nil, err
+		}
 		subModuleMap := map[string]bool{}
 
 		for i := 0; i < len(tasConfig.PostMerge.SubModules); i++ {
 			if err := utils.ValidateSubModule(&tasConfig.PostMerge.SubModules[i]); err != nil {
 				return nil, err
 			}
+			if err := validateSubModuleConditions(yamlFilePath, &tasConfig.PostMerge.SubModules[i]); err != nil {
+				return nil, err
+			}
+			if err := validateArtifacts(yamlFilePath, tasConfig.PostMerge.SubModules[i].Artifacts); err != nil {
+				return nil, err
+			}
 			if _, ok := subModuleMap[tasConfig.PostMerge.SubModules[i].Name]; ok {
 				return nil, fmt.Errorf("duplicate subModule name found in `postMerge` in tas configuration file %s", yamlFilePath)
 			}
 			subModuleMap[tasConfig.PostMerge.SubModules[i].Name] = true
 		}
+		if err := expandMatrix(yamlFilePath, tasConfig.PostMerge); err != nil {
+			return nil, err
+		}
 	}
 	if err := isValidLicenseTier(tasConfig.Tier, licenseTier); err != nil {
-		tc.logger.Errorf("LicenseTier validation failed. error: %v", err)
+		logger.Errorf("LicenseTier validation failed. error: %v", err)
 		return nil, err
 	}
 
 	return tasConfig, nil
 }
 
+// validateCondition validates the glob patterns used in an `if` condition
+func validateCondition(filePath string, condition *core.Condition) error {
+	if condition == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, condition.BranchName...), condition.Changed...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return errs.New(fmt.Sprintf("invalid pattern `%s` in `if` condition in tas configuration file %s", pattern, filePath))
+		}
+	}
+	return nil
+}
+
+// validateRunConditions validates the `if` conditions of every step of the runs
+func validateRunConditions(filePath string, runs ...*core.Run) error {
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		for i := range run.Commands {
+			if err := validateCondition(filePath, run.Commands[i].If); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateSubModuleConditions validates the `if` conditions of a submodule and its steps
+func validateSubModuleConditions(filePath string, subModule *core.SubModule) error {
+	if err := validateCondition(filePath, subModule.If); err != nil {
+		return err
+	}
+	return validateRunConditions(filePath, subModule.Prerun, subModule.Postrun)
+}
+
+// validateArtifacts validates that the artifact patterns are valid globs within the repo
+func validateArtifacts(filePath string, patterns []string) error {
+	for _, pattern := range patterns {
+		cleanPattern := path.Clean(pattern)
+		if !doublestar.ValidatePattern(cleanPattern) || path.IsAbs(cleanPattern) ||
+			cleanPattern == ".." || strings.HasPrefix(cleanPattern, "../") {
+			return errs.New(fmt.Sprintf("invalid artifact pattern `%s` in tas configuration file %s", pattern, filePath))
+		}
+	}
+	return nil
+}
+
+// validateMaskPatterns validates that the patterns of credentials to be masked in logs are valid regexes
+func validateMaskPatterns(filePath string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errs.New(fmt.Sprintf("invalid pattern `%s` in `maskPatterns` in tas configuration file %s", pattern, filePath))
+		}
+	}
+	return nil
+}
+
+// validateSecretPolicy validates the branch patterns of the secret policy
+func validateSecretPolicy(filePath string, policy *config.SecretPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	patterns := append([]string{}, policy.Branches...)
+	for _, scope := range policy.Secrets {
+		patterns = append(patterns, scope.Branches...)
+	}
+	for _, pattern := range patterns {
+		if !doublestar.ValidatePattern(pattern) {
+			return errs.New(fmt.Sprintf("invalid pattern `%s` in `secretPolicy` in tas configuration file %s", pattern, filePath))
+		}
+	}
+	return nil
+}
+
+// validateInheritEnv validates that env variables holding credentials of TAS are not inherited by user commands
+func validateInheritEnv(filePath string, names []string) error {
+	for _, name := range names {
+		if utils.IsInternalEnv(name) {
+			return errs.New(fmt.Sprintf("env variable `%s` in `inheritEnv` cannot be inherited in tas configuration file %s", name, filePath))
+		}
+	}
+	return nil
+}
+
+// validateServices validates that service names, used as their hostnames, are unique
+func validateServices(filePath string, services []core.Service) error {
+	serviceMap := map[string]bool{}
+	for i := range services {
+		if _, ok := serviceMap[services[i].Name]; ok {
+			return fmt.Errorf("duplicate service name `%s` found in `services` in tas configuration file %s", services[i].Name, filePath)
+		}
+		serviceMap[services[i].Name] = true
+	}
+	return nil
+}
+
 func (tc *tasConfigManager) GetVersion(path string) (int, error) {
 	yamlFile, err := os.ReadFile(path)
 	if err != nil {
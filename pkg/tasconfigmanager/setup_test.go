@@ -123,8 +123,8 @@ func TestLoadAndValidateV1(t *testing.T) {
 					EnvMap:   map[string]string{"NODE_ENV": "development"},
 					Patterns: []string{"{packages,scripts}/**/__tests__/*{.js,.coffee,[!d].ts}"},
 				},
-				Prerun:      &core.Run{EnvMap: map[string]string{"NODE_ENV": "development"}, Commands: []string{"yarn"}},
-				Postrun:     &core.Run{Commands: []string{"node --version"}},
+				Prerun:      &core.Run{EnvMap: map[string]string{"NODE_ENV": "development"}, Commands: []core.Step{{Run: "yarn"}}},
+				Postrun:     &core.Run{Commands: []core.Step{{Run: "node --version"}}},
 				ConfigFile:  "scripts/jest/config.source-www.js",
 				NodeVersion: "14.17.6",
 				Tier:        "small",
@@ -234,6 +234,60 @@ func TestLoadAndValidateV2(t *testing.T) {
 			fmt.Errorf("duplicate subModule name found in `postMerge` in tas configuration file %s",
 				"../../testutils/testdata/tasyml/duplicate_submodule_postmerge.yaml"),
 		},
+		{
+			"Invalid pattern in submodule condition",
+			path.Join("../../", "testutils/testdata/tasyml/invalid_condition_v2.yaml"),
+			core.EventPullRequest,
+			core.Small,
+			nil,
+			fmt.Errorf("invalid pattern `somepath/[src/**` in `if` condition in tas configuration file %s",
+				"../../testutils/testdata/tasyml/invalid_condition_v2.yaml"),
+		},
+		{
+			"Artifact pattern outside repo in submodule",
+			path.Join("../../", "testutils/testdata/tasyml/invalid_artifacts_v2.yaml"),
+			core.EventPush,
+			core.Small,
+			nil,
+			fmt.Errorf("invalid artifact pattern `../outside/**` in tas configuration file %s",
+				"../../testutils/testdata/tasyml/invalid_artifacts_v2.yaml"),
+		},
+		{
+			"Invalid mask pattern",
+			path.Join("../../", "testutils/testdata/tasyml/invalid_mask_patterns_v2.yaml"),
+			core.EventPush,
+			core.Small,
+			nil,
+			fmt.Errorf("invalid pattern `password=(\\S+` in `maskPatterns` in tas configuration file %s",
+				"../../testutils/testdata/tasyml/invalid_mask_patterns_v2.yaml"),
+		},
+		{
+			"Internal env variable in inheritEnv",
+			path.Join("../../", "testutils/testdata/tasyml/invalid_inherit_env_v2.yaml"),
+			core.EventPush,
+			core.Small,
+			nil,
+			fmt.Errorf("env variable `TOKEN` in `inheritEnv` cannot be inherited in tas configuration file %s",
+				"../../testutils/testdata/tasyml/invalid_inherit_env_v2.yaml"),
+		},
+		{
+			"Invalid branch pattern in secretPolicy",
+			path.Join("../../", "testutils/testdata/tasyml/invalid_secret_policy_v2.yaml"),
+			core.EventPush,
+			core.Small,
+			nil,
+			fmt.Errorf("invalid pattern `release/[a-z` in `secretPolicy` in tas configuration file %s",
+				"../../testutils/testdata/tasyml/invalid_secret_policy_v2.yaml"),
+		},
+		{
+			"Duplicate service name",
+			path.Join("../../", "testutils/testdata/tasyml/duplicate_services_v2.yaml"),
+			core.EventPush,
+			core.Small,
+			nil,
+			fmt.Errorf("duplicate service name `postgres` found in `services` in tas configuration file %s",
+				"../../testutils/testdata/tasyml/duplicate_services_v2.yaml"),
+		},
 		{
 			"Valid Config",
 			"../../testutils/testdata/tasyml/valid_with_cachekeyV2.yml",
@@ -28,10 +28,11 @@ func New(requests core.Requests, logger lumber.Logger) (core.Task, error) {
 }
 
 func (t *task) UpdateStatus(ctx context.Context, payload *core.TaskPayload) error {
-	t.logger.Debugf("sending status update of task: %s to %s for repository: %s", payload.TaskID, payload.Status, payload.RepoLink)
+	logger := lumber.FromContext(ctx, t.logger)
+	logger.Debugf("sending status update of task: %s to %s for repository: %s", payload.TaskID, payload.Status, payload.RepoLink)
 	reqBody, err := json.Marshal(payload)
 	if err != nil {
-		t.logger.Errorf("error while json marshal %v", err)
+		logger.Errorf("error while json marshal %v", err)
 		return err
 	}
 	query, headers := utils.GetDefaultQueryAndHeaders()
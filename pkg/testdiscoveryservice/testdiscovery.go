@@ -7,11 +7,15 @@ import (
 	"net/http"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/logstream"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+	"github.com/LambdaTest/test-at-scale/pkg/procutils"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
 	"github.com/LambdaTest/test-at-scale/pkg/utils"
 )
 
@@ -38,7 +42,13 @@ func NewTestDiscoveryService(ctx context.Context,
 	}
 }
 
-func (tds *testDiscoveryService) Discover(ctx context.Context, discoveryArgs *core.DiscoveyArgs) (*core.DiscoveryResult, error) {
+func (tds *testDiscoveryService) Discover(ctx context.Context,
+	discoveryArgs *core.DiscoveyArgs) (result *core.DiscoveryResult, err error) {
+	logger := lumber.FromContext(ctx, tds.logger)
+	defer metrics.ObserveStage(metrics.StageDiscovery, time.Now())
+	ctx, span := tracing.Start(ctx, tracing.SpanDiscovery)
+	core.SetProgressStage(ctx, tracing.SpanDiscovery)
+	defer func() { tracing.End(span, err) }()
 	configFilePath, err := utils.GetConfigFileName(discoveryArgs.Payload.TasFileName)
 	if err != nil {
 		return nil, err
@@ -61,25 +71,28 @@ func (tds *testDiscoveryService) Discover(ctx context.Context, discoveryArgs *co
 			}
 		}
 	}
-	tds.logger.Debugf("Discovering tests at paths %+v", discoveryArgs.TestPattern)
+	logger.Debugf("Discovering tests at paths %+v", discoveryArgs.TestPattern)
 
-	cmd := exec.CommandContext(ctx, global.FrameworkRunnerMap[discoveryArgs.FrameWork], args...) //nolint:gosec
+	cmd := exec.Command(global.FrameworkRunnerMap[discoveryArgs.FrameWork], args...) //nolint:gosec
 	cmd.Dir = discoveryArgs.CWD
 	envVars, err := tds.execManager.GetEnvVariables(discoveryArgs.EnvMap, discoveryArgs.SecretData)
 	if err != nil {
-		tds.logger.Errorf("failed to parse env variables, error: %v", err)
+		logger.Errorf("failed to parse env variables, error: %v", err)
 		return nil, err
 	}
 	cmd.Env = envVars
-	logWriter := lumber.NewWriter(tds.logger)
+	logWriter := lumber.NewWriter(logger)
 	defer logWriter.Close()
-	maskWriter := logstream.NewMasker(logWriter, discoveryArgs.SecretData)
+	maskWriter := logstream.NewMasker(logWriter, utils.WithInternalSecrets(discoveryArgs.SecretData))
+	defer maskWriter.Close()
 	cmd.Stdout = maskWriter
 	cmd.Stderr = maskWriter
 
-	tds.logger.Debugf("Executing test discovery command: %s", cmd.String())
-	if err := cmd.Run(); err != nil {
-		tds.logger.Errorf("command %s of type %s failed with error: %v", cmd.String(), core.Discovery, err)
+	logger.Debugf("Executing test discovery command: %s", cmd.String())
+	err = procutils.Run(ctx, cmd, logger)
+	metrics.RecordRunnerExit(metrics.StageDiscovery, err)
+	if err != nil {
+		logger.Errorf("command %s of type %s failed with error: %v", cmd.String(), core.Discovery, err)
 		return nil, err
 	}
 
@@ -101,10 +114,14 @@ func (tds *testDiscoveryService) shouldImpactAll(smartRun bool, configFilePath s
 	return impactAll
 }
 
-func (tds *testDiscoveryService) SendResult(ctx context.Context, testDiscoveryResult *core.DiscoveryResult) error {
+func (tds *testDiscoveryService) SendResult(ctx context.Context, testDiscoveryResult *core.DiscoveryResult) (err error) {
+	logger := lumber.FromContext(ctx, tds.logger)
+	ctx, span := tracing.Start(ctx, tracing.SpanSendResults)
+	core.SetProgressStage(ctx, tracing.SpanSendResults)
+	defer func() { tracing.End(span, err) }()
 	reqBody, err := json.Marshal(testDiscoveryResult)
 	if err != nil {
-		tds.logger.Errorf("error while json marshal %v", err)
+		logger.Errorf("error while json marshal %v", err)
 		return err
 	}
 	query, headers := utils.GetDefaultQueryAndHeaders()
@@ -10,13 +10,18 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/LambdaTest/test-at-scale/config"
 	"github.com/LambdaTest/test-at-scale/pkg/core"
 	"github.com/LambdaTest/test-at-scale/pkg/global"
 	"github.com/LambdaTest/test-at-scale/pkg/logstream"
 	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"github.com/LambdaTest/test-at-scale/pkg/metrics"
+	"github.com/LambdaTest/test-at-scale/pkg/procutils"
 	"github.com/LambdaTest/test-at-scale/pkg/service/teststats"
+	"github.com/LambdaTest/test-at-scale/pkg/tracing"
 	"github.com/LambdaTest/test-at-scale/pkg/utils"
 )
 
@@ -50,16 +55,25 @@ func NewTestExecutionService(cfg *config.NucleusConfig,
 
 // Run executes the test files
 func (tes *testExecutionService) Run(ctx context.Context,
-	testExecutionArgs *core.TestExecutionArgs) (*core.ExecutionResults, error) {
+	testExecutionArgs *core.TestExecutionArgs) (executionResults *core.ExecutionResults, err error) {
+	logger := lumber.FromContext(ctx, tes.logger)
+	defer metrics.ObserveStage(metrics.StageExecution, time.Now())
+	ctx, span := tracing.Start(ctx, tracing.SpanExecution)
+	core.SetProgressStage(ctx, tracing.SpanExecution)
+	defer func() { tracing.End(span, err) }()
 	azureReader, azureWriter := io.Pipe()
 	defer azureWriter.Close()
 
-	errChan := testExecutionArgs.LogWriterStrategy.Write(ctx, azureReader)
+	// the logs of an aborted run are uploaded within the grace period
+	logCtx, logCancel := core.GraceContext(ctx)
+	defer logCancel()
+	errChan := testExecutionArgs.LogWriterStrategy.Write(logCtx, azureReader)
 	defer tes.closeAndWriteLog(azureWriter, errChan)
-	logWriter := lumber.NewWriter(tes.logger)
+	logWriter := lumber.NewWriter(logger)
 	defer logWriter.Close()
 	multiWriter := io.MultiWriter(logWriter, azureWriter)
-	maskWriter := logstream.NewMasker(multiWriter, testExecutionArgs.SecretData)
+	maskWriter := logstream.NewMasker(multiWriter, utils.WithInternalSecrets(testExecutionArgs.SecretData))
+	defer maskWriter.Close()
 
 	args, err := tes.buildCmdArgs(ctx, testExecutionArgs.TestConfigFile,
 		testExecutionArgs.FrameWork, testExecutionArgs.FrameWorkVersion, testExecutionArgs.Payload, testExecutionArgs.TestPattern)
@@ -72,28 +86,29 @@ func (tes *testExecutionService) Run(ctx context.Context,
 	commandArgs := args
 	envVars, err := tes.execManager.GetEnvVariables(testExecutionArgs.EnvMap, testExecutionArgs.SecretData)
 	if err != nil {
-		tes.logger.Errorf("failed to parse env variables, error: %v", err)
+		logger.Errorf("failed to parse env variables, error: %v", err)
 		return nil, err
 	}
 
-	executionResults := &core.ExecutionResults{
+	executionResults = &core.ExecutionResults{
 		TaskID:   payload.TaskID,
 		BuildID:  payload.BuildID,
 		RepoID:   payload.RepoID,
 		OrgID:    payload.OrgID,
 		CommitID: payload.BuildTargetCommit,
 		TaskType: payload.TaskType,
+		Matrix:   testExecutionArgs.Matrix,
 	}
 	for i := 1; i <= tes.cfg.ConsecutiveRuns; i++ {
 		var cmd *exec.Cmd
 		if testExecutionArgs.FrameWork == "jasmine" || testExecutionArgs.FrameWork == "mocha" {
 			if collectCoverage {
-				cmd = exec.CommandContext(ctx, "nyc", commandArgs...)
+				cmd = exec.Command("nyc", commandArgs...)
 			} else {
-				cmd = exec.CommandContext(ctx, commandArgs[0], commandArgs[1:]...) //nolint:gosec
+				cmd = exec.Command(commandArgs[0], commandArgs[1:]...) //nolint:gosec
 			}
 		} else {
-			cmd = exec.CommandContext(ctx, commandArgs[0], commandArgs[1:]...) //nolint:gosec
+			cmd = exec.Command(commandArgs[0], commandArgs[1:]...) //nolint:gosec
 			if collectCoverage {
 				envVars = append(envVars, "TAS_COLLECT_COVERAGE=true")
 			}
@@ -102,22 +117,35 @@ func (tes *testExecutionService) Run(ctx context.Context,
 		cmd.Env = envVars
 		cmd.Stdout = maskWriter
 		cmd.Stderr = maskWriter
-		tes.logger.Debugf("Executing test execution command: %s", cmd.String())
-		if err := cmd.Start(); err != nil {
-			tes.logger.Errorf("failed to execute test %s %v", cmd.String(), err)
+		logger.Debugf("Executing test execution command: %s", cmd.String())
+		// on abort the runner process group is sent SIGTERM, so that the runner can flush its results to nucleus
+		wait, err := procutils.Start(ctx, cmd, logger)
+		if err != nil {
+			logger.Errorf("failed to execute test %s %v", cmd.String(), err)
 			return nil, err
 		}
 		pid := int32(cmd.Process.Pid)
-		tes.logger.Debugf("execution command started with pid %d", pid)
+		logger.Debugf("execution command started with pid %d", pid)
 
-		if err := tes.ts.CaptureTestStats(pid, tes.cfg.CollectStats); err != nil {
-			tes.logger.Errorf("failed to find process for command %s with pid %d %v", cmd.String(), pid, err)
+		if err := tes.ts.CaptureTestStats(ctx, pid, tes.cfg.CollectStats); err != nil {
+			logger.Errorf("failed to find process for command %s with pid %d %v", cmd.String(), pid, err)
+			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			_ = wait()
 			return nil, err
 		}
-		err := cmd.Wait()
+		err = wait()
+		metrics.RecordRunnerExit(metrics.StageExecution, err)
 		result := <-tes.ts.ExecutionResultOutputChannel
+		if ctx.Err() != nil {
+			// the results flushed by the runner before it exited are returned as partial results
+			logger.Warnf("test execution aborted, error: %v", ctx.Err())
+			if result != nil {
+				executionResults.Results = append(executionResults.Results, result.Results...)
+			}
+			return executionResults, ctx.Err()
+		}
 		if err != nil {
-			tes.logger.Errorf("error in test execution: %+v", err)
+			logger.Errorf("error in test execution: %+v", err)
 			// returning error when result is nil to throw execution errors like heap out of memory
 			if result == nil {
 				return nil, err
@@ -143,20 +171,24 @@ func getPatternAndEnvV1(payload *core.Payload, tasConfig *core.TASConfig) (targe
 
 func (tes *testExecutionService) SendResults(ctx context.Context,
 	payload *core.ExecutionResults) (resp *core.TestReportResponsePayload, err error) {
+	logger := lumber.FromContext(ctx, tes.logger)
+	ctx, span := tracing.Start(ctx, tracing.SpanSendResults)
+	core.SetProgressStage(ctx, tracing.SpanSendResults)
+	defer func() { tracing.End(span, err) }()
 	reqBody, err := json.Marshal(payload)
 	if err != nil {
-		tes.logger.Errorf("failed to marshal request body %v", err)
+		logger.Errorf("failed to marshal request body %v", err)
 		return nil, err
 	}
 	query, headers := utils.GetDefaultQueryAndHeaders()
 	respBody, _, err := tes.requests.MakeAPIRequest(ctx, http.MethodPost, tes.serverEndpoint, reqBody, query, headers)
 	if err != nil {
-		tes.logger.Errorf("error while sending reports %v", err)
+		logger.Errorf("error while sending reports %v", err)
 		return nil, err
 	}
 	err = json.Unmarshal(respBody, &resp)
 	if err != nil {
-		tes.logger.Errorf("failed to unmarshal response body %v", err)
+		logger.Errorf("failed to unmarshal response body %v", err)
 		return nil, err
 	}
 	if resp.TaskStatus == "" {
@@ -166,9 +198,10 @@ func (tes *testExecutionService) SendResults(ctx context.Context,
 }
 
 func (tes *testExecutionService) getLocatorsFile(ctx context.Context, locatorAddress string) (string, error) {
+	logger := lumber.FromContext(ctx, tes.logger)
 	resp, err := tes.azureClient.FindUsingSASUrl(ctx, locatorAddress)
 	if err != nil {
-		tes.logger.Errorf("Error while downloading locatorFile, error %v", err)
+		logger.Errorf("Error while downloading locatorFile, error %v", err)
 		return "", err
 	}
 	defer resp.Close()
@@ -199,15 +232,16 @@ func (tes *testExecutionService) buildCmdArgs(ctx context.Context,
 	frameworkVersion int,
 	payload *core.Payload,
 	target []string) ([]string, error) {
+	logger := lumber.FromContext(ctx, tes.logger)
 	args := []string{global.FrameworkRunnerMap[frameWork]}
 
 	args = append(args, utils.GetArgs("execute", frameWork, frameworkVersion, testConfigFile, target)...)
 
 	if payload.LocatorAddress != "" {
 		locatorFile, err := tes.getLocatorsFile(ctx, payload.LocatorAddress)
-		tes.logger.Debugf("locators : %v\n", locatorFile)
+		logger.Debugf("locators : %v\n", locatorFile)
 		if err != nil {
-			tes.logger.Errorf("failed to get locator file, error: %v", err)
+			logger.Errorf("failed to get locator file, error: %v", err)
 			return nil, err
 		}
 
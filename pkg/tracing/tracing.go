@@ -0,0 +1,101 @@
+// Package tracing traces the tasks of nucleus with OpenTelemetry.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/LambdaTest/test-at-scale"
+
+// Names of the spans of a task
+const (
+	SpanTask             = "task"
+	SpanFetchPayload     = "fetch_payload"
+	SpanClone            = "clone"
+	SpanExtractWorkspace = "extract_workspace"
+	SpanDiff             = "diff"
+	SpanCacheDownload    = "cache_download"
+	SpanCacheUpload      = "cache_upload"
+	SpanCacheWorkspace   = "cache_workspace"
+	SpanNodeInstall      = "node_install"
+	SpanDiscovery        = "discovery"
+	SpanExecution        = "execution"
+	SpanSendResults      = "send_results"
+)
+
+// Setup registers the tracer provider exporting the spans of service over OTLP to the collector of cfg.
+// Tracing is disabled when no collector is configured. The returned function flushes the pending spans
+// and stops the export, it must be called before the service exits.
+func Setup(ctx context.Context, cfg *config.TracingConfig, service, version string) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(service),
+		semconv.ServiceVersionKey.String(version))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span of ctx. The fields of the logger of ctx,
+// e.g. the task and the submodule, are added to the span as attributes.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(Attributes(lumber.FieldsFromContext(ctx)), attrs...)
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it as failed if err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Attributes returns the logger fields as span attributes
+func Attributes(fields lumber.Fields) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(fields))
+	for key, value := range fields {
+		attrs = append(attrs, attribute.String(key, fmt.Sprint(value)))
+	}
+	return attrs
+}
+
+// TraceID returns the id of the trace of ctx, empty if ctx is not traced
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// InjectHeaders adds the trace context of ctx to the headers of an outbound request
+func InjectHeaders(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
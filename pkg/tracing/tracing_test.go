@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/LambdaTest/test-at-scale/config"
+	"github.com/LambdaTest/test-at-scale/pkg/lumber"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &config.TracingConfig{}, "nucleus", "test")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	ctx, span := Start(context.Background(), SpanTask)
+	End(span, nil)
+	if got := TraceID(ctx); got != "" {
+		t.Errorf("TraceID() = %v, want no trace id when tracing is disabled", got)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	// the collector stands in for the OTLP/HTTP endpoint of an opentelemetry collector
+	var mu sync.Mutex
+	var paths []string
+	var bodies int
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		if len(body) > 0 {
+			bodies++
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+	defer func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	cfg := &config.TracingConfig{Endpoint: strings.TrimPrefix(collector.URL, "http://"), Insecure: true}
+	shutdown, err := Setup(context.Background(), cfg, "nucleus", "test")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	// testutils imports the pipeline, which is traced, so the logger is created here
+	logger, err := lumber.NewLogger(lumber.LoggingConfig{ConsoleLevel: lumber.Debug}, true, lumber.InstanceZapLogger)
+	if err != nil {
+		t.Fatalf("failed to create logger, error: %v", err)
+	}
+	ctx, _ := lumber.ContextWithFields(context.Background(), logger, lumber.Fields{lumber.FieldTaskID: "task"})
+	ctx, taskSpan := Start(ctx, SpanTask)
+	_, discoverySpan := Start(ctx, SpanDiscovery)
+	End(discoverySpan, errors.New("discovery failed"))
+
+	header := http.Header{}
+	InjectHeaders(ctx, header)
+	traceID := TraceID(ctx)
+	if traceID == "" {
+		t.Errorf("TraceID() is empty, want the id of the task trace")
+	}
+	if got := header.Get("traceparent"); !strings.Contains(got, traceID) {
+		t.Errorf("InjectHeaders() traceparent = %q, want the trace id %s", got, traceID)
+	}
+	End(taskSpan, nil)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if bodies == 0 {
+		t.Fatalf("no spans were exported to the collector")
+	}
+	for _, path := range paths {
+		if path != "/v1/traces" {
+			t.Errorf("spans exported to %s, want /v1/traces", path)
+		}
+	}
+}
@@ -223,6 +223,21 @@ func validateStruct(validate *validator.Validate, config interface{}, ymlFilenam
 	return nil
 }
 
+// IsInternalEnv reports whether the env variable holds credentials of nucleus or synapse
+func IsInternalEnv(name string) bool {
+	for _, internal := range global.InternalEnvVars {
+		if name == internal {
+			return true
+		}
+	}
+	for _, prefix := range global.InternalEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateSubModule validates submodule
 func ValidateSubModule(module *core.SubModule) error {
 	if module.Name == "" {
@@ -238,6 +253,28 @@ func ValidateSubModule(module *core.SubModule) error {
 	return nil
 }
 
+// authToken is the token of nucleus for TAS server, which is kept out of the environment of the user commands
+var authToken string
+
+// SetAuthToken sets the token supplied with each request made to TAS Server
+func SetAuthToken(token string) {
+	authToken = token
+}
+
+// WithInternalSecrets returns a copy of secretData along with the credentials of nucleus, for masking them
+// in the logs. The credentials are never passed to the user commands, so they are not part of secretData.
+func WithInternalSecrets(secretData map[string]string) map[string]string {
+	secrets := make(map[string]string, len(secretData)+1)
+	for k, v := range secretData {
+		secrets[k] = v
+	}
+	if authToken != "" {
+		// the key is not a valid secret name, so that no secret of the repo is overwritten
+		secrets["nucleus:TOKEN"] = authToken
+	}
+	return secrets
+}
+
 // GetDefaultQueryAndHeaders returns the query and headers that should be supplied with each request made to TAS Server
 func GetDefaultQueryAndHeaders() (query map[string]interface{}, headers map[string]string) {
 	query = map[string]interface{}{
@@ -247,7 +284,7 @@ func GetDefaultQueryAndHeaders() (query map[string]interface{}, headers map[stri
 		"taskID":  os.Getenv("TASK_ID"),
 	}
 	headers = map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %s", os.Getenv("TOKEN")),
+		"Authorization": fmt.Sprintf("Bearer %s", authToken),
 	}
 	return query, headers
 }
@@ -187,8 +187,8 @@ func TestValidateStructv1(t *testing.T) {
 					EnvMap:   map[string]string{"NODE_ENV": "development"},
 					Patterns: []string{"{packages,scripts}/**/__tests__/*{.js,.coffee,[!d].ts}"},
 				},
-				Prerun:      &core.Run{EnvMap: map[string]string{"NODE_ENV": "development"}, Commands: []string{"yarn"}},
-				Postrun:     &core.Run{Commands: []string{"node --version"}},
+				Prerun:      &core.Run{EnvMap: map[string]string{"NODE_ENV": "development"}, Commands: []core.Step{{Run: "yarn"}}},
+				Postrun:     &core.Run{Commands: []core.Step{{Run: "node --version"}}},
 				ConfigFile:  "scripts/jest/config.source-www.js",
 				NodeVersion: "14.17.6",
 				Tier:        "small",
@@ -396,3 +396,14 @@ func TestValidateSubModule(t *testing.T) {
 		})
 	}
 }
+
+func TestWithInternalSecrets(t *testing.T) {
+	SetAuthToken("nucleus-token")
+	defer SetAuthToken("")
+	secretData := map[string]string{"TOKEN": "repo-token"}
+	want := map[string]string{"TOKEN": "repo-token", "nucleus:TOKEN": "nucleus-token"}
+	assert.Equal(t, want, WithInternalSecrets(secretData))
+	assert.Equal(t, map[string]string{"TOKEN": "repo-token"}, secretData, "secretData must not be modified")
+	_, headers := GetDefaultQueryAndHeaders()
+	assert.Equal(t, "Bearer nucleus-token", headers["Authorization"])
+}
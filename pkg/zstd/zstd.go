@@ -3,10 +3,8 @@ package zstd
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/LambdaTest/test-at-scale/pkg/core"
@@ -20,8 +18,8 @@ type zstdCompressor struct {
 }
 
 const (
-	manifestFileName = "manifest.txt"
-	executableName   = "tar"
+	manifestFilePattern = "manifest-*.txt"
+	executableName      = "tar"
 )
 
 //New return zStandard compression manager
@@ -35,23 +33,36 @@ func New(execManager core.ExecutionManager, logger lumber.Logger) (core.ZstdComp
 	return &zstdCompressor{logger: logger, execManager: execManager, execPath: path}, nil
 }
 
-func (z *zstdCompressor) createManifestFile(workingDir string, fileNames ...string) error {
-	return ioutil.WriteFile(filepath.Join(os.TempDir(), manifestFileName), []byte(strings.Join(fileNames, "\n")), 0660)
+// createManifestFile creates a manifest file of its own for each compression, so that they can run concurrently
+func (z *zstdCompressor) createManifestFile(fileNames ...string) (string, error) {
+	f, err := os.CreateTemp("", manifestFilePattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strings.Join(fileNames, "\n")); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
 }
 
 // Compress compress the list of files
 func (z *zstdCompressor) Compress(ctx context.Context, compressedFileName string, preservePath bool, workingDirectory string, filesToCompress ...string) error {
-	if err := z.createManifestFile(workingDirectory, filesToCompress...); err != nil {
-		z.logger.Errorf("failed to create manifest file %v", err)
+	logger := lumber.FromContext(ctx, z.logger)
+	manifestFile, err := z.createManifestFile(filesToCompress...)
+	if err != nil {
+		logger.Errorf("failed to create manifest file %v", err)
 		return err
 	}
-	command := fmt.Sprintf("%s --posix -I 'zstd -5 -T0' -cf %s -C %s -T %s", z.execPath, compressedFileName, workingDirectory, filepath.Join(os.TempDir(), manifestFileName))
+	defer os.Remove(manifestFile)
+	command := fmt.Sprintf("%s --posix -I 'zstd -5 -T0' -cf %s -C %s -T %s", z.execPath, compressedFileName, workingDirectory, manifestFile)
 	if preservePath {
 		command = fmt.Sprintf("%s -P", command)
 	}
 	commands := []string{command}
-	if err := z.execManager.ExecuteInternalCommands(ctx, core.Zstd, commands, workingDirectory, nil, nil); err != nil {
-		z.logger.Errorf("error while zstd compression %v", err)
+	if _, err := z.execManager.ExecuteInternalCommands(ctx, core.Zstd, commands, workingDirectory, nil, nil); err != nil {
+		logger.Errorf("error while zstd compression %v", err)
 		return err
 	}
 	return nil
@@ -59,13 +70,14 @@ func (z *zstdCompressor) Compress(ctx context.Context, compressedFileName string
 
 //Decompress performs the decompression operation for the given file
 func (z *zstdCompressor) Decompress(ctx context.Context, filePath string, preservePath bool, workingDirectory string) error {
+	logger := lumber.FromContext(ctx, z.logger)
 	command := fmt.Sprintf("%s --posix -I 'zstd -d' -xf %s -C %s", z.execPath, filePath, workingDirectory)
 	if preservePath {
 		command = fmt.Sprintf("%s -P", command)
 	}
 	commands := []string{command}
-	if err := z.execManager.ExecuteInternalCommands(ctx, core.Zstd, commands, workingDirectory, nil, nil); err != nil {
-		z.logger.Errorf("error while zstd decompression %v", err)
+	if _, err := z.execManager.ExecuteInternalCommands(ctx, core.Zstd, commands, workingDirectory, nil, nil); err != nil {
+		logger.Errorf("error while zstd decompression %v", err)
 		return err
 	}
 	return nil
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/LambdaTest/test-at-scale/mocks"
@@ -46,8 +47,7 @@ func Test_zstdCompressor_createManifestFile(t *testing.T) {
 		execPath    string
 	}
 	type args struct {
-		workingDir string
-		fileNames  []string
+		fileNames []string
 	}
 	tests := []struct {
 		name    string
@@ -58,7 +58,7 @@ func Test_zstdCompressor_createManifestFile(t *testing.T) {
 		{
 			"Test createManifestFile",
 			fields{logger: logger, execManager: execManager, execPath: path},
-			args{"./", []string{"file1", "file2"}},
+			args{[]string{"file1", "file2"}},
 			false,
 		},
 	}
@@ -69,8 +69,19 @@ func Test_zstdCompressor_createManifestFile(t *testing.T) {
 				execManager: tt.fields.execManager,
 				execPath:    tt.fields.execPath,
 			}
-			if err := z.createManifestFile(tt.args.workingDir, tt.args.fileNames...); (err != nil) != tt.wantErr {
+			manifestFile, err := z.createManifestFile(tt.args.fileNames...)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("zstdCompressor.createManifestFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			defer os.Remove(manifestFile)
+			content, err := os.ReadFile(manifestFile)
+			if err != nil {
+				t.Errorf("failed to read manifest file, error: %v", err)
+				return
+			}
+			if string(content) != "file1\nfile2" {
+				t.Errorf("Expected manifest: %q, got: %q", "file1\nfile2", content)
 			}
 		})
 	}
@@ -93,10 +104,13 @@ func Test_zstdCompressor_Compress(t *testing.T) {
 		mock.AnythingOfType("map[string]string"),
 		mock.AnythingOfType("map[string]string"),
 	).Return(
-		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) error {
+		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) []*core.StepResult {
 			ReceivedArgs = commands
 			return nil
 		},
+		func(ctx context.Context, commandType core.CommandType, commands []string, cwd string, envMap, secretData map[string]string) error {
+			return nil
+		},
 	)
 	execManagerErr := new(mocks.ExecutionManager)
 	execManagerErr.On("ExecuteInternalCommands",
@@ -108,8 +122,12 @@ func Test_zstdCompressor_Compress(t *testing.T) {
 		mock.AnythingOfType("map[string]string"),
 	).Return(
 		func(ctx context.Context, commandType core.CommandType, commands []string,
-			cwd string, envMap, secretData map[string]string) error {
+			cwd string, envMap, secretData map[string]string) []*core.StepResult {
 			ReceivedArgs = commands
+			return nil
+		},
+		func(ctx context.Context, commandType core.CommandType, commands []string,
+			cwd string, envMap, secretData map[string]string) error {
 			return errs.New("error from mocked interface")
 		},
 	)
@@ -164,14 +182,14 @@ func Test_zstdCompressor_Compress(t *testing.T) {
 				return
 			}
 
-			command := fmt.Sprintf("%s --posix -I 'zstd -5 -T0' -cf compressedFileName -C ./ -T %s",
-				z.execPath, filepath.Join(os.TempDir(), manifestFileName))
+			// each compression gets a manifest file of its own
+			command := regexp.QuoteMeta(fmt.Sprintf("%s --posix -I 'zstd -5 -T0' -cf compressedFileName -C ./ -T %s",
+				z.execPath, filepath.Join(os.TempDir(), "manifest-"))) + `\d+\.txt`
 			if tt.args.preservePath {
 				command = fmt.Sprintf("%s -P", command)
 			}
-			commands := []string{command}
-			if !reflect.DeepEqual(ReceivedArgs, commands) {
-				t.Errorf("Expected commands: %v, got: %v", commands, ReceivedArgs)
+			if len(ReceivedArgs) != 1 || !regexp.MustCompile("^"+command+"$").MatchString(ReceivedArgs[0]) {
+				t.Errorf("Expected commands: %v, got: %v", command, ReceivedArgs)
 			}
 		})
 	}
@@ -196,9 +214,13 @@ func Test_zstdCompressor_Decompress(t *testing.T) {
 		mock.AnythingOfType("map[string]string"),
 		mock.AnythingOfType("map[string]string")).Return(
 		func(ctx context.Context, commandType core.CommandType, commands []string,
-			cwd string, envMap, secretData map[string]string) error {
+			cwd string, envMap, secretData map[string]string) []*core.StepResult {
 			ReceivedArgs = commands
 			return nil
+		},
+		func(ctx context.Context, commandType core.CommandType, commands []string,
+			cwd string, envMap, secretData map[string]string) error {
+			return nil
 		})
 
 	execManagerErr := new(mocks.ExecutionManager)
@@ -211,8 +233,12 @@ func Test_zstdCompressor_Decompress(t *testing.T) {
 		mock.AnythingOfType("map[string]string"),
 	).Return(
 		func(ctx context.Context, commandType core.CommandType, commands []string,
-			cwd string, envMap, secretData map[string]string) error {
+			cwd string, envMap, secretData map[string]string) []*core.StepResult {
 			ReceivedArgs = commands
+			return nil
+		},
+		func(ctx context.Context, commandType core.CommandType, commands []string,
+			cwd string, envMap, secretData map[string]string) error {
 			return errs.New("error from mocked interface")
 		})
 